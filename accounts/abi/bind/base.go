@@ -235,7 +235,16 @@ func (c *BoundContract) transact(opts *TransactOpts, contract *common.Address, i
 			}
 		}
 		// If the contract surely has code (or code is not needed), estimate the transaction
-		msg := ethereum.CallMsg{From: opts.From, To: contract, Value: value, Data: input}
+		msg := ethereum.CallMsg{
+			From:                opts.From,
+			To:                  contract,
+			GasPrice:            gasPrice,
+			FeeCurrency:         feeCurrency,
+			GatewayFeeRecipient: gatewayFeeRecipient,
+			GatewayFee:          gatewayFee,
+			Value:               value,
+			Data:                input,
+		}
 		gasLimit, err = c.transactor.EstimateGas(ensureContext(opts.Context), msg)
 		if err != nil {
 			return nil, fmt.Errorf("failed to estimate gas needed: %v", err)
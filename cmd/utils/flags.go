@@ -19,6 +19,7 @@ package utils
 
 import (
 	"crypto/ecdsa"
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"math/big"
@@ -45,9 +46,11 @@ import (
 	"github.com/ethereum/go-ethereum/eth/downloader"
 	"github.com/ethereum/go-ethereum/ethdb"
 	"github.com/ethereum/go-ethereum/ethstats"
+	"github.com/ethereum/go-ethereum/graphql"
 	"github.com/ethereum/go-ethereum/les"
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/metrics"
+	"github.com/ethereum/go-ethereum/metrics/exp"
 	"github.com/ethereum/go-ethereum/metrics/influxdb"
 	"github.com/ethereum/go-ethereum/node"
 	"github.com/ethereum/go-ethereum/p2p"
@@ -56,6 +59,7 @@ import (
 	"github.com/ethereum/go-ethereum/p2p/nat"
 	"github.com/ethereum/go-ethereum/p2p/netutil"
 	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rpc"
 	whisper "github.com/ethereum/go-ethereum/whisper/whisperv6"
 	cli "gopkg.in/urfave/cli.v1"
 )
@@ -338,6 +342,10 @@ var (
 		Usage: "Percentage of cache memory allowance to use for trie pruning",
 		Value: 25,
 	}
+	CacheNoPrefetchFlag = cli.BoolFlag{
+		Name:  "cache.noprefetch",
+		Usage: "Disable heuristic state prefetch during block import (less CPU and disk IO, more time waiting for data)",
+	}
 	TrieCacheGenFlag = cli.IntFlag{
 		Name:  "trie-cache-gens",
 		Usage: "Number of trie node generations to keep in memory",
@@ -463,6 +471,30 @@ var (
 		Usage: "API's offered over the HTTP-RPC interface",
 		Value: "",
 	}
+	GraphQLEnabledFlag = cli.BoolFlag{
+		Name:  "graphql",
+		Usage: "Enable the GraphQL server",
+	}
+	GraphQLListenAddrFlag = cli.StringFlag{
+		Name:  "graphql.addr",
+		Usage: "GraphQL server listening interface",
+		Value: node.DefaultGraphQLHost,
+	}
+	GraphQLPortFlag = cli.IntFlag{
+		Name:  "graphql.port",
+		Usage: "GraphQL server listening port",
+		Value: node.DefaultGraphQLPort,
+	}
+	GraphQLCORSDomainFlag = cli.StringFlag{
+		Name:  "graphql.corsdomain",
+		Usage: "Comma separated list of domains from which to accept cross origin requests (browser enforced)",
+		Value: "",
+	}
+	GraphQLVirtualHostsFlag = cli.StringFlag{
+		Name:  "graphql.vhosts",
+		Usage: "Comma separated list of virtual hostnames from which to accept requests (server enforced). Accepts '*' wildcard.",
+		Value: strings.Join(node.DefaultConfig.GraphQLVirtualHosts, ","),
+	}
 	IPCDisabledFlag = cli.BoolFlag{
 		Name:  "ipcdisable",
 		Usage: "Disable the IPC-RPC server",
@@ -605,6 +637,20 @@ var (
 		Name:  metrics.MetricsEnabledFlag,
 		Usage: "Enable metrics collection and reporting",
 	}
+	// MetricsHTTPFlag defines the endpoint for a stand-alone metrics HTTP endpoint.
+	// Since the pprof service enables sensitive/vulnerable behavior, this allows a user
+	// to enable a public-OK metrics endpoint without having to worry about ALSO exposing
+	// other profiling behavior or information.
+	MetricsHTTPFlag = cli.StringFlag{
+		Name:  "metrics.addr",
+		Usage: "Enable stand-alone metrics HTTP server listening interface",
+		Value: "",
+	}
+	MetricsPortFlag = cli.IntFlag{
+		Name:  "metrics.port",
+		Usage: "Metrics HTTP server listening port",
+		Value: 6060,
+	}
 	MetricsEnableInfluxDBFlag = cli.BoolFlag{
 		Name:  "metrics.influxdb",
 		Usage: "Enable metrics export/push to an external InfluxDB database",
@@ -671,6 +717,45 @@ var (
 		Usage: "A validator's signature must be absent for this many consecutive blocks to be considered down for the uptime score",
 		Value: eth.DefaultConfig.Istanbul.LookbackWindow,
 	}
+	IstanbulSnapshotRetentionFlag = cli.Uint64Flag{
+		Name:  "istanbul.snapshotretention",
+		Usage: "Number of recent epochs whose validator set snapshots are kept on disk (0 = keep all)",
+		Value: eth.DefaultConfig.Istanbul.SnapshotRetention,
+	}
+	IstanbulSnapshotCheckpointFlag = cli.Uint64Flag{
+		Name:  "istanbul.snapshotcheckpoint",
+		Usage: "Number of epochs between validator set snapshots that are never pruned",
+		Value: eth.DefaultConfig.Istanbul.SnapshotCheckpointInterval,
+	}
+	IstanbulBacklogLimitFlag = cli.Uint64Flag{
+		Name:  "istanbul.backloglimit",
+		Usage: "Maximum number of future consensus messages kept per validator (0 = unlimited)",
+		Value: eth.DefaultConfig.Istanbul.BacklogPerValidatorLimit,
+	}
+	IstanbulBacklogSequenceGapFlag = cli.Uint64Flag{
+		Name:  "istanbul.backlogseqgap",
+		Usage: "Number of sequences ahead of the current one from which future consensus messages are kept (0 = unlimited)",
+		Value: eth.DefaultConfig.Istanbul.BacklogSequenceGap,
+	}
+	IstanbulBacklogRoundGapFlag = cli.Uint64Flag{
+		Name:  "istanbul.backlogroundgap",
+		Usage: "Number of rounds ahead of the current one from which future consensus messages are kept (0 = unlimited)",
+		Value: eth.DefaultConfig.Istanbul.BacklogRoundGap,
+	}
+	IstanbulPeerMessageRateFlag = cli.Uint64Flag{
+		Name:  "istanbul.peermsgrate",
+		Usage: "Number of announce messages accepted per second from a single validator, and of forward messages from a single peer (0 = unlimited)",
+		Value: eth.DefaultConfig.Istanbul.PeerMessageRate,
+	}
+	IstanbulPreconnectBlocksFlag = cli.Uint64Flag{
+		Name:  "istanbul.preconnectblocks",
+		Usage: "Number of blocks before an epoch switch to connect to the next validators, and after it to drop the leaving ones (0 = at the switch)",
+		Value: eth.DefaultConfig.Istanbul.ValidatorPreconnectBlocks,
+	}
+	IstanbulReplicaFlag = cli.BoolFlag{
+		Name:  "istanbul.replica",
+		Usage: "Follow consensus as a hot-standby replica of the validator, without signing, until promoted",
+	}
 
 	// Proxy node settings
 	ProxyFlag = cli.BoolFlag{
@@ -870,6 +955,26 @@ func setHTTP(ctx *cli.Context, cfg *node.Config) {
 	}
 }
 
+// setGraphQL creates the GraphQL listener interface string from the set
+// command line flags, returning empty if the GraphQL endpoint is disabled.
+func setGraphQL(ctx *cli.Context, cfg *node.Config) {
+	if ctx.GlobalBool(GraphQLEnabledFlag.Name) && cfg.GraphQLHost == "" {
+		cfg.GraphQLHost = "127.0.0.1"
+		if ctx.GlobalIsSet(GraphQLListenAddrFlag.Name) {
+			cfg.GraphQLHost = ctx.GlobalString(GraphQLListenAddrFlag.Name)
+		}
+	}
+	if ctx.GlobalIsSet(GraphQLPortFlag.Name) {
+		cfg.GraphQLPort = ctx.GlobalInt(GraphQLPortFlag.Name)
+	}
+	if ctx.GlobalIsSet(GraphQLCORSDomainFlag.Name) {
+		cfg.GraphQLCors = splitAndTrim(ctx.GlobalString(GraphQLCORSDomainFlag.Name))
+	}
+	if ctx.GlobalIsSet(GraphQLVirtualHostsFlag.Name) {
+		cfg.GraphQLVirtualHosts = splitAndTrim(ctx.GlobalString(GraphQLVirtualHostsFlag.Name))
+	}
+}
+
 // setWS creates the WebSocket RPC listener interface string from the set
 // command line flags, returning empty if the HTTP endpoint is disabled.
 func setWS(ctx *cli.Context, cfg *node.Config) {
@@ -1082,6 +1187,7 @@ func SetNodeConfig(ctx *cli.Context, cfg *node.Config) {
 	SetP2PConfig(ctx, &cfg.P2P)
 	setIPC(ctx, cfg)
 	setHTTP(ctx, cfg)
+	setGraphQL(ctx, cfg)
 	setWS(ctx, cfg)
 	setNodeUserIdent(ctx, cfg)
 	setDataDir(ctx, cfg)
@@ -1211,6 +1317,30 @@ func setIstanbul(ctx *cli.Context, stack *node.Node, cfg *eth.Config) {
 	if ctx.GlobalIsSet(IstanbulLookbackWindowFlag.Name) {
 		cfg.Istanbul.LookbackWindow = ctx.GlobalUint64(IstanbulLookbackWindowFlag.Name)
 	}
+	if ctx.GlobalIsSet(IstanbulSnapshotRetentionFlag.Name) {
+		cfg.Istanbul.SnapshotRetention = ctx.GlobalUint64(IstanbulSnapshotRetentionFlag.Name)
+	}
+	if ctx.GlobalIsSet(IstanbulSnapshotCheckpointFlag.Name) {
+		cfg.Istanbul.SnapshotCheckpointInterval = ctx.GlobalUint64(IstanbulSnapshotCheckpointFlag.Name)
+	}
+	if ctx.GlobalIsSet(IstanbulBacklogLimitFlag.Name) {
+		cfg.Istanbul.BacklogPerValidatorLimit = ctx.GlobalUint64(IstanbulBacklogLimitFlag.Name)
+	}
+	if ctx.GlobalIsSet(IstanbulBacklogSequenceGapFlag.Name) {
+		cfg.Istanbul.BacklogSequenceGap = ctx.GlobalUint64(IstanbulBacklogSequenceGapFlag.Name)
+	}
+	if ctx.GlobalIsSet(IstanbulBacklogRoundGapFlag.Name) {
+		cfg.Istanbul.BacklogRoundGap = ctx.GlobalUint64(IstanbulBacklogRoundGapFlag.Name)
+	}
+	if ctx.GlobalIsSet(IstanbulPeerMessageRateFlag.Name) {
+		cfg.Istanbul.PeerMessageRate = ctx.GlobalUint64(IstanbulPeerMessageRateFlag.Name)
+	}
+	if ctx.GlobalIsSet(IstanbulPreconnectBlocksFlag.Name) {
+		cfg.Istanbul.ValidatorPreconnectBlocks = ctx.GlobalUint64(IstanbulPreconnectBlocksFlag.Name)
+	}
+	if ctx.GlobalIsSet(IstanbulReplicaFlag.Name) {
+		cfg.Istanbul.Replica = ctx.GlobalBool(IstanbulReplicaFlag.Name)
+	}
 	if ctx.GlobalIsSet(IstanbulProposerPolicyFlag.Name) {
 		cfg.Istanbul.ProposerPolicy = istanbul.ProposerPolicy(ctx.GlobalUint64(IstanbulProposerPolicyFlag.Name))
 	}
@@ -1376,6 +1506,7 @@ func SetEthConfig(ctx *cli.Context, stack *node.Node, cfg *eth.Config) {
 		Fatalf("--%s must be either 'full' or 'archive'", GCModeFlag.Name)
 	}
 	cfg.NoPruning = ctx.GlobalString(GCModeFlag.Name) == "archive"
+	cfg.NoPrefetch = ctx.GlobalBool(CacheNoPrefetchFlag.Name)
 
 	if ctx.GlobalIsSet(CacheFlag.Name) || ctx.GlobalIsSet(CacheTrieFlag.Name) {
 		cfg.TrieCleanCache = ctx.GlobalInt(CacheFlag.Name) * ctx.GlobalInt(CacheTrieFlag.Name) / 100
@@ -1549,6 +1680,24 @@ func RegisterEthStatsService(stack *node.Node, url string) {
 	}
 }
 
+// RegisterGraphQLService adds the GraphQL API to the node, serving either the
+// full or the light client.
+func RegisterGraphQLService(stack *node.Node, endpoint string, cors, vhosts []string, timeouts rpc.HTTPTimeouts) {
+	if err := stack.Register(func(ctx *node.ServiceContext) (node.Service, error) {
+		var ethServ *eth.Ethereum
+		if err := ctx.Service(&ethServ); err == nil {
+			return graphql.New(ethServ.APIBackend, ethServ.Engine(), endpoint, cors, vhosts, timeouts)
+		}
+		var lesServ *les.LightEthereum
+		if err := ctx.Service(&lesServ); err == nil {
+			return graphql.New(lesServ.ApiBackend, lesServ.Engine(), endpoint, cors, vhosts, timeouts)
+		}
+		return nil, errors.New("no full or light Ethereum service running")
+	}); err != nil {
+		Fatalf("Failed to register the GraphQL service: %v", err)
+	}
+}
+
 func SetupMetrics(ctx *cli.Context) {
 	if metrics.Enabled {
 		log.Info("Enabling metrics collection")
@@ -1567,6 +1716,12 @@ func SetupMetrics(ctx *cli.Context) {
 
 			go influxdb.InfluxDBWithTags(metrics.DefaultRegistry, 10*time.Second, endpoint, database, username, password, "geth.", tagsMap)
 		}
+
+		if ctx.GlobalIsSet(MetricsHTTPFlag.Name) {
+			address := fmt.Sprintf("%s:%d", ctx.GlobalString(MetricsHTTPFlag.Name), ctx.GlobalInt(MetricsPortFlag.Name))
+			log.Info("Enabling stand-alone metrics HTTP endpoint", "address", address)
+			exp.Setup(address)
+		}
 	}
 }
 
@@ -1654,10 +1809,11 @@ func MakeChain(ctx *cli.Context, stack *node.Node) (chain *core.BlockChain, chai
 		Fatalf("--%s must be either 'full' or 'archive'", GCModeFlag.Name)
 	}
 	cache := &core.CacheConfig{
-		Disabled:       ctx.GlobalString(GCModeFlag.Name) == "archive",
-		TrieCleanLimit: eth.DefaultConfig.TrieCleanCache,
-		TrieDirtyLimit: eth.DefaultConfig.TrieDirtyCache,
-		TrieTimeLimit:  eth.DefaultConfig.TrieTimeout,
+		Disabled:            ctx.GlobalString(GCModeFlag.Name) == "archive",
+		TrieCleanLimit:      eth.DefaultConfig.TrieCleanCache,
+		TrieCleanNoPrefetch: ctx.GlobalBool(CacheNoPrefetchFlag.Name),
+		TrieDirtyLimit:      eth.DefaultConfig.TrieDirtyCache,
+		TrieTimeLimit:       eth.DefaultConfig.TrieTimeout,
 	}
 	if ctx.GlobalIsSet(CacheFlag.Name) || ctx.GlobalIsSet(CacheTrieFlag.Name) {
 		cache.TrieCleanLimit = ctx.GlobalInt(CacheFlag.Name) * ctx.GlobalInt(CacheTrieFlag.Name) / 100
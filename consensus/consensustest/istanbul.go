@@ -0,0 +1,72 @@
+// Copyright 2017 The Celo Authors
+// This file is part of the celo library.
+//
+// The celo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The celo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the celo library. If not, see <http://www.gnu.org/licenses/>.
+
+package consensustest
+
+import (
+	"crypto/ecdsa"
+
+	bls "github.com/celo-org/bls-zexe/go"
+	"github.com/ethereum/go-ethereum/consensus/istanbul"
+	"github.com/ethereum/go-ethereum/crypto"
+	blscrypto "github.com/ethereum/go-ethereum/crypto/bls"
+)
+
+// GenerateValidators generates n istanbul validators with new keys, returning
+// them along with their BLS and ECDSA private keys.
+func GenerateValidators(n int) ([]istanbul.ValidatorData, [][]byte, []*ecdsa.PrivateKey, error) {
+	vals := make([]istanbul.ValidatorData, 0, n)
+	blsKeys := make([][]byte, 0, n)
+	keys := make([]*ecdsa.PrivateKey, 0, n)
+	for i := 0; i < n; i++ {
+		privateKey, err := crypto.GenerateKey()
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		blsPrivateKey, err := blscrypto.ECDSAToBLS(privateKey)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		blsPublicKey, err := blscrypto.PrivateToPublic(blsPrivateKey)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		vals = append(vals, istanbul.ValidatorData{
+			Address:      crypto.PubkeyToAddress(privateKey.PublicKey),
+			BLSPublicKey: blsPublicKey,
+		})
+		keys = append(keys, privateKey)
+		blsKeys = append(blsKeys, blsPrivateKey)
+	}
+	return vals, blsKeys, keys, nil
+}
+
+// SignBLSMessage signs the given data with the BLS private key, as validators
+// sign the headers they commit.
+func SignBLSMessage(blsKey []byte, data []byte) ([]byte, error) {
+	privateKey, err := bls.DeserializePrivateKey(blsKey)
+	if err != nil {
+		return nil, err
+	}
+	defer privateKey.Destroy()
+
+	signature, err := privateKey.SignMessage(data, []byte{}, false)
+	if err != nil {
+		return nil, err
+	}
+	defer signature.Destroy()
+	return signature.Serialize()
+}
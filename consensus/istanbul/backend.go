@@ -88,6 +88,13 @@ type Backend interface {
 	// RefreshValPeers will connect with all the validators in the valset and disconnect validator peers that are not in the set
 	RefreshValPeers(valset ValidatorSet)
 
+	// IsPrimaryForSeq returns whether the node signs consensus messages for the given
+	// sequence, as opposed to following consensus as a replica
+	IsPrimaryForSeq(seq *big.Int) bool
+
+	// UpdateReplicaState applies a scheduled replica handover once the given sequence reached it
+	UpdateReplicaState(seq *big.Int)
+
 	// Authorize injects a private key into the consensus engine.
 	Authorize(address common.Address, signFn SignerFn, signHashBLSFn SignerFn, signMessageBLSFn MessageSignerFn)
 }
@@ -35,6 +35,21 @@ import (
 	"github.com/ethereum/go-ethereum/rlp"
 )
 
+const (
+	// announceVersion is the version of the announce protocol spoken by this node.
+	// Announce and announce request messages of other versions are dropped.
+	announceVersion uint = 1
+
+	// enodeCertificateTTL is the time after which an enode certificate expires.
+	// Validators announce themselves every minute, so a certificate older than
+	// this is for an enode that may have changed since.
+	enodeCertificateTTL = 10 * time.Minute
+
+	// announceRequestTTL is the time after which an announce request is stale, and
+	// is neither answered nor relayed any more.
+	announceRequestTTL = time.Minute
+)
+
 // ===============================================================
 //
 // define the istanbul announce data and announce record structure
@@ -49,13 +64,27 @@ func (ar *announceRecord) String() string {
 }
 
 type announceData struct {
+	Version         uint
+	AnnounceRecords []*announceRecord
+	EnodeURLHash    common.Hash
+	Certificate     *istanbul.EnodeCertificate // nil for the announce messages of istanbul64 peers
+	View            *istanbul.View
+}
+
+// legacyAnnounceData is the layout of the announce messages of istanbul64 peers.
+type legacyAnnounceData struct {
 	AnnounceRecords []*announceRecord
 	EnodeURLHash    common.Hash
 	View            *istanbul.View
 }
 
 func (ad *announceData) String() string {
-	return fmt.Sprintf("{View: %v, EnodeURLHash: %v, AnnounceRecords: %v}", ad.View, ad.EnodeURLHash.Hex(), ad.AnnounceRecords)
+	return fmt.Sprintf("{Version: %d, View: %v, EnodeURLHash: %v, Certificate: %v, AnnounceRecords: %v}", ad.Version, ad.View, ad.EnodeURLHash.Hex(), ad.Certificate, ad.AnnounceRecords)
+}
+
+// isLegacy returns whether the announce data is in the layout of istanbul64 peers.
+func (ad *announceData) isLegacy() bool {
+	return ad.Certificate == nil
 }
 
 // ==============================================
@@ -81,23 +110,47 @@ func (ar *announceRecord) DecodeRLP(s *rlp.Stream) error {
 	return nil
 }
 
-// EncodeRLP serializes ad into the Ethereum RLP format.
+// EncodeRLP serializes ad into the Ethereum RLP format, in the layout of
+// istanbul64 peers if it has no certificate.
 func (ad *announceData) EncodeRLP(w io.Writer) error {
-	return rlp.Encode(w, []interface{}{ad.AnnounceRecords, ad.EnodeURLHash, ad.View})
+	if ad.isLegacy() {
+		return rlp.Encode(w, []interface{}{ad.AnnounceRecords, ad.EnodeURLHash, ad.View})
+	}
+	return rlp.Encode(w, []interface{}{ad.Version, ad.AnnounceRecords, ad.Certificate, ad.View})
 }
 
 // DecodeRLP implements rlp.Decoder, and load the ad fields from a RLP stream.
+// The announce messages of istanbul64 peers start with the announce records
+// rather than a version, and are loaded without a certificate.
 func (ad *announceData) DecodeRLP(s *rlp.Stream) error {
+	raw, err := s.Raw()
+	if err != nil {
+		return err
+	}
+	content, _, err := rlp.SplitList(raw)
+	if err != nil {
+		return err
+	}
+	if kind, _, _, err := rlp.Split(content); err == nil && kind == rlp.List {
+		var msg legacyAnnounceData
+		if err := rlp.DecodeBytes(raw, &msg); err != nil {
+			return err
+		}
+		*ad = announceData{AnnounceRecords: msg.AnnounceRecords, EnodeURLHash: msg.EnodeURLHash, View: msg.View}
+		return nil
+	}
+
 	var msg struct {
+		Version         uint
 		AnnounceRecords []*announceRecord
-		EnodeURLHash    common.Hash
+		Certificate     *istanbul.EnodeCertificate
 		View            *istanbul.View
 	}
 
-	if err := s.Decode(&msg); err != nil {
+	if err := rlp.DecodeBytes(raw, &msg); err != nil {
 		return err
 	}
-	ad.AnnounceRecords, ad.EnodeURLHash, ad.View = msg.AnnounceRecords, msg.EnodeURLHash, msg.View
+	*ad = announceData{Version: msg.Version, AnnounceRecords: msg.AnnounceRecords, EnodeURLHash: msg.Certificate.EnodeURLHash, Certificate: msg.Certificate, View: msg.View}
 	return nil
 }
 
@@ -117,6 +170,7 @@ func (sb *Backend) sendAnnounceMsgs() {
 			// output the valEnodeTable for debugging purposes
 			log.Trace("ValidatorEnodeDB dump", "ValidatorEnodeDB", sb.valEnodeTable.String())
 			go sb.sendIstAnnounce()
+			go sb.refreshValEnodes()
 		case <-sb.announceQuit:
 			ticker.Stop()
 			return
@@ -124,7 +178,9 @@ func (sb *Backend) sendAnnounceMsgs() {
 	}
 }
 
-func (sb *Backend) generateIstAnnounce() (*istanbul.Message, error) {
+// generateIstAnnounce generates an announce message in the layout of the peers
+// of the given protocol version.
+func (sb *Backend) generateIstAnnounce(version int) (*istanbul.Message, error) {
 	var enodeUrl string
 	if sb.config.Proxied {
 		if sb.proxyNode != nil {
@@ -156,6 +212,16 @@ func (sb *Backend) generateIstAnnounce() (*istanbul.Message, error) {
 		View:            view,
 	}
 
+	// Certify the enode URL, so that it can be verified apart from this message
+	if hasCertifiedAnnounces(version) {
+		certificate := istanbul.NewEnodeCertificate(enodeUrl, now())
+		if err := certificate.Sign(sb.Sign); err != nil {
+			sb.logger.Error("Error in signing an enode certificate", "err", err)
+			return nil, err
+		}
+		announceData.Version, announceData.Certificate = announceVersion, certificate
+	}
+
 	announceBytes, err := rlp.EncodeToBytes(announceData)
 	if err != nil {
 		sb.logger.Error("Error encoding announce content in an Istanbul Validator Enode Share message", "AnnounceData", announceData.String(), "err", err)
@@ -175,37 +241,60 @@ func (sb *Backend) generateIstAnnounce() (*istanbul.Message, error) {
 }
 
 func (sb *Backend) sendIstAnnounce() error {
-	istMsg, err := sb.generateIstAnnounce()
-	if err != nil {
-		return err
-	}
-
-	if istMsg == nil {
+	// Replicas don't announce themselves as the validator
+	if !sb.IsPrimary() {
 		return nil
 	}
 
-	// Sign the announce message
-	if err := istMsg.Sign(sb.Sign); err != nil {
-		sb.logger.Error("Error in signing an Istanbul Announce Message", "AnnounceMsg", istMsg.String(), "err", err)
-		return err
-	}
+	// Peers can only decode the announce messages in the layout of their version
+	for _, version := range []int{istanbul65, istanbul64} {
+		istMsg, err := sb.generateIstAnnounce(version)
+		if err != nil {
+			return err
+		}
 
-	// Convert to payload
-	payload, err := istMsg.Payload()
-	if err != nil {
-		sb.logger.Error("Error in converting Istanbul Announce Message to payload", "AnnounceMsg", istMsg.String(), "err", err)
-		return err
-	}
+		if istMsg == nil {
+			return nil
+		}
 
-	sb.Gossip(nil, payload, istanbulAnnounceMsg, true)
+		// Sign the announce message
+		if err := istMsg.Sign(sb.Sign); err != nil {
+			sb.logger.Error("Error in signing an Istanbul Announce Message", "AnnounceMsg", istMsg.String(), "err", err)
+			return err
+		}
+
+		// Convert to payload
+		payload, err := istMsg.Payload()
+		if err != nil {
+			sb.logger.Error("Error in converting Istanbul Announce Message to payload", "AnnounceMsg", istMsg.String(), "err", err)
+			return err
+		}
+
+		sb.gossipIstAnnounce(payload, !hasCertifiedAnnounces(version))
+	}
 
 	return nil
 }
 
+// gossipIstAnnounce sends the announce message to the peers that can decode its
+// layout. A proxied validator also sends the istanbul64 layout to its proxy,
+// which relays it to its istanbul64 peers.
+func (sb *Backend) gossipIstAnnounce(payload []byte, legacy bool) {
+	sb.gossip(nil, payload, istanbulAnnounceMsg, true, func(version int) bool {
+		return hasCertifiedAnnounces(version) != legacy || (legacy && sb.config.Proxied)
+	})
+}
+
 func (sb *Backend) retrieveActiveAndRegisteredValidators() (map[common.Address]bool, error) {
 	validatorsSet := make(map[common.Address]bool)
 
-	registeredValidators, err := validators.RetrieveRegisteredValidators(nil, nil)
+	block := sb.currentBlock()
+	state, err := sb.stateAt(block.Hash())
+	if err != nil {
+		sb.logger.Error("Error in retrieving the state of the current block", "err", err)
+		return validatorsSet, err
+	}
+	registeredValidators, err := validators.RetrieveRegisteredValidators(block.Header(), state)
 
 	// The validator contract may not be deployed yet.
 	// Even if it is deployed, it may not have any registered validators yet.
@@ -221,7 +310,6 @@ func (sb *Backend) retrieveActiveAndRegisteredValidators() (map[common.Address]b
 	}
 
 	// Add active validators regardless
-	block := sb.currentBlock()
 	valSet := sb.getValidators(block.Number().Uint64(), block.Hash())
 	for _, val := range valSet.List() {
 		validatorsSet[val.Address()] = true
@@ -256,10 +344,25 @@ func (sb *Backend) handleIstAnnounce(payload []byte) error {
 		return err
 	}
 
+	if !announceData.isLegacy() && announceData.Version != announceVersion {
+		logger.Debug("Received an announce message with an unsupported version", "from", msg.Address, "version", announceData.Version)
+		return errUnsupportedAnnounceVersion
+	}
+
 	logger = logger.New("msgAddress", msg.Address, "msg_round", announceData.View.Round, "msg_seq", announceData.View.Sequence)
 
-	if view, err := sb.valEnodeTable.GetViewFromAddress(msg.Address); err == nil && announceData.View.Cmp(view) <= 0 {
-		logger.Trace("Received an old announce message", "senderAddr", msg.Address, "messageView", announceData.View, "currentEntryView", view)
+	// Don't process, nor relay, announce messages with an invalid or stale enode certificate.
+	// Those of istanbul64 peers have none, and are only ordered by their view.
+	if !announceData.isLegacy() {
+		if err := announceData.Certificate.Verify(msg.Address, "", now(), enodeCertificateTTL); err != nil {
+			logger.Debug("Received an announce message with a bad enode certificate", "certificate", announceData.Certificate, "err", err)
+			return err
+		}
+	}
+
+	announceEntry := &vet.AddressEntry{View: announceData.View, Certificate: announceData.Certificate}
+	if entry, err := sb.valEnodeTable.GetEntryFromAddress(msg.Address); err == nil && !announceEntry.IsNewerThan(entry) {
+		logger.Trace("Received an old announce message", "senderAddr", msg.Address, "messageView", announceData.View, "currentEntryView", entry.View, "currentEntryTimestamp", entry.Timestamp())
 		return errOldAnnounceMessage
 	}
 
@@ -274,6 +377,15 @@ func (sb *Backend) handleIstAnnounce(payload []byte) error {
 		return errUnauthorizedAnnounceMessage
 	}
 
+	// Limit the rate of the announce messages of each validator, whichever peers
+	// relay them. Replays and unauthorized messages were dropped above, so they
+	// don't use up the rate of the validator.
+	if !sb.originLimiter.allow(msg.Address, time.Now()) {
+		logger.Debug("Dropping IstanbulAnnounce message exceeding the validator rate limit", "from", msg.Address)
+		droppedAnnounceMeter.Mark(1)
+		return nil
+	}
+
 	var node *enode.Node
 	var destAddresses = make([]string, 0, len(announceData.AnnounceRecords))
 	var processedAddresses = make(map[common.Address]bool)
@@ -288,6 +400,10 @@ func (sb *Backend) handleIstAnnounce(payload []byte) error {
 		if announceRecord.DestAddress == sb.Address() {
 			// TODO: Decrypt the enodeURL using this validator's validator key after making changes to encrypt it
 			enodeUrl := string(announceRecord.EncryptedEnodeURL)
+			if !announceData.isLegacy() && istanbul.RLPHash(enodeUrl) != announceData.EnodeURLHash {
+				logger.Warn("Received an enodeURL not matching its certificate", "enodeUrl", enodeUrl)
+				return istanbul.ErrInvalidEnodeCertificate
+			}
 			node, err = enode.ParseV4(enodeUrl)
 			if err != nil {
 				logger.Error("Error in parsing enodeURL", "enodeUrl", enodeUrl)
@@ -299,7 +415,7 @@ func (sb *Backend) handleIstAnnounce(payload []byte) error {
 	}
 	// Save in the valEnodeTable if mining
 	if sb.coreStarted && node != nil {
-		if err := sb.valEnodeTable.Upsert(map[common.Address]*vet.AddressEntry{msg.Address: {Node: node, View: announceData.View}}); err != nil {
+		if err := sb.valEnodeTable.Upsert(map[common.Address]*vet.AddressEntry{msg.Address: {Node: node, View: announceData.View, Certificate: announceData.Certificate}}); err != nil {
 			logger.Warn("Error in upserting a valenode entry", "AnnounceData", announceData.String(), "error", err)
 			return err
 		}
@@ -322,8 +438,10 @@ func (sb *Backend) regossipIstAnnounce(msg *istanbul.Message, payload []byte, an
 	sort.Strings(destAddresses)
 	destAddressesHash := istanbul.RLPHash(destAddresses)
 
+	gossipKey := announceGossipKey{address: msg.Address, legacy: announceData.isLegacy()}
+
 	sb.lastAnnounceGossipedMu.RLock()
-	if lastGossipTs, ok := sb.lastAnnounceGossiped[msg.Address]; ok {
+	if lastGossipTs, ok := sb.lastAnnounceGossiped[gossipKey]; ok {
 
 		if lastGossipTs.enodeURLHash == announceData.EnodeURLHash && bytes.Equal(lastGossipTs.destAddressesHash.Bytes(), destAddressesHash.Bytes()) && time.Since(lastGossipTs.timestamp) < time.Minute {
 			logger.Trace("Already regossiped the msg within the last minute, so not regossiping.", "IstanbulMsg", msg.String(), "AnnounceData", announceData.String())
@@ -333,19 +451,30 @@ func (sb *Backend) regossipIstAnnounce(msg *istanbul.Message, payload []byte, an
 	}
 	sb.lastAnnounceGossipedMu.RUnlock()
 
+	// Don't let a single validator use this node to amplify its announce messages
+	limiter := sb.announceLimiter
+	if announceData.isLegacy() {
+		limiter = sb.legacyAnnounceLimiter
+	}
+	if !limiter.allow(msg.Address, time.Now()) {
+		logger.Debug("Not regossiping the announce message, rate limit exceeded", "IstanbulMsg", msg.String())
+		droppedRegossipMeter.Mark(1)
+		return nil
+	}
+
 	logger.Trace("Regossiping the istanbul announce message", "IstanbulMsg", msg.String(), "AnnounceMsg", announceData.String())
-	sb.Gossip(nil, payload, istanbulAnnounceMsg, true)
+	sb.gossipIstAnnounce(payload, announceData.isLegacy())
 
 	sb.lastAnnounceGossipedMu.Lock()
 	defer sb.lastAnnounceGossipedMu.Unlock()
-	sb.lastAnnounceGossiped[msg.Address] = &AnnounceGossipTimestamp{enodeURLHash: announceData.EnodeURLHash, timestamp: time.Now(), destAddressesHash: destAddressesHash}
+	sb.lastAnnounceGossiped[gossipKey] = &AnnounceGossipTimestamp{enodeURLHash: announceData.EnodeURLHash, timestamp: time.Now(), destAddressesHash: destAddressesHash}
 
 	// prune non registered validator entries in the valEnodeTable, reverseValEnodeTable, and lastAnnounceGossiped tables about 5% of the times that an announce msg is handled
 	if (mrand.Int() % 100) <= 5 {
-		for remoteAddress := range sb.lastAnnounceGossiped {
-			if !regAndActiveVals[remoteAddress] {
-				logger.Trace("Deleting entry from the lastAnnounceGossiped table", "address", remoteAddress, "gossip timestamp", sb.lastAnnounceGossiped[remoteAddress])
-				delete(sb.lastAnnounceGossiped, remoteAddress)
+		for gossipKey := range sb.lastAnnounceGossiped {
+			if !regAndActiveVals[gossipKey.address] {
+				logger.Trace("Deleting entry from the lastAnnounceGossiped table", "address", gossipKey.address, "gossip timestamp", sb.lastAnnounceGossiped[gossipKey])
+				delete(sb.lastAnnounceGossiped, gossipKey)
 			}
 		}
 
@@ -356,3 +485,217 @@ func (sb *Backend) regossipIstAnnounce(msg *istanbul.Message, payload []byte, an
 
 	return nil
 }
+
+// ===============================================================
+//
+// define the istanbul announce request, asking validators for a fresh announce
+
+type announceRequest struct {
+	Version   uint
+	Timestamp uint64 // Unix time in seconds at which the request was sent
+	Addresses []common.Address
+}
+
+func (ar *announceRequest) String() string {
+	return fmt.Sprintf("{Version: %d, Timestamp: %d, Addresses: %v}", ar.Version, ar.Timestamp, ar.Addresses)
+}
+
+// isStale returns whether the request was sent more than announceRequestTTL before
+// now, or claims to be sent more than announceRequestTTL after now.
+func (ar *announceRequest) isStale(now time.Time) bool {
+	sent := time.Unix(int64(ar.Timestamp), 0)
+	return now.Sub(sent) > announceRequestTTL || sent.Sub(now) > announceRequestTTL
+}
+
+// refreshValEnodes removes the valEnodeTable entries whose enode certificate
+// expired, and asks the registered validators without an entry for a fresh
+// announce message.
+func (sb *Backend) refreshValEnodes() error {
+	regAndActiveVals, err := sb.retrieveActiveAndRegisteredValidators()
+	if err != nil {
+		return err
+	}
+	entries, err := sb.valEnodeTable.GetAllValEnodes()
+	if err != nil {
+		return err
+	}
+
+	for address, entry := range entries {
+		if sb.isValEnodeExpired(entry) {
+			sb.logger.Debug("Removing an expired entry from the valEnodeTable", "address", address, "timestamp", entry.Timestamp(), "view", entry.View)
+			if err := sb.valEnodeTable.RemoveEntry(address); err != nil {
+				return err
+			}
+			delete(entries, address)
+		}
+	}
+
+	var missing []common.Address
+	for address := range regAndActiveVals {
+		if _, ok := entries[address]; !ok && address != sb.Address() {
+			missing = append(missing, address)
+		}
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+	return sb.sendAnnounceRequest(missing)
+}
+
+// isValEnodeExpired returns whether a valEnodeTable entry is too old to be relied on.
+// Entries of istanbul64 validators have no certificate, so they expire once the
+// sequence they were announced at is as many blocks behind the head as the
+// certificate TTL spans.
+func (sb *Backend) isValEnodeExpired(entry *vet.AddressEntry) bool {
+	if entry.Certificate != nil {
+		return entry.Certificate.IsExpired(now(), enodeCertificateTTL)
+	}
+	if entry.View == nil || entry.View.Sequence == nil {
+		return true
+	}
+	ttlBlocks := uint64(enodeCertificateTTL / time.Second)
+	if sb.config.BlockPeriod > 0 {
+		ttlBlocks /= sb.config.BlockPeriod
+	}
+	head := sb.currentBlock().NumberU64()
+	return head > ttlBlocks && entry.View.Sequence.Uint64() < head-ttlBlocks
+}
+
+// sendAnnounceRequest asks the given validators to send a fresh announce message.
+func (sb *Backend) sendAnnounceRequest(addresses []common.Address) error {
+	// Replicas don't sign messages on behalf of the validator
+	if !sb.IsPrimary() {
+		return nil
+	}
+
+	request := &announceRequest{Version: announceVersion, Timestamp: uint64(now().Unix()), Addresses: addresses}
+	requestBytes, err := rlp.EncodeToBytes(request)
+	if err != nil {
+		sb.logger.Error("Error encoding announce request content", "AnnounceRequest", request.String(), "err", err)
+		return err
+	}
+
+	msg := &istanbul.Message{
+		Code:      istanbulAnnounceRequestMsg,
+		Msg:       requestBytes,
+		Address:   sb.Address(),
+		Signature: []byte{},
+	}
+
+	// Sign the announce request message
+	if err := msg.Sign(sb.Sign); err != nil {
+		sb.logger.Error("Error in signing an Istanbul Announce Request Message", "AnnounceRequestMsg", msg.String(), "err", err)
+		return err
+	}
+
+	// Convert to payload
+	payload, err := msg.Payload()
+	if err != nil {
+		sb.logger.Error("Error in converting Istanbul Announce Request Message to payload", "AnnounceRequestMsg", msg.String(), "err", err)
+		return err
+	}
+
+	sb.logger.Debug("Requesting fresh announce messages", "AnnounceRequest", request.String())
+	sb.Gossip(nil, payload, istanbulAnnounceRequestMsg, true)
+
+	return nil
+}
+
+func (sb *Backend) handleAnnounceRequest(payload []byte) error {
+	logger := sb.logger.New("func", "handleAnnounceRequest")
+
+	msg := new(istanbul.Message)
+
+	// Decode message
+	if err := msg.FromPayload(payload, istanbul.GetSignatureAddress); err != nil {
+		logger.Error("Error in decoding received Istanbul Announce Request message", "err", err, "payload", hex.EncodeToString(payload))
+		return err
+	}
+
+	// If the message is originally from this node, then ignore it
+	if msg.Address == sb.Address() {
+		return nil
+	}
+
+	var request announceRequest
+	if err := rlp.DecodeBytes(msg.Msg, &request); err != nil {
+		logger.Error("Error in decoding received Istanbul Announce Request message content", "err", err, "IstanbulMsg", msg.String())
+		return err
+	}
+
+	if request.Version != announceVersion {
+		logger.Debug("Received an announce request with an unsupported version", "from", msg.Address, "version", request.Version)
+		return errUnsupportedAnnounceVersion
+	}
+
+	// The timestamp is signed along with the request, so a captured request can
+	// only be replayed until it goes stale, and only once per validator and timestamp
+	if request.isStale(now()) {
+		logger.Debug("Received a stale announce request", "from", msg.Address, "timestamp", request.Timestamp)
+		return errOldAnnounceRequest
+	}
+	if !sb.updateLastAnnounceRequest(msg.Address, request.Timestamp) {
+		logger.Trace("Received an already handled announce request", "from", msg.Address, "timestamp", request.Timestamp)
+		return errOldAnnounceRequest
+	}
+
+	// Only registered validators may request announce messages
+	regAndActiveVals, err := sb.retrieveActiveAndRegisteredValidators()
+	if err != nil {
+		return err
+	}
+	if !regAndActiveVals[msg.Address] {
+		logger.Warn("Received an announce request from a non registered validator. Ignoring it.", "from", msg.Address)
+		return errUnauthorizedAnnounceMessage
+	}
+
+	// Limit the rate of the announce requests of each validator, whichever peers
+	// relay them, apart from the rate of its announce messages
+	if !sb.requestOriginLimiter.allow(msg.Address, time.Now()) {
+		logger.Debug("Dropping Istanbul Announce Request message exceeding the validator rate limit", "from", msg.Address)
+		droppedAnnounceRequestMeter.Mark(1)
+		return nil
+	}
+
+	requested := false
+	for _, address := range request.Addresses {
+		if address == sb.Address() {
+			requested = true
+			break
+		}
+	}
+
+	// Announce this validator, at most as often as the rate limit of the requester allows
+	if requested && sb.coreStarted && sb.announceRequestLimiter.allow(msg.Address, now()) {
+		logger.Debug("Sending a fresh announce message upon request", "from", msg.Address)
+		go sb.sendIstAnnounce()
+	}
+
+	// Relay the request to the other requested validators
+	if !requested || len(request.Addresses) > 1 {
+		sb.Gossip(nil, payload, istanbulAnnounceRequestMsg, true)
+	}
+
+	return nil
+}
+
+// updateLastAnnounceRequest records the timestamp of the latest announce request of
+// a validator. It returns false if the request is not newer than the last one
+// handled from that validator.
+func (sb *Backend) updateLastAnnounceRequest(address common.Address, timestamp uint64) bool {
+	sb.lastAnnounceRequestMu.Lock()
+	defer sb.lastAnnounceRequestMu.Unlock()
+
+	if last, ok := sb.lastAnnounceRequest[address]; ok && timestamp <= last {
+		return false
+	}
+	sb.lastAnnounceRequest[address] = timestamp
+
+	// Forget the requests that went stale, replays of those are rejected anyway
+	for other, last := range sb.lastAnnounceRequest {
+		if (&announceRequest{Timestamp: last}).isStale(now()) {
+			delete(sb.lastAnnounceRequest, other)
+		}
+	}
+	return true
+}
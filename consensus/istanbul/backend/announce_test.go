@@ -1,11 +1,18 @@
 package backend
 
 import (
+	"math/big"
 	"net"
 	"testing"
+	"time"
 
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/consensus/consensustest"
+	"github.com/ethereum/go-ethereum/consensus/istanbul"
+	vet "github.com/ethereum/go-ethereum/consensus/istanbul/backend/internal/enodes"
+	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/p2p/enode"
+	"github.com/ethereum/go-ethereum/rlp"
 )
 
 func TestHandleIstAnnounce(t *testing.T) {
@@ -28,17 +35,23 @@ func TestHandleIstAnnounce(t *testing.T) {
 	b.Authorize(val1Addr, signerFn, signerBLSHashFn, signerBLSMessageFn)
 
 	// Generate an ist announce message using val1
-	istMsg, err := b.generateIstAnnounce()
+	istMsg, err := b.generateIstAnnounce(istanbul65)
 	istMsg.Sign(b.Sign)
 	payload, _ := istMsg.Payload()
 
 	// Set backend to val2
 	b.address = valSet.GetByIndex(2).Address()
 
+	// Allow a single announce message per validator
+	b.originLimiter = newRateLimiter(0.001, 1)
+
 	// Handle val1's announce message
 	if err = b.handleIstAnnounce(payload); err != nil {
 		t.Errorf("error %v", err)
 	}
+	if b.originLimiter.allow(val1Addr, time.Now()) {
+		t.Errorf("announce message not rate limited by its origin")
+	}
 
 	if node, err := b.valEnodeTable.GetNodeFromAddress(val1Addr); err == nil {
 		if node == nil || node.String() != val1Node.String() {
@@ -48,3 +61,215 @@ func TestHandleIstAnnounce(t *testing.T) {
 		t.Errorf("Failed to save enode entry")
 	}
 }
+
+func TestHandleIstAnnounceStaleCertificate(t *testing.T) {
+	_, b := newBlockChain(4, true)
+	for b == nil || b.Address() == getAddress() {
+		_, b = newBlockChain(4, true)
+	}
+
+	block := b.currentBlock()
+	valSet := b.getValidators(block.Number().Uint64(), block.Hash())
+
+	val1PrivateKey, _ := generatePrivateKey()
+	val1Node := enode.NewV4(&val1PrivateKey.PublicKey, net.ParseIP("1.2.3.4"), 0, 0)
+	val1Addr := getAddress()
+
+	b.SetP2PServer(&consensustest.MockP2PServer{Node: val1Node})
+	b.Authorize(val1Addr, signerFn, signerBLSHashFn, signerBLSMessageFn)
+
+	// Generate an ist announce message certified an hour ago
+	now = func() time.Time { return time.Now().Add(-time.Hour) }
+	istMsg, err := b.generateIstAnnounce(istanbul65)
+	now = time.Now
+	if err != nil {
+		t.Fatalf("failed to generate announce message: %v", err)
+	}
+	istMsg.Sign(b.Sign)
+	payload, _ := istMsg.Payload()
+
+	// Handle val1's announce message as val2
+	b.address = valSet.GetByIndex(2).Address()
+	if err := b.handleIstAnnounce(payload); err != istanbul.ErrExpiredEnodeCertificate {
+		t.Errorf("error mismatch: have %v, want %v", err, istanbul.ErrExpiredEnodeCertificate)
+	}
+	if _, err := b.valEnodeTable.GetNodeFromAddress(val1Addr); err == nil {
+		t.Errorf("Saved the enode of a stale certificate")
+	}
+}
+
+func TestHandleIstAnnounceReplay(t *testing.T) {
+	_, b := newBlockChain(4, true)
+	for b == nil || b.Address() == getAddress() {
+		_, b = newBlockChain(4, true)
+	}
+
+	block := b.currentBlock()
+	valSet := b.getValidators(block.Number().Uint64(), block.Hash())
+
+	val1PrivateKey, _ := generatePrivateKey()
+	val1Node := enode.NewV4(&val1PrivateKey.PublicKey, net.ParseIP("1.2.3.4"), 0, 0)
+	val1Addr := getAddress()
+
+	b.SetP2PServer(&consensustest.MockP2PServer{Node: val1Node})
+	b.Authorize(val1Addr, signerFn, signerBLSHashFn, signerBLSMessageFn)
+
+	// Generate ist announce messages of val1 certified at the given times
+	newAnnounce := func(certified time.Time) []byte {
+		now = func() time.Time { return certified }
+		defer func() { now = time.Now }()
+
+		istMsg, err := b.generateIstAnnounce(istanbul65)
+		if err != nil {
+			t.Fatalf("failed to generate announce message: %v", err)
+		}
+		istMsg.Sign(b.Sign)
+		payload, _ := istMsg.Payload()
+		return payload
+	}
+	certified := time.Now()
+	old := newAnnounce(certified.Add(-time.Minute))
+	newer := newAnnounce(certified)
+
+	// Handle val1's announce messages as val2, allowing two per validator
+	b.address = valSet.GetByIndex(2).Address()
+	b.originLimiter = newRateLimiter(0.001, 2)
+
+	if err := b.handleIstAnnounce(old); err != nil {
+		t.Fatalf("failed to handle announce message: %v", err)
+	}
+	for i := 0; i < 3; i++ {
+		if err := b.handleIstAnnounce(old); err != errOldAnnounceMessage {
+			t.Errorf("replay %d: error mismatch: have %v, want %v", i, err, errOldAnnounceMessage)
+		}
+	}
+	if err := b.handleIstAnnounce(newer); err != nil {
+		t.Fatalf("failed to handle newer announce message: %v", err)
+	}
+	entry, err := b.valEnodeTable.GetEntryFromAddress(val1Addr)
+	if err != nil {
+		t.Fatalf("Failed to save enode entry: %v", err)
+	}
+	if want := uint64(certified.Unix()); entry.Timestamp() != want {
+		t.Errorf("newer announce message not saved: have timestamp %d, want %d", entry.Timestamp(), want)
+	}
+}
+
+func TestHandleLegacyIstAnnounce(t *testing.T) {
+	_, b := newBlockChain(4, true)
+	for b == nil || b.Address() == getAddress() {
+		_, b = newBlockChain(4, true)
+	}
+
+	block := b.currentBlock()
+	valSet := b.getValidators(block.Number().Uint64(), block.Hash())
+
+	val1PrivateKey, _ := generatePrivateKey()
+	val1Node := enode.NewV4(&val1PrivateKey.PublicKey, net.ParseIP("1.2.3.4"), 0, 0)
+	val1Addr := getAddress()
+
+	b.SetP2PServer(&consensustest.MockP2PServer{Node: val1Node})
+	b.Authorize(val1Addr, signerFn, signerBLSHashFn, signerBLSMessageFn)
+
+	// Generate an ist announce message for istanbul64 peers
+	istMsg, err := b.generateIstAnnounce(istanbul64)
+	if err != nil {
+		t.Fatalf("failed to generate announce message: %v", err)
+	}
+	var legacy legacyAnnounceData
+	if err := rlp.DecodeBytes(istMsg.Msg, &legacy); err != nil {
+		t.Fatalf("announce message not in the istanbul64 layout: %v", err)
+	}
+	if want := istanbul.RLPHash(val1Node.String()); legacy.EnodeURLHash != want {
+		t.Errorf("enode URL hash mismatch: have %x, want %x", legacy.EnodeURLHash, want)
+	}
+	istMsg.Sign(b.Sign)
+	payload, _ := istMsg.Payload()
+
+	// Handle val1's announce message as val2
+	b.address = valSet.GetByIndex(2).Address()
+	if err := b.handleIstAnnounce(payload); err != nil {
+		t.Fatalf("failed to handle announce message: %v", err)
+	}
+	entry, err := b.valEnodeTable.GetEntryFromAddress(val1Addr)
+	if err != nil {
+		t.Fatalf("Failed to save enode entry: %v", err)
+	}
+	if entry.Node.String() != val1Node.String() {
+		t.Errorf("Expected %v, but got %v instead", val1Node.String(), entry.Node)
+	}
+	if entry.Certificate != nil {
+		t.Errorf("Saved a certificate for an istanbul64 announce message: %v", entry.Certificate)
+	}
+}
+
+func TestHandleAnnounceRequestReplay(t *testing.T) {
+	_, b := newBlockChain(4, true)
+	for b == nil || b.Address() == getAddress() {
+		_, b = newBlockChain(4, true)
+	}
+
+	block := b.currentBlock()
+	valSet := b.getValidators(block.Number().Uint64(), block.Hash())
+	val1Addr := getAddress()
+	b.Authorize(val1Addr, signerFn, signerBLSHashFn, signerBLSMessageFn)
+
+	// Generate announce requests of val1 sent at the given times
+	newRequest := func(sent time.Time) []byte {
+		request := &announceRequest{Version: announceVersion, Timestamp: uint64(sent.Unix()), Addresses: []common.Address{valSet.GetByIndex(3).Address()}}
+		requestBytes, _ := rlp.EncodeToBytes(request)
+		msg := &istanbul.Message{Code: istanbulAnnounceRequestMsg, Msg: requestBytes, Address: val1Addr, Signature: []byte{}}
+		msg.Sign(b.Sign)
+		payload, _ := msg.Payload()
+		return payload
+	}
+	stale := newRequest(time.Now().Add(-time.Hour))
+	fresh := newRequest(time.Now())
+
+	// Handle val1's announce requests as val2, allowing two requests but no
+	// announce messages per validator
+	b.address = valSet.GetByIndex(2).Address()
+	b.originLimiter = newRateLimiter(0, 0)
+	b.requestOriginLimiter = newRateLimiter(0.001, 2)
+
+	if err := b.handleAnnounceRequest(stale); err != errOldAnnounceRequest {
+		t.Errorf("stale request: error mismatch: have %v, want %v", err, errOldAnnounceRequest)
+	}
+	if err := b.handleAnnounceRequest(fresh); err != nil {
+		t.Errorf("fresh request: error %v", err)
+	}
+	if err := b.handleAnnounceRequest(fresh); err != errOldAnnounceRequest {
+		t.Errorf("replayed request: error mismatch: have %v, want %v", err, errOldAnnounceRequest)
+	}
+	// Only the fresh request counts towards the rate limit of the validator
+	if !b.requestOriginLimiter.allow(val1Addr, time.Now()) {
+		t.Errorf("stale or replayed announce requests rate limited")
+	}
+	if b.requestOriginLimiter.allow(val1Addr, time.Now()) {
+		t.Errorf("fresh announce request not rate limited by its origin")
+	}
+}
+
+func TestLegacyValEnodeExpiry(t *testing.T) {
+	_, b := newBlockChain(4, true)
+	b.currentBlock = func() *types.Block {
+		return types.NewBlockWithHeader(&types.Header{Number: big.NewInt(10000)})
+	}
+
+	ttlBlocks := uint64(enodeCertificateTTL/time.Second) / b.config.BlockPeriod
+	tests := []struct {
+		sequence uint64
+		expired  bool
+	}{
+		{sequence: 10000, expired: false},
+		{sequence: 10000 - ttlBlocks, expired: false},
+		{sequence: 10000 - ttlBlocks - 1, expired: true},
+		{sequence: 0, expired: true},
+	}
+	for i, tt := range tests {
+		entry := &vet.AddressEntry{View: &istanbul.View{Sequence: new(big.Int).SetUint64(tt.sequence), Round: common.Big0}}
+		if expired := b.isValEnodeExpired(entry); expired != tt.expired {
+			t.Errorf("test %d: expiry mismatch: have %v, want %v", i, expired, tt.expired)
+		}
+	}
+}
@@ -19,10 +19,15 @@ package backend
 import (
 	"errors"
 	"fmt"
+	"math/big"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/consensus"
 	"github.com/ethereum/go-ethereum/consensus/istanbul"
+	istanbulCore "github.com/ethereum/go-ethereum/consensus/istanbul/core"
+	"github.com/ethereum/go-ethereum/contract_comm/election"
+	"github.com/ethereum/go-ethereum/core/state"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/p2p/enode"
 	"github.com/ethereum/go-ethereum/rpc"
@@ -96,6 +101,145 @@ func (api *API) GetValidatorsAtHash(hash common.Hash) ([]common.Address, error)
 	return validatorsAddresses, nil
 }
 
+// GroupVotes is the total of the votes for an eligible validator group.
+type GroupVotes struct {
+	Group common.Address `json:"group"`
+	Votes *hexutil.Big   `json:"votes"`
+}
+
+// VoteChange is a hypothetical change of the votes for an eligible validator
+// group. Value is added to the votes of the group, or if Revoke is set, revoked
+// from the active votes Voter cast for the group.
+type VoteChange struct {
+	Group  common.Address  `json:"group"`
+	Value  *hexutil.Big    `json:"value"`
+	Revoke bool            `json:"revoke"`
+	Voter  *common.Address `json:"voter"`
+}
+
+// NextEpochValidators is the predicted outcome of the election at the end of the
+// current epoch.
+type NextEpochValidators struct {
+	Number     hexutil.Uint64   `json:"number"` // Head block the election was run against
+	Validators []common.Address `json:"validators"`
+	Groups     []GroupVotes     `json:"groups"`
+}
+
+// GetNextEpochValidators runs the validator election against the state of the
+// current head, and returns the elected validators along with the vote totals of
+// the eligible groups. If vote changes are given, they are applied in order to a
+// copy of the head state before the election is run.
+func (api *API) GetNextEpochValidators(changes *[]VoteChange) (*NextEpochValidators, error) {
+	chain, ok := api.chain.(interface {
+		StateAt(root common.Hash) (*state.StateDB, error)
+	})
+	if !ok {
+		return nil, errNoHeadState
+	}
+	header := api.chain.CurrentHeader()
+	statedb, err := chain.StateAt(header.Root)
+	if err != nil {
+		return nil, err
+	}
+	if changes != nil {
+		for _, change := range *changes {
+			if change.Value == nil || change.Value.ToInt().Sign() <= 0 {
+				return nil, errInvalidVoteChange
+			}
+			if !change.Revoke {
+				err = election.IncrementGroupVotes(header, statedb, []common.Address{change.Group}, []*big.Int{change.Value.ToInt()})
+			} else if change.Voter == nil {
+				return nil, errNoVoter
+			} else {
+				err = election.DecrementGroupVotes(header, statedb, *change.Voter, change.Group, change.Value.ToInt())
+			}
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	validators, err := election.GetElectedValidators(header, statedb)
+	if err != nil {
+		return nil, err
+	}
+	voteTotals, err := election.GetTotalVotesForEligibleValidatorGroups(header, statedb)
+	if err != nil {
+		return nil, err
+	}
+	groups := make([]GroupVotes, len(voteTotals))
+	for i, voteTotal := range voteTotals {
+		groups[i] = GroupVotes{Group: voteTotal.Group, Votes: (*hexutil.Big)(voteTotal.Value)}
+	}
+	return &NextEpochValidators{
+		Number:     hexutil.Uint64(header.Number.Uint64()),
+		Validators: validators,
+		Groups:     groups,
+	}, nil
+}
+
+// SignerInfo describes the validator signing key in use and the one the validator
+// rotates to, if any.
+type SignerInfo struct {
+	Current common.Address  `json:"current"`
+	Next    *common.Address `json:"next"`
+}
+
+// GetSigners returns the current validator signing key and the pending rotation.
+func (api *API) GetSigners() *SignerInfo {
+	info := &SignerInfo{Current: api.istanbul.Address()}
+	if next, ok := api.istanbul.NextSigner(); ok {
+		info.Next = &next
+	}
+	return info
+}
+
+// GetRoundTimeline retrieves the consensus events recorded for the given sequence.
+// If no sequence is given, the timeline of the sequence following the current head is returned.
+func (api *API) GetRoundTimeline(sequence *rpc.BlockNumber) (*istanbulCore.RoundTimeline, error) {
+	var seq *big.Int
+	if sequence == nil || *sequence == rpc.LatestBlockNumber || *sequence == rpc.PendingBlockNumber {
+		seq = new(big.Int).Add(api.chain.CurrentHeader().Number, common.Big1)
+	} else {
+		seq = big.NewInt(sequence.Int64())
+	}
+	timeline := api.istanbul.core.RoundTimeline(seq)
+	if timeline == nil {
+		return nil, errUnknownTimeline
+	}
+	return timeline, nil
+}
+
+// StartValidating promotes a replica to primary immediately.
+func (api *API) StartValidating() error {
+	return api.istanbul.StartValidating()
+}
+
+// StopValidating demotes a primary to replica immediately.
+func (api *API) StopValidating() error {
+	return api.istanbul.StopValidating()
+}
+
+// StartValidatingAtBlock promotes a replica to primary from the given block on.
+func (api *API) StartValidatingAtBlock(number hexutil.Uint64) error {
+	return api.istanbul.StartValidatingAtBlock(uint64(number))
+}
+
+// StopValidatingAtBlock demotes a primary to replica from the given block on.
+func (api *API) StopValidatingAtBlock(number hexutil.Uint64) error {
+	return api.istanbul.StopValidatingAtBlock(uint64(number))
+}
+
+// ReplicaState returns whether the node is the primary or a replica of the
+// validator, and the scheduled handover.
+func (api *API) ReplicaState() *ReplicaInfo {
+	return api.istanbul.ReplicaState()
+}
+
+// RequestAnnounce asks the given validators to send a fresh announce message.
+func (api *API) RequestAnnounce(addresses []common.Address) error {
+	return api.istanbul.sendAnnounceRequest(addresses)
+}
+
 // AddProxy peers with a remote node that acts as a proxy, even if slots are full
 func (api *API) AddProxy(url, externalUrl string) (bool, error) {
 	if !api.istanbul.config.Proxied {
@@ -0,0 +1,137 @@
+// Copyright 2017 The celo Authors
+// This file is part of the celo library.
+//
+// The celo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The celo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the celo library. If not, see <http://www.gnu.org/licenses/>.
+
+package backend
+
+import (
+	"errors"
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// electionStubCode is a stand-in for the Election contract, tracking the votes
+// of the eligible groups 0x0a and 0x0b in storage slots 0 and 1:
+//
+//   getTotalVotesForEligibleValidatorGroups(): returns ([0x0a, 0x0b], [slot 0, slot 1])
+//   electValidatorSigners():                   returns [0x0b] if slot 1 > slot 0, else [0x0a]
+//   distributeEpochRewards(group, value, ...): adds value to the votes of the group
+//   revokeActive(group, value, ...):           subtracts value from the votes of the
+//                                              group, only callable by voter 0x0c
+//   getGroupsVotedForByAccount(account):       returns [0x0a, 0x0b]
+//
+// Calls for other groups and revocations of more than the votes revert.
+const electionStubCode = "6000357c0100000000000000000000000000000000000000000000000000000000900480637046c96b146100605780632ba38e691461009157806312541a6b146100b85780636e198475146100e4578063457578a31461012a575b60006000fd5b604060005260a06020526002604052600a606052600b608052600260a05260005460c05260015460e0526101006000f35b60206000526001602052600a60405260005460015411156100b257600b6040525b60606000f35b60043580600a146100cf57600b146100d75761005a565b5060006100da565b60015b8054602435019055005b33600c141561005a5760043580600a1461010457600b1461010c5761005a565b50600061010f565b60015b805460243581811161005a5790039055600160005260206000f35b60206000526002602052600a604052600b60605260806000f3"
+
+// stateChain serves a copy of the given state as the state of the head.
+type stateChain struct {
+	*core.BlockChain
+	state *state.StateDB
+}
+
+func (c *stateChain) StateAt(root common.Hash) (*state.StateDB, error) {
+	if root != c.CurrentHeader().Root {
+		return nil, errors.New("state of a block other than the head requested")
+	}
+	return c.state.Copy(), nil
+}
+
+func TestGetNextEpochValidators(t *testing.T) {
+	var (
+		groupA   = common.HexToAddress("0x0a")
+		groupB   = common.HexToAddress("0x0b")
+		voter    = common.HexToAddress("0x0c")
+		stranger = common.HexToAddress("0x0d")
+		election = common.HexToAddress("0xe1ec")
+	)
+	chain, engine := newBlockChain(1, true)
+	defer engine.Stop()
+
+	// The registry returns the election stub for any contract
+	statedb, err := chain.State()
+	if err != nil {
+		t.Fatalf("failed to get head state: %v", err)
+	}
+	statedb.SetCode(params.RegistrySmartContractAddress, append(append([]byte{0x73}, election.Bytes()...), 0x60, 0, 0x52, 0x60, 32, 0x60, 0, 0xf3))
+	statedb.SetCode(election, common.FromHex(electionStubCode))
+	statedb.SetState(election, common.BigToHash(big.NewInt(0)), common.BigToHash(big.NewInt(10)))
+	statedb.SetState(election, common.BigToHash(big.NewInt(1)), common.BigToHash(big.NewInt(5)))
+	api := &API{chain: &stateChain{chain, statedb}, istanbul: engine}
+
+	increase := func(group common.Address, value int64) VoteChange {
+		return VoteChange{Group: group, Value: (*hexutil.Big)(big.NewInt(value))}
+	}
+	revoke := func(voter common.Address, group common.Address, value int64) VoteChange {
+		return VoteChange{Group: group, Value: (*hexutil.Big)(big.NewInt(value)), Revoke: true, Voter: &voter}
+	}
+	tests := []struct {
+		name       string
+		changes    []VoteChange
+		validators []common.Address
+		votes      []int64 // of group A and B
+		err        bool
+	}{
+		{name: "no_changes", validators: []common.Address{groupA}, votes: []int64{10, 5}},
+		{name: "increase", changes: []VoteChange{increase(groupB, 10)}, validators: []common.Address{groupB}, votes: []int64{10, 15}},
+		{name: "decrease", changes: []VoteChange{revoke(voter, groupA, 8)}, validators: []common.Address{groupB}, votes: []int64{2, 5}},
+		{name: "increase_and_decrease", changes: []VoteChange{increase(groupB, 3), revoke(voter, groupA, 2)}, validators: []common.Address{groupA}, votes: []int64{8, 8}},
+		{name: "decrease_below_zero", changes: []VoteChange{revoke(voter, groupB, 6)}, err: true},
+		{name: "decrease_by_non_voter", changes: []VoteChange{revoke(stranger, groupA, 1)}, err: true},
+		{name: "decrease_without_voter", changes: []VoteChange{{Group: groupA, Value: (*hexutil.Big)(big.NewInt(1)), Revoke: true}}, err: true},
+		{name: "zero_value", changes: []VoteChange{increase(groupA, 0)}, err: true},
+		{name: "unknown_group_increase", changes: []VoteChange{increase(voter, 1)}, err: true},
+		{name: "unknown_group_decrease", changes: []VoteChange{revoke(voter, voter, 1)}, err: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var changes *[]VoteChange
+			if test.changes != nil {
+				changes = &test.changes
+			}
+			result, err := api.GetNextEpochValidators(changes)
+			if test.err {
+				if err == nil {
+					t.Fatalf("invalid vote changes accepted")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("failed to get next epoch validators: %v", err)
+			}
+			if !reflect.DeepEqual(result.Validators, test.validators) {
+				t.Errorf("validators mismatch: have %v, want %v", result.Validators, test.validators)
+			}
+			if len(result.Groups) != 2 {
+				t.Fatalf("group count mismatch: have %d, want 2", len(result.Groups))
+			}
+			for i, group := range []common.Address{groupA, groupB} {
+				if result.Groups[i].Group != group || result.Groups[i].Votes.ToInt().Int64() != test.votes[i] {
+					t.Errorf("group %d mismatch: have %s with %v votes, want %s with %d", i, result.Groups[i].Group.Hex(), result.Groups[i].Votes, group.Hex(), test.votes[i])
+				}
+			}
+		})
+	}
+	// The changes must not leak into the head state
+	if votes := statedb.GetState(election, common.BigToHash(big.NewInt(0))).Big(); votes.Int64() != 10 {
+		t.Errorf("head state modified: group A has %v votes", votes)
+	}
+}
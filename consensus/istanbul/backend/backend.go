@@ -67,8 +67,18 @@ var (
 	// errOldAnnounceMessage is returned when the received announce message's block number is earlier
 	// than a previous received message
 	errOldAnnounceMessage = errors.New("old announce message")
+
+	// errOldAnnounceRequest is returned when the announce request is stale or was already handled
+	errOldAnnounceRequest = errors.New("old announce request")
 )
 
+// Key of the recent announce messages, which are regossiped separately for
+// each layout
+type announceGossipKey struct {
+	address common.Address
+	legacy  bool
+}
+
 // Entries for the recent announce messages
 type AnnounceGossipTimestamp struct {
 	enodeURLHash      common.Hash
@@ -100,22 +110,31 @@ func New(config *istanbul.Config, db ethdb.Database) consensus.Istanbul {
 		logger.Crit("Failed to create known messages cache", "err", err)
 	}
 	backend := &Backend{
-		config:               config,
-		istanbulEventMux:     new(event.TypeMux),
-		logger:               logger,
-		db:                   db,
-		commitCh:             make(chan *types.Block, 1),
-		recentSnapshots:      recentSnapshots,
-		coreStarted:          false,
-		recentMessages:       recentMessages,
-		knownMessages:        knownMessages,
-		announceWg:           new(sync.WaitGroup),
-		announceQuit:         make(chan struct{}),
-		lastAnnounceGossiped: make(map[common.Address]*AnnounceGossipTimestamp),
-		valEnodesShareWg:     new(sync.WaitGroup),
-		valEnodesShareQuit:   make(chan struct{}),
-	}
-	backend.core = istanbulCore.New(backend, backend.config)
+		config:                 config,
+		istanbulEventMux:       new(event.TypeMux),
+		logger:                 logger,
+		db:                     db,
+		commitCh:               make(chan *types.Block, 1),
+		recentSnapshots:        recentSnapshots,
+		coreStarted:            false,
+		recentMessages:         recentMessages,
+		knownMessages:          knownMessages,
+		announceWg:             new(sync.WaitGroup),
+		announceQuit:           make(chan struct{}),
+		lastAnnounceGossiped:   make(map[announceGossipKey]*AnnounceGossipTimestamp),
+		lastAnnounceRequest:    make(map[common.Address]uint64),
+		originLimiter:          newRateLimiter(float64(config.PeerMessageRate), float64(config.PeerMessageRate)),
+		requestOriginLimiter:   newRateLimiter(float64(config.PeerMessageRate), float64(config.PeerMessageRate)),
+		fwdLimiter:             newRateLimiter(float64(config.PeerMessageRate), float64(config.PeerMessageRate)),
+		announceLimiter:        newRateLimiter(announceRegossipRate, announceRegossipBurst),
+		legacyAnnounceLimiter:  newRateLimiter(announceRegossipRate, announceRegossipBurst),
+		announceRequestLimiter: newRateLimiter(announceRegossipRate, announceRegossipBurst),
+		valEnodesShareWg:       new(sync.WaitGroup),
+		valEnodesShareQuit:     make(chan struct{}),
+		replicaState:           newReplicaState(db, config.Replica),
+		leavingValPeers:        make(map[enode.ID]*leavingValPeer),
+	}
+	backend.core = istanbulCore.New(backend, backend.config, db)
 
 	vph := &validatorPeerHandler{sb: backend}
 	table, err := enodes.OpenValidatorEnodeDB(config.ValidatorEnodeDBPath, vph)
@@ -129,6 +148,46 @@ func New(config *istanbul.Config, db ethdb.Database) consensus.Istanbul {
 
 // ----------------------------------------------------------------------------
 
+// signer is a signing key of the validator along with its signing functions.
+type signer struct {
+	address          common.Address
+	signFn           istanbul.SignerFn
+	signHashBLSFn    istanbul.SignerFn
+	signMessageBLSFn istanbul.MessageSignerFn
+}
+
+// dbKeySignerRotation is the database key of the validator's signer rotation.
+var dbKeySignerRotation = []byte("istanbul-signer-rotation")
+
+// signerRotation is the persisted state of a signer rotation, so a restarted
+// node keeps signing with the right key. Zero addresses mean none.
+type signerRotation struct {
+	Signer common.Address // Signing key the validator rotated to
+	Next   common.Address // Signing key the validator is rotating to
+}
+
+// readSignerRotation retrieves the persisted signer rotation, if any.
+func readSignerRotation(db ethdb.Database) signerRotation {
+	var rotation signerRotation
+	if blob, err := db.Get(dbKeySignerRotation); err == nil {
+		if err := rlp.DecodeBytes(blob, &rotation); err != nil {
+			log.Error("Invalid istanbul signer rotation", "err", err)
+		}
+	}
+	return rotation
+}
+
+// writeSignerRotation persists the given signer rotation.
+func writeSignerRotation(db ethdb.Database, rotation signerRotation) {
+	blob, err := rlp.EncodeToBytes(rotation)
+	if err != nil {
+		log.Crit("Failed to encode istanbul signer rotation", "err", err)
+	}
+	if err := db.Put(dbKeySignerRotation, blob); err != nil {
+		log.Error("Failed to store istanbul signer rotation", "err", err)
+	}
+}
+
 type Backend struct {
 	config           *istanbul.Config
 	istanbulEventMux *event.TypeMux
@@ -139,6 +198,11 @@ type Backend struct {
 	signMessageBLSFn istanbul.MessageSignerFn // Signer function to authorize messages using BLS with
 	signFnMu         sync.RWMutex             // Protects the signer fields
 
+	nextSigner *signer                     // Signing key the validator rotates to once it's elected (protected by signFnMu)
+	onRotate   func(signer common.Address) // Called after switching to the next signing key (protected by signFnMu)
+
+	replicaState *replicaState // Whether the node signs for the validator or follows consensus as a replica
+
 	core         istanbulCore.Engine
 	logger       log.Logger
 	db           ethdb.Database
@@ -169,11 +233,29 @@ type Backend struct {
 	recentMessages *lru.ARCCache // the cache of peer's messages
 	knownMessages  *lru.ARCCache // the cache of self messages
 
-	lastAnnounceGossiped   map[common.Address]*AnnounceGossipTimestamp
+	lastAnnounceGossiped   map[announceGossipKey]*AnnounceGossipTimestamp
 	lastAnnounceGossipedMu sync.RWMutex
 
+	lastAnnounceRequest   map[common.Address]uint64 // timestamp of the latest announce request handled from each validator
+	lastAnnounceRequestMu sync.Mutex
+
+	originLimiter        *rateLimiter // rate limit of the announce messages received from each validator
+	requestOriginLimiter *rateLimiter // rate limit of the announce request messages received from each validator
+	fwdLimiter           *rateLimiter // rate limit of the forward messages received from each peer but the proxied validator
+	announceLimiter      *rateLimiter // rate limit of the announce messages regossiped for each validator
+
+	legacyAnnounceLimiter *rateLimiter // rate limit of the istanbul64 announce messages regossiped for each validator
+
+	announceRequestLimiter *rateLimiter // rate limit of the announce messages sent upon request of each validator
+
 	valEnodeTable *enodes.ValidatorEnodeDB
 
+	// Validator connections set up before, and kept after, an epoch switch
+	valPeersMu        sync.Mutex
+	nextValPeers      map[common.Address]bool      // validators elected for the next epoch, connected ahead of the switch
+	nextValPeersEpoch uint64                       // epoch whose next validators were last predicted, 0 if none
+	leavingValPeers   map[enode.ID]*leavingValPeer // peers of validators that left, dropped after a grace period
+
 	announceWg   *sync.WaitGroup
 	announceQuit chan struct{}
 
@@ -199,6 +281,11 @@ func (sb *Backend) IsProxiedValidator() bool {
 	return sb.proxyNode != nil
 }
 
+// IsProxyConnected returns whether this proxied validator is currently connected to its proxy
+func (sb *Backend) IsProxyConnected() bool {
+	return sb.proxyNode != nil && sb.proxyNode.peer != nil
+}
+
 // SendDelegateSignMsgToProxy sends an istanbulDelegateSign message to a proxy
 // if one exists
 func (sb *Backend) SendDelegateSignMsgToProxy(msg []byte) error {
@@ -224,15 +311,115 @@ func (sb *Backend) SendDelegateSignMsgToProxiedValidator(msg []byte) error {
 // Authorize implements istanbul.Backend.Authorize
 func (sb *Backend) Authorize(address common.Address, signFn istanbul.SignerFn, signHashBLSFn istanbul.SignerFn, signMessageBLSFn istanbul.MessageSignerFn) {
 	sb.signFnMu.Lock()
-	defer sb.signFnMu.Unlock()
-
 	sb.address = address
 	sb.signFn = signFn
 	sb.signHashBLSFn = signHashBLSFn
 	sb.signMessageBLSFn = signMessageBLSFn
+	sb.signFnMu.Unlock()
+
+	// The core may be signing with the signer functions while it switches
 	sb.core.SetAddress(address)
 }
 
+// AuthorizeNext loads the signing key the validator rotates to. The current key
+// keeps being used until the validator set of an epoch contains the new signer,
+// i.e. until the Validators contract registered it and the election picked it
+// up, at which point the backend switches to it at the epoch boundary.
+//
+// The BLS functions have to belong to the new signer's account as well, since
+// the validator registers the BLS key derived from its signer: the BLS key
+// rotates along with the signer, whatever the BLSbase is.
+//
+// The pending rotation is persisted, and SignerRotation reports it so that it
+// can be restored after a restart.
+func (sb *Backend) AuthorizeNext(address common.Address, signFn istanbul.SignerFn, signHashBLSFn istanbul.SignerFn, signMessageBLSFn istanbul.MessageSignerFn) {
+	sb.signFnMu.Lock()
+	defer sb.signFnMu.Unlock()
+
+	rotation := readSignerRotation(sb.db)
+	if address == sb.address {
+		sb.nextSigner = nil
+		rotation.Next = common.Address{}
+		writeSignerRotation(sb.db, rotation)
+		return
+	}
+	sb.nextSigner = &signer{
+		address:          address,
+		signFn:           signFn,
+		signHashBLSFn:    signHashBLSFn,
+		signMessageBLSFn: signMessageBLSFn,
+	}
+	rotation.Next = address
+	writeSignerRotation(sb.db, rotation)
+	sb.logger.Info("Loaded next validator signing key", "current", sb.address, "next", address)
+}
+
+// NextSigner returns the address of the signing key the validator rotates to,
+// if there is a pending rotation.
+func (sb *Backend) NextSigner() (common.Address, bool) {
+	sb.signFnMu.RLock()
+	defer sb.signFnMu.RUnlock()
+
+	if sb.nextSigner == nil {
+		return common.Address{}, false
+	}
+	return sb.nextSigner.address, true
+}
+
+// SignerRotation returns the persisted signer rotation: the signing key the
+// validator last rotated to and the one it's rotating to, zero if none.
+func (sb *Backend) SignerRotation() (signer common.Address, next common.Address) {
+	rotation := readSignerRotation(sb.db)
+	return rotation.Signer, rotation.Next
+}
+
+// SubscribeSignerRotation sets the callback invoked with the new signer after
+// the validator switched to it, e.g. to update the etherbase.
+func (sb *Backend) SubscribeSignerRotation(fn func(signer common.Address)) {
+	sb.signFnMu.Lock()
+	defer sb.signFnMu.Unlock()
+
+	sb.onRotate = fn
+}
+
+// rotateSigner switches to the next signing key if it's part of the validator
+// set that signs off on the block following the given one.
+func (sb *Backend) rotateSigner(head *types.Block) {
+	sb.signFnMu.RLock()
+	next := sb.nextSigner
+	sb.signFnMu.RUnlock()
+
+	if next == nil || head == nil {
+		return
+	}
+	valset := sb.getValidators(head.Number().Uint64(), head.Hash())
+	if _, val := valset.GetByAddress(next.address); val == nil {
+		return
+	}
+	sb.signFnMu.Lock()
+	if sb.nextSigner != next {
+		// The pending rotation changed in the meantime
+		sb.signFnMu.Unlock()
+		return
+	}
+	old := sb.address
+	sb.address = next.address
+	sb.signFn = next.signFn
+	sb.signHashBLSFn = next.signHashBLSFn
+	sb.signMessageBLSFn = next.signMessageBLSFn
+	sb.nextSigner = nil
+	writeSignerRotation(sb.db, signerRotation{Signer: next.address})
+	onRotate := sb.onRotate
+	sb.signFnMu.Unlock()
+
+	// The core switches in between two of its events
+	sb.core.SetAddress(next.address)
+	if onRotate != nil {
+		onRotate(next.address)
+	}
+	sb.logger.Info("Rotated validator signing key", "number", head.Number().Uint64()+1, "old", old, "new", next.address)
+}
+
 // Address implements istanbul.Backend.Address
 func (sb *Backend) Address() common.Address {
 	return sb.address
@@ -305,6 +492,16 @@ func (sb *Backend) BroadcastConsensusMsg(destAddresses []common.Address, payload
 
 // Gossip implements istanbul.Backend.Gossip
 func (sb *Backend) Gossip(destAddresses []common.Address, payload []byte, ethMsgCode uint64, ignoreCache bool) error {
+	// Announce requests are only known to the peers exchanging certified announces
+	if ethMsgCode == istanbulAnnounceRequestMsg {
+		return sb.gossip(destAddresses, payload, ethMsgCode, ignoreCache, hasCertifiedAnnounces)
+	}
+	return sb.gossip(destAddresses, payload, ethMsgCode, ignoreCache, nil)
+}
+
+// gossip sends the message to the peers of the given addresses, or to all peers
+// if nil, skipping the peers whose protocol version isn't accepted.
+func (sb *Backend) gossip(destAddresses []common.Address, payload []byte, ethMsgCode uint64, ignoreCache bool, acceptVersion func(version int) bool) error {
 	// If this is a proxied validator and it wants to send a consensus message,
 	// wrap the consensus message in a forward message.
 	if sb.config.Proxied && ethMsgCode == istanbulConsensusMsg {
@@ -337,6 +534,9 @@ func (sb *Backend) Gossip(destAddresses []common.Address, payload []byte, ethMsg
 
 	if len(peers) > 0 {
 		for addr, p := range peers {
+			if acceptVersion != nil && !acceptVersion(p.Version()) {
+				continue
+			}
 			if !ignoreCache {
 				ms, ok := sb.recentMessages.Get(addr)
 				var m *lru.ARCCache
@@ -700,6 +900,7 @@ func (sb *Backend) RefreshValPeers(valset istanbul.ValidatorSet) {
 		return
 	}
 
+	sb.resetNextValidators()
 	sb.valEnodeTable.RefreshValPeers(valset, sb.Address())
 }
 
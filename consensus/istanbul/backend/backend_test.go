@@ -187,6 +187,53 @@ func TestGetProposer(t *testing.T) {
 	}
 }
 
+func TestRotateSigner(t *testing.T) {
+	chain, engine := newBlockChain(4, true)
+	current := engine.Address()
+	var rotated common.Address
+	engine.SubscribeSignerRotation(func(signer common.Address) { rotated = signer })
+
+	// A signer that isn't elected must not replace the current one
+	engine.AuthorizeNext(getInvalidAddress(), signerFnInvalid, signerBLSHashFn, signerBLSMessageFn)
+	engine.rotateSigner(chain.Genesis())
+	if engine.Address() != current {
+		t.Fatalf("address mismatch: have %v, want %v", engine.Address().Hex(), current.Hex())
+	}
+	if next, ok := engine.NextSigner(); !ok || next != getInvalidAddress() {
+		t.Fatalf("next signer mismatch: have %v, want %v", next.Hex(), getInvalidAddress().Hex())
+	}
+	if signer, next := engine.SignerRotation(); signer != (common.Address{}) || next != getInvalidAddress() {
+		t.Fatalf("persisted rotation mismatch: have %v -> %v, want none -> %v", signer.Hex(), next.Hex(), getInvalidAddress().Hex())
+	}
+
+	// The signer is switched once it's part of the validator set
+	engine.AuthorizeNext(getAddress(), signerFn, signerBLSHashFn, signerBLSMessageFn)
+	engine.rotateSigner(chain.Genesis())
+	if engine.Address() != getAddress() {
+		t.Fatalf("address mismatch: have %v, want %v", engine.Address().Hex(), getAddress().Hex())
+	}
+	if _, ok := engine.NextSigner(); ok {
+		t.Fatalf("next signer not cleared after rotation")
+	}
+	if rotated != getAddress() {
+		t.Errorf("rotation callback mismatch: have %v, want %v", rotated.Hex(), getAddress().Hex())
+	}
+	// A restarted node must pick up the rotated signer
+	config := *istanbul.DefaultConfig
+	config.ValidatorEnodeDBPath = ""
+	if signer, next := New(&config, engine.db).(*Backend).SignerRotation(); signer != getAddress() || next != (common.Address{}) {
+		t.Errorf("persisted rotation mismatch: have %v -> %v, want %v -> none", signer.Hex(), next.Hex(), getAddress().Hex())
+	}
+	data := []byte("Here is a string....")
+	sig, err := engine.Sign(data)
+	if err != nil {
+		t.Fatalf("error mismatch: have %v, want nil", err)
+	}
+	if err := engine.CheckSignature(data, getAddress(), sig); err != nil {
+		t.Errorf("signature mismatch after rotation: %v", err)
+	}
+}
+
 /**
  * SimpleBackend
  * Private key: bb047e5940b6d83354d9432db7c449ac8fca2248008aaa7271369880f9f11cc1
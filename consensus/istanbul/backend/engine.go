@@ -58,6 +58,18 @@ var (
 	// errUnknownBlock is returned when the list of validators or header is requested for a block
 	// that is not part of the local blockchain.
 	errUnknownBlock = errors.New("unknown block")
+	// errUnknownTimeline is returned when the round timeline is requested for a sequence
+	// that has not been recorded or has already been evicted.
+	errUnknownTimeline = errors.New("no round timeline for sequence")
+	// errNoHeadState is returned if the state of the head block is required but
+	// the chain doesn't provide it, as on light clients.
+	errNoHeadState = errors.New("head state not available")
+	// errInvalidVoteChange is returned if a hypothetical vote change doesn't
+	// change the votes of a group by a positive value.
+	errInvalidVoteChange = errors.New("vote change must have a positive value")
+	// errNoVoter is returned if a hypothetical vote revocation doesn't name the
+	// voter revoking the votes.
+	errNoVoter = errors.New("vote revocation must have a voter")
 	// errUnauthorized is returned if a header is signed by a non authorized entity.
 	errUnauthorized = errors.New("unauthorized")
 	// errInvalidDifficulty is returned if the difficulty of a block is not 1
@@ -88,6 +100,9 @@ var (
 	// errUnauthorizedAnnounceMessage is returned when the received announce message is from
 	// an unregistered validator
 	errUnauthorizedAnnounceMessage = errors.New("unauthorized announce message")
+	// errUnsupportedAnnounceVersion is returned when the received announce or announce
+	// request message uses an announce protocol version this node doesn't support
+	errUnsupportedAnnounceVersion = errors.New("unsupported announce version")
 	// errUnauthorizedValEnodesShareMessage is returned when the received valEnodeshare message is from
 	// an unauthorized sender
 	errUnauthorizedValEnodesShareMessage = errors.New("unauthorized valenodesshare message")
@@ -527,6 +542,10 @@ func (sb *Backend) Seal(chain consensus.ChainReader, block *types.Block, results
 	if _, v := snap.ValSet.GetByAddress(sb.address); v == nil {
 		return errUnauthorized
 	}
+	// Replicas follow consensus without proposing blocks
+	if !sb.IsPrimaryForSeq(header.Number) {
+		return nil
+	}
 
 	parent := chain.GetHeader(header.ParentHash, number-1)
 	if parent == nil {
@@ -612,6 +631,7 @@ func (sb *Backend) APIs(chain consensus.ChainReader) []rpc.API {
 func (sb *Backend) SetChain(chain consensus.ChainReader, currentBlock func() *types.Block) {
 	sb.chain = chain
 	sb.currentBlock = currentBlock
+	sb.migrateSnapshots(chain)
 }
 
 // Start implements consensus.Istanbul.Start
@@ -717,6 +737,7 @@ func (sb *Backend) snapshot(chain consensus.ChainReader, number uint64, hash com
 	// 'addedValidators' field in the header.
 
 	// Retrieve the most recent cached or on disk snapshot.
+	head := headNumber(chain, number)
 	for ; ; numberIter = numberIter - sb.config.Epoch {
 		// If an in-memory snapshot was found, use that
 		if s, ok := sb.recentSnapshots.Get(numberIter); ok {
@@ -724,6 +745,16 @@ func (sb *Backend) snapshot(chain consensus.ChainReader, number uint64, hash com
 			break
 		}
 
+		// Skip the epochs whose snapshots were pruned, they are rebuilt from the
+		// closest retained snapshot below.
+		if numberIter != 0 && !sb.retainSnapshot(numberIter, head) {
+			numberIter = sb.lastCheckpointSnapshot(numberIter)
+			if s, ok := sb.recentSnapshots.Get(numberIter); ok {
+				snap = s.(*Snapshot)
+				break
+			}
+		}
+
 		if numberIter == number {
 			blockHash = hash
 		} else {
@@ -820,7 +851,7 @@ func (sb *Backend) snapshot(chain consensus.ChainReader, number uint64, hash com
 	if len(headers) > 0 {
 		var err error
 		log.Trace("Snapshot headers len greater than 0", "headers", headers)
-		snap, err = snap.apply(headers, sb.db)
+		snap, err = snap.apply(headers, func(snap *Snapshot) { sb.storeSnapshot(snap, head) })
 		if err != nil {
 			log.Error("Unable to apply headers to snapshots", "headers", headers)
 			return nil, err
@@ -839,6 +870,103 @@ func (sb *Backend) snapshot(chain consensus.ChainReader, number uint64, hash com
 	return returnSnap, nil
 }
 
+// headNumber returns the number of the current head of the chain, or the given
+// block number if the chain has no head yet or the block is ahead of it, as is
+// the case for blocks that are being verified for import.
+func headNumber(chain consensus.ChainReader, number uint64) uint64 {
+	if header := chain.CurrentHeader(); header != nil && header.Number.Uint64() > number {
+		return header.Number.Uint64()
+	}
+	return number
+}
+
+// retainSnapshot returns whether the snapshot at the given epoch block is kept
+// on disk, given the current head of the chain.
+func (sb *Backend) retainSnapshot(number uint64, head uint64) bool {
+	if sb.config.SnapshotRetention == 0 {
+		return true
+	}
+	if interval := sb.config.SnapshotCheckpointInterval; interval != 0 && (number/sb.config.Epoch)%interval == 0 {
+		return true
+	}
+	return number+sb.config.SnapshotRetention*sb.config.Epoch > head
+}
+
+// lastCheckpointSnapshot returns the block number of the most recent checkpoint
+// snapshot at or before the given epoch block.
+func (sb *Backend) lastCheckpointSnapshot(number uint64) uint64 {
+	interval := sb.config.SnapshotCheckpointInterval
+	if interval == 0 {
+		return 0
+	}
+	return (number / sb.config.Epoch / interval) * interval * sb.config.Epoch
+}
+
+// storeSnapshot persists the snapshot if it falls within the retention policy,
+// and prunes the snapshot that dropped out of the retention window with it.
+func (sb *Backend) storeSnapshot(snap *Snapshot, head uint64) {
+	if !sb.retainSnapshot(snap.Number, head) {
+		return
+	}
+	if err := snap.store(sb.db); err != nil {
+		log.Error("Failed to store validator set snapshot", "number", snap.Number, "hash", snap.Hash, "err", err)
+		return
+	}
+	log.Trace("Stored validator set snapshot to disk", "number", snap.Number, "hash", snap.Hash)
+
+	if window := sb.config.SnapshotRetention * sb.config.Epoch; window != 0 && snap.Number > window {
+		if stale := snap.Number - window; !sb.retainSnapshot(stale, head) {
+			if err := deleteSnapshot(sb.db, stale); err != nil {
+				log.Error("Failed to prune validator set snapshot", "number", stale, "err", err)
+			}
+		}
+	}
+}
+
+// migrateSnapshots converts the snapshots stored in the legacy JSON format to
+// the RLP format and prunes the ones outside of the retention policy. It walks
+// the epoch headers of the canonical chain once, and records the new storage
+// version when done.
+func (sb *Backend) migrateSnapshots(chain consensus.ChainReader) {
+	if readSnapshotVersion(sb.db) >= snapshotVersion {
+		return
+	}
+	// Without a head there is nothing to migrate yet
+	if chain.CurrentHeader() == nil {
+		return
+	}
+	var (
+		start          = time.Now()
+		head           = chain.CurrentHeader().Number.Uint64()
+		pruned, stored int
+	)
+	for number := uint64(0); number <= head; number += sb.config.Epoch {
+		header := chain.GetHeaderByNumber(number)
+		if header == nil {
+			break
+		}
+		if has, _ := sb.db.Has(snapshotKey(header.Hash())); !has {
+			continue
+		}
+		if !sb.retainSnapshot(number, head) {
+			sb.db.Delete(snapshotKey(header.Hash()))
+			pruned++
+			continue
+		}
+		// Loading the snapshot converts it to the RLP format
+		if _, err := loadSnapshot(sb.config.Epoch, sb.db, header.Hash()); err != nil {
+			log.Warn("Failed to migrate validator set snapshot", "number", number, "err", err)
+			continue
+		}
+		stored++
+	}
+	if err := writeSnapshotVersion(sb.db, snapshotVersion); err != nil {
+		log.Error("Failed to store validator set snapshot version", "err", err)
+		return
+	}
+	log.Info("Migrated validator set snapshots", "retained", stored, "pruned", pruned, "elapsed", common.PrettyDuration(time.Since(start)))
+}
+
 // FIXME: Need to update this for Istanbul
 // sigHash returns the hash which is used as input for the Istanbul
 // signing. It is the hash of the entire header apart from the 65 byte signature
@@ -19,6 +19,7 @@ package backend
 import (
 	"errors"
 	"reflect"
+	"time"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/consensus"
@@ -29,13 +30,21 @@ import (
 	lru "github.com/hashicorp/golang-lru"
 )
 
+// Istanbul protocol versions. Since istanbul65, announce messages carry an enode
+// certificate, and validators can request fresh announce messages.
+const (
+	istanbul64 = 64
+	istanbul65 = 65
+)
+
 // If you want to add a code, you need to increment the Lengths Array size!
 const (
-	istanbulConsensusMsg      = 0x11
-	istanbulAnnounceMsg       = 0x12
-	istanbulValEnodesShareMsg = 0x13
-	istanbulFwdMsg            = 0x14
-	istanbulDelegateSign      = 0x15
+	istanbulConsensusMsg       = 0x11
+	istanbulAnnounceMsg        = 0x12
+	istanbulValEnodesShareMsg  = 0x13
+	istanbulFwdMsg             = 0x14
+	istanbulDelegateSign       = 0x15
+	istanbulAnnounceRequestMsg = 0x16
 )
 
 var (
@@ -47,14 +56,20 @@ var (
 func (sb *Backend) Protocol() consensus.Protocol {
 	return consensus.Protocol{
 		Name:     "istanbul",
-		Versions: []uint{64},
-		Lengths:  []uint64{22},
+		Versions: []uint{istanbul65, istanbul64},
+		Lengths:  []uint64{23, 22},
 		Primary:  true,
 	}
 }
 
+// hasCertifiedAnnounces returns whether peers of the given protocol version
+// exchange announce messages with an enode certificate.
+func hasCertifiedAnnounces(version int) bool {
+	return version >= istanbul65
+}
+
 func (sb *Backend) isIstanbulMsg(msg p2p.Msg) bool {
-	return (msg.Code == istanbulConsensusMsg) || (msg.Code == istanbulAnnounceMsg) || (msg.Code == istanbulValEnodesShareMsg) || (msg.Code == istanbulFwdMsg) || (msg.Code == istanbulDelegateSign)
+	return (msg.Code == istanbulConsensusMsg) || (msg.Code == istanbulAnnounceMsg) || (msg.Code == istanbulValEnodesShareMsg) || (msg.Code == istanbulFwdMsg) || (msg.Code == istanbulDelegateSign) || (msg.Code == istanbulAnnounceRequestMsg)
 }
 
 // HandleMsg implements consensus.Handler.HandleMsg
@@ -69,6 +84,15 @@ func (sb *Backend) HandleMsg(addr common.Address, msg p2p.Msg, peer consensus.Pe
 			return true, istanbul.ErrStoppedEngine
 		}
 
+		// Limit the rate of the forward messages, except for the ones of the
+		// proxied validator carrying its consensus messages. Announce and announce
+		// request messages are limited by their origin once their signature is checked.
+		if msg.Code == istanbulFwdMsg && !reflect.DeepEqual(peer, sb.proxiedPeer) && !sb.fwdLimiter.allow(addr, time.Now()) {
+			sb.logger.Debug("Dropping forward message exceeding the peer rate limit", "address", addr)
+			droppedFwdMeter.Mark(1)
+			return true, nil
+		}
+
 		var data []byte
 		if err := msg.Decode(&data); err != nil {
 			sb.logger.Error("Failed to decode message payload", "msg", msg)
@@ -111,6 +135,8 @@ func (sb *Backend) HandleMsg(addr common.Address, msg p2p.Msg, peer consensus.Pe
 			return true, err
 		} else if msg.Code == istanbulAnnounceMsg {
 			go sb.handleIstAnnounce(data)
+		} else if msg.Code == istanbulAnnounceRequestMsg {
+			go sb.handleAnnounceRequest(data)
 		} else if msg.Code == istanbulValEnodesShareMsg {
 			go sb.handleValEnodesShareMsg(data)
 		}
@@ -204,20 +230,31 @@ func (sb *Backend) NewWork() error {
 	if !sb.coreStarted {
 		return istanbul.ErrStoppedEngine
 	}
+	// Switch to the next signing key before the new sequence starts, if it
+	// signs off on the next block.
+	if sb.currentBlock != nil {
+		sb.rotateSigner(sb.currentBlock())
+	}
 
 	go sb.istanbulEventMux.Post(istanbul.FinalCommittedEvent{})
 	return nil
 }
 
 // This function is called by all nodes.
+// Shortly before and after the end of each epoch, it will connect to the validators
+// elected for the next epoch, and drop the validators that left.
 // At the end of each epoch, this function will
-//    1)  Output if it is or isn't an elected validator if it has mining turned on.
-//    2)  Refresh the validator connections if it's a proxy or non proxied validator
+//    1)  Switch to the validator's next signing key, if it was elected.
+//    2)  Output if it is or isn't an elected validator if it has mining turned on.
+//    3)  Refresh the validator connections if it's a proxy or non proxied validator
 func (sb *Backend) NewChainHead(newBlock *types.Block) {
+	sb.updateValPeers(newBlock)
+
 	if istanbul.IsLastBlockOfEpoch(newBlock.Number().Uint64(), sb.config.Epoch) {
 		sb.coreMu.RLock()
 		defer sb.coreMu.RUnlock()
 
+		sb.rotateSigner(newBlock)
 		valset := sb.getValidators(newBlock.Number().Uint64(), newBlock.Hash())
 
 		// Output whether this validator was or wasn't elected for the
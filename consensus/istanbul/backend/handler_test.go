@@ -37,6 +37,10 @@ func (p *MockPeer) Node() *enode.Node {
 	return nil
 }
 
+func (p *MockPeer) Version() int {
+	return istanbul65
+}
+
 func TestIstanbulMessage(t *testing.T) {
 	_, backend := newBlockChain(1, true)
 
@@ -77,6 +81,38 @@ func TestIstanbulMessage(t *testing.T) {
 	}
 }
 
+// namedPeer is a peer distinguishable from the other mock peers.
+type namedPeer struct {
+	MockPeer
+	name string
+}
+
+func TestFwdMsgRateLimit(t *testing.T) {
+	_, backend := newBlockChain(1, true)
+	backend.fwdLimiter = newRateLimiter(0.001, 1)
+
+	proxied, other := &namedPeer{name: "proxied"}, &namedPeer{name: "other"}
+	backend.proxiedPeer = proxied
+	addr := common.BytesToAddress([]byte("address"))
+
+	// The forward messages of the proxied validator aren't rate limited
+	for i := 0; i < 3; i++ {
+		data := []byte{byte(i)}
+		backend.HandleMsg(addr, makeMsg(istanbulFwdMsg, data), proxied)
+		if _, ok := backend.knownMessages.Get(istanbul.RLPHash(data)); !ok {
+			t.Fatalf("forward message %d of the proxied validator dropped", i)
+		}
+	}
+	// The ones of other peers are
+	for i := 3; i < 5; i++ {
+		data := []byte{byte(i)}
+		backend.HandleMsg(addr, makeMsg(istanbulFwdMsg, data), other)
+		if _, ok := backend.knownMessages.Get(istanbul.RLPHash(data)); ok != (i == 3) {
+			t.Fatalf("forward message %d rate limit mismatch: have handled %v, want %v", i, ok, i == 3)
+		}
+	}
+}
+
 func makeMsg(msgcode uint64, data interface{}) p2p.Msg {
 	size, r, _ := rlp.EncodeToReader(data)
 	return p2p.Msg{Code: msgcode, Size: uint32(size), Payload: r}
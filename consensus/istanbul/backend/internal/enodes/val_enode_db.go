@@ -48,7 +48,7 @@ const (
 const (
 	// dbNodeExpiration = 24 * time.Hour // Time after which an unseen node should be dropped.
 	// dbCleanupCycle   = time.Hour      // Time period for running the expiration task.
-	dbVersion = 1
+	dbVersion = 2
 )
 
 // ValidatorEnodeHandler is handler to Add/Remove events. Events execute within write lock
@@ -76,23 +76,43 @@ func nodeIDKey(nodeID enode.ID) []byte {
 
 // Entries for the valEnodeTable
 type AddressEntry struct {
-	Node *enode.Node
-	View *istanbul.View
+	Node        *enode.Node
+	View        *istanbul.View
+	Certificate *istanbul.EnodeCertificate
 }
 
 func (ve *AddressEntry) String() string {
 	return fmt.Sprintf("{enodeURL: %v, view: %v}", ve.Node.String(), ve.View)
 }
 
+// Timestamp returns the time at which the entry's enode certificate was issued,
+// or zero if the entry has no certificate.
+func (ve *AddressEntry) Timestamp() uint64 {
+	if ve.Certificate == nil {
+		return 0
+	}
+	return ve.Certificate.Timestamp
+}
+
+// IsNewerThan returns whether the entry is newer than the given one, ordering
+// entries by their certificate timestamp first, and by their view otherwise.
+func (ve *AddressEntry) IsNewerThan(other *AddressEntry) bool {
+	if ts, otherTs := ve.Timestamp(), other.Timestamp(); ts != otherTs {
+		return ts > otherTs
+	}
+	return ve.View.Cmp(other.View) > 0
+}
+
 // Implement RLP Encode/Decode interface
 type rlpEntry struct {
-	EnodeURL string
-	View     *istanbul.View
+	EnodeURL    string
+	View        *istanbul.View
+	Certificate *istanbul.EnodeCertificate `rlp:"nil"`
 }
 
 // EncodeRLP serializes AddressEntry into the Ethereum RLP format.
 func (ve *AddressEntry) EncodeRLP(w io.Writer) error {
-	return rlp.Encode(w, rlpEntry{ve.Node.String(), ve.View})
+	return rlp.Encode(w, rlpEntry{ve.Node.String(), ve.View, ve.Certificate})
 }
 
 // DecodeRLP implements rlp.Decoder, and load the AddressEntry fields from a RLP stream.
@@ -107,7 +127,7 @@ func (ve *AddressEntry) DecodeRLP(s *rlp.Stream) error {
 		return err
 	}
 
-	*ve = AddressEntry{Node: node, View: entry.View}
+	*ve = AddressEntry{Node: node, View: entry.View, Certificate: entry.Certificate}
 	return nil
 }
 
@@ -233,6 +253,13 @@ func (vet *ValidatorEnodeDB) GetViewFromAddress(address common.Address) (*istanb
 	return entry.View, nil
 }
 
+// GetEntryFromAddress will return the entry for an address if it's known
+func (vet *ValidatorEnodeDB) GetEntryFromAddress(address common.Address) (*AddressEntry, error) {
+	vet.lock.RLock()
+	defer vet.lock.RUnlock()
+	return vet.getAddressEntry(address)
+}
+
 // GetAddressFromNodeID will return the address for an nodeID if it's known
 func (vet *ValidatorEnodeDB) GetAddressFromNodeID(nodeID enode.ID) (common.Address, error) {
 	vet.lock.RLock()
@@ -265,7 +292,7 @@ func (vet *ValidatorEnodeDB) GetAllValEnodes() (map[common.Address]*AddressEntry
 }
 
 // Upsert will update or insert a validator enode entry; given that the existing entry
-// is older (determined by view parameter) that the new one
+// is older (determined by certificate timestamp and view) than the new one
 func (vet *ValidatorEnodeDB) Upsert(valEnodeEntries map[common.Address]*AddressEntry) error {
 	vet.lock.Lock()
 	defer vet.lock.Unlock()
@@ -290,8 +317,8 @@ func (vet *ValidatorEnodeDB) Upsert(valEnodeEntries map[common.Address]*AddressE
 		}
 
 		// If it's an old message, ignore it
-		if !isNew && addressEntry.View.Cmp(currentEntry.View) <= 0 {
-			vet.logger.Trace("Ignoring the entry because it's view is older than what is stored in the val enode db",
+		if !isNew && !addressEntry.IsNewerThan(currentEntry) {
+			vet.logger.Trace("Ignoring the entry because it's older than what is stored in the val enode db",
 				"entryAddress", remoteAddress, "entryEnodeURL", addressEntry.Node.String(), "addressView", addressEntry.View)
 			continue
 		}
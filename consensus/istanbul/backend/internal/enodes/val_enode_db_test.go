@@ -142,6 +142,37 @@ func TestRLPEntries(t *testing.T) {
 	}
 }
 
+func TestUpsertOrdering(t *testing.T) {
+	vet, err := OpenValidatorEnodeDB("", &mockListener{})
+	if err != nil {
+		t.Fatal("Failed to open DB")
+	}
+
+	// Entries are ordered by their certificate timestamp, then by view
+	upserts := []struct {
+		entry *AddressEntry
+		node  *enode.Node
+	}{
+		{&AddressEntry{Node: nodeA, View: view(0, 2), Certificate: &istanbul.EnodeCertificate{Timestamp: 10}}, nodeA},
+		{&AddressEntry{Node: nodeB, View: view(0, 3), Certificate: &istanbul.EnodeCertificate{Timestamp: 9}}, nodeA},
+		{&AddressEntry{Node: nodeB, View: view(0, 1), Certificate: &istanbul.EnodeCertificate{Timestamp: 11}}, nodeB},
+		{&AddressEntry{Node: nodeA, View: view(0, 1), Certificate: &istanbul.EnodeCertificate{Timestamp: 11}}, nodeB},
+		{&AddressEntry{Node: nodeA, View: view(0, 2), Certificate: &istanbul.EnodeCertificate{Timestamp: 11}}, nodeA},
+	}
+	for i, upsert := range upserts {
+		if err := vet.Upsert(map[common.Address]*AddressEntry{addressA: upsert.entry}); err != nil {
+			t.Fatalf("upsert %d: failed to upsert: %v", i, err)
+		}
+		node, err := vet.GetNodeFromAddress(addressA)
+		if err != nil {
+			t.Fatalf("upsert %d: failed to get node: %v", i, err)
+		}
+		if node.String() != upsert.node.String() {
+			t.Errorf("upsert %d: node mismatch: have %s, want %s", i, node.String(), upsert.node.String())
+		}
+	}
+}
+
 func TestTableToString(t *testing.T) {
 	vet, err := OpenValidatorEnodeDB("", &mockListener{})
 	if err != nil {
@@ -38,11 +38,12 @@ func (vph *validatorPeerHandler) AddValidatorPeer(node *enode.Node, address comm
 	}
 
 	// Connect to the remote peer if it's part of the current epoch's valset and
-	// if this node is also part of the current epoch's valset
+	// if this node is also part of the current epoch's valset, or if both were
+	// elected for the next epoch which is about to start
 
 	block := vph.sb.currentBlock()
 	valSet := vph.sb.getValidators(block.Number().Uint64(), block.Hash())
-	if valSet.ContainsByAddress(address) && valSet.ContainsByAddress(vph.sb.ValidatorAddress()) {
+	if (valSet.ContainsByAddress(address) && valSet.ContainsByAddress(vph.sb.ValidatorAddress())) || vph.sb.isNextValidator(address) {
 		vph.sb.p2pserver.AddPeer(node, p2p.ValidatorPurpose)
 		vph.sb.p2pserver.AddTrustedPeer(node, p2p.ValidatorPurpose)
 	}
@@ -59,10 +60,10 @@ func (vph *validatorPeerHandler) ReplaceValidatorPeers(newNodes []*enode.Node) {
 		nodeIDSet[node.ID()] = true
 	}
 
-	// Remove old Validator Peers
-	for existingPeerID, existingPeer := range vph.sb.broadcaster.FindPeers(nodeIDSet, p2p.ValidatorPurpose) {
+	// Remove old Validator Peers, once their grace period is over
+	for existingPeerID, existingPeer := range vph.sb.broadcaster.FindPeers(nil, p2p.ValidatorPurpose) {
 		if !nodeIDSet[existingPeerID] {
-			vph.RemoveValidatorPeer(existingPeer.Node())
+			vph.sb.dropValidatorPeer(existingPeer.Node())
 		}
 	}
 
@@ -0,0 +1,195 @@
+// Copyright 2017 The celo Authors
+// This file is part of the celo library.
+//
+// The celo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The celo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the celo library. If not, see <http://www.gnu.org/licenses/>.
+
+package backend
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/istanbul"
+	"github.com/ethereum/go-ethereum/contract_comm/election"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/p2p"
+	"github.com/ethereum/go-ethereum/p2p/enode"
+)
+
+// leavingValPeer is a peer of a validator that left the validator set, kept
+// connected for a few blocks after the epoch switch.
+type leavingValPeer struct {
+	node  *enode.Node
+	until uint64 // Block number from which the peer is dropped
+}
+
+// updateValPeers manages the validator connections around an epoch switch.
+// Within ValidatorPreconnectBlocks blocks before the switch, it connects to the
+// validators predicted to be elected for the next epoch, so that consensus
+// doesn't wait for connections to come up in the first blocks of the epoch. The
+// validators that left are dropped once as many blocks passed after the switch.
+func (sb *Backend) updateValPeers(head *types.Block) {
+	window := sb.config.ValidatorPreconnectBlocks
+	if window == 0 || sb.p2pserver == nil {
+		return
+	}
+	number := head.NumberU64()
+	sb.dropLeavingValPeers(head)
+
+	if left := sb.config.Epoch - istanbul.GetNumberWithinEpoch(number, sb.config.Epoch); left == 0 || left > window {
+		return
+	}
+	if !(&validatorPeerHandler{sb: sb}).MaintainValConnections() {
+		return
+	}
+
+	// The election is run once per window, against the first head within it, and
+	// outside of the chain head handling since it is a full EVM call
+	epoch := istanbul.GetEpochNumber(number, sb.config.Epoch)
+	sb.valPeersMu.Lock()
+	predicted := sb.nextValPeersEpoch == epoch
+	sb.nextValPeersEpoch = epoch
+	sb.valPeersMu.Unlock()
+	if predicted {
+		return
+	}
+
+	go func() {
+		next, err := sb.predictNextValidators(head)
+		if err != nil {
+			sb.logger.Warn("Failed to predict the next epoch's validators", "number", number, "err", err)
+
+			// Retry on the next head within the window
+			sb.valPeersMu.Lock()
+			if sb.nextValPeersEpoch == epoch {
+				sb.nextValPeersEpoch = 0
+			}
+			sb.valPeersMu.Unlock()
+			return
+		}
+		sb.connectNextValidators(next, sb.getValidators(number, head.Hash()))
+	}()
+}
+
+// predictNextValidators runs the validator election against the state of the given head.
+func (sb *Backend) predictNextValidators(head *types.Block) ([]common.Address, error) {
+	chain, ok := sb.chain.(interface {
+		StateAt(root common.Hash) (*state.StateDB, error)
+	})
+	if !ok {
+		return nil, errNoHeadState
+	}
+	statedb, err := chain.StateAt(head.Root())
+	if err != nil {
+		return nil, err
+	}
+	return election.GetElectedValidators(head.Header(), statedb)
+}
+
+// connectNextValidators connects to the given validators of the next epoch, if
+// this validator is among them, as trusted peers outside the MaxPeers limit.
+// Validators connected ahead of the switch that aren't elected anymore, nor part
+// of the current validator set, are disconnected.
+func (sb *Backend) connectNextValidators(next []common.Address, current istanbul.ValidatorSet) {
+	nextSet := make(map[common.Address]bool)
+	for _, address := range next {
+		nextSet[address] = true
+	}
+	if !nextSet[sb.ValidatorAddress()] {
+		nextSet = make(map[common.Address]bool)
+	}
+
+	sb.valPeersMu.Lock()
+	prevSet := sb.nextValPeers
+	sb.nextValPeers = nextSet
+	sb.valPeersMu.Unlock()
+
+	for address := range prevSet {
+		if nextSet[address] || current.ContainsByAddress(address) {
+			continue
+		}
+		if node, err := sb.valEnodeTable.GetNodeFromAddress(address); err == nil {
+			sb.logger.Debug("Disconnecting from a validator not elected anymore", "address", address)
+			sb.p2pserver.RemovePeer(node, p2p.ValidatorPurpose)
+			sb.p2pserver.RemoveTrustedPeer(node, p2p.ValidatorPurpose)
+		}
+	}
+	for address := range nextSet {
+		if address == sb.ValidatorAddress() || current.ContainsByAddress(address) {
+			continue
+		}
+		if node, err := sb.valEnodeTable.GetNodeFromAddress(address); err == nil {
+			sb.logger.Debug("Connecting to a validator elected for the next epoch", "address", address)
+			sb.p2pserver.AddPeer(node, p2p.ValidatorPurpose)
+			sb.p2pserver.AddTrustedPeer(node, p2p.ValidatorPurpose)
+		}
+	}
+}
+
+// isNextValidator returns whether the given validator was connected to ahead of
+// the epoch switch.
+func (sb *Backend) isNextValidator(address common.Address) bool {
+	sb.valPeersMu.Lock()
+	defer sb.valPeersMu.Unlock()
+	return sb.nextValPeers[address]
+}
+
+// resetNextValidators forgets the validators connected to ahead of the epoch
+// switch, once it happened.
+func (sb *Backend) resetNextValidators() {
+	sb.valPeersMu.Lock()
+	defer sb.valPeersMu.Unlock()
+	sb.nextValPeers = nil
+}
+
+// dropValidatorPeer disconnects from the peer of a validator that left the
+// validator set, after a grace period if configured.
+func (sb *Backend) dropValidatorPeer(node *enode.Node) {
+	grace := sb.config.ValidatorPreconnectBlocks
+	if grace == 0 || sb.currentBlock == nil {
+		sb.p2pserver.RemovePeer(node, p2p.ValidatorPurpose)
+		sb.p2pserver.RemoveTrustedPeer(node, p2p.ValidatorPurpose)
+		return
+	}
+	sb.valPeersMu.Lock()
+	defer sb.valPeersMu.Unlock()
+	sb.leavingValPeers[node.ID()] = &leavingValPeer{node: node, until: sb.currentBlock().NumberU64() + grace}
+}
+
+// dropLeavingValPeers disconnects from the peers of the validators that left the
+// validator set, once their grace period is over. Peers of validators that were
+// elected again are kept.
+func (sb *Backend) dropLeavingValPeers(head *types.Block) {
+	var drop []*enode.Node
+	sb.valPeersMu.Lock()
+	for id, peer := range sb.leavingValPeers {
+		if head.NumberU64() >= peer.until {
+			drop = append(drop, peer.node)
+			delete(sb.leavingValPeers, id)
+		}
+	}
+	sb.valPeersMu.Unlock()
+	if len(drop) == 0 {
+		return
+	}
+
+	valSet := sb.getValidators(head.NumberU64(), head.Hash())
+	for _, node := range drop {
+		if address, err := sb.valEnodeTable.GetAddressFromNodeID(node.ID()); err == nil && valSet.ContainsByAddress(address) {
+			continue
+		}
+		sb.logger.Debug("Disconnecting from a validator that left the validator set", "node", node.String())
+		sb.p2pserver.RemovePeer(node, p2p.ValidatorPurpose)
+		sb.p2pserver.RemoveTrustedPeer(node, p2p.ValidatorPurpose)
+	}
+}
@@ -0,0 +1,137 @@
+// Copyright 2017 The celo Authors
+// This file is part of the celo library.
+//
+// The celo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The celo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the celo library. If not, see <http://www.gnu.org/licenses/>.
+
+package backend
+
+import (
+	"math/big"
+	"net"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/consensustest"
+	"github.com/ethereum/go-ethereum/consensus/istanbul"
+	vet "github.com/ethereum/go-ethereum/consensus/istanbul/backend/internal/enodes"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/p2p"
+	"github.com/ethereum/go-ethereum/p2p/enode"
+)
+
+// recordingP2PServer records the validator peers added and removed.
+type recordingP2PServer struct {
+	consensustest.MockP2PServer
+	peers map[enode.ID]bool
+}
+
+func (serv *recordingP2PServer) AddPeer(node *enode.Node, purpose p2p.PurposeFlag) {
+	serv.peers[node.ID()] = true
+}
+
+func (serv *recordingP2PServer) RemovePeer(node *enode.Node, purpose p2p.PurposeFlag) {
+	delete(serv.peers, node.ID())
+}
+
+func newTestValEnode(t *testing.T, b *Backend, address common.Address) *enode.Node {
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	node := enode.NewV4(&key.PublicKey, net.ParseIP("1.2.3.4"), 30303, 30303)
+	b.valEnodeTable.Upsert(map[common.Address]*vet.AddressEntry{address: {
+		Node: node,
+		View: &istanbul.View{Round: big.NewInt(0), Sequence: big.NewInt(0)},
+	}})
+	return node
+}
+
+func TestConnectNextValidators(t *testing.T) {
+	_, b := newBlockChain(4, true)
+	block := b.currentBlock()
+	valSet := b.getValidators(block.Number().Uint64(), block.Hash())
+
+	server := &recordingP2PServer{peers: make(map[enode.ID]bool)}
+	b.SetP2PServer(server)
+
+	nextAddr := common.Address{1}
+	nextNode := newTestValEnode(t, b, nextAddr)
+
+	// Validators elected along with this one are connected to ahead of the switch
+	b.connectNextValidators([]common.Address{b.ValidatorAddress(), nextAddr}, valSet)
+	if !server.peers[nextNode.ID()] {
+		t.Errorf("next validator not connected")
+	}
+	if !b.isNextValidator(nextAddr) {
+		t.Errorf("next validator not recorded")
+	}
+
+	// Validators not predicted to be elected anymore are disconnected
+	b.connectNextValidators([]common.Address{b.ValidatorAddress()}, valSet)
+	if server.peers[nextNode.ID()] {
+		t.Errorf("validator not elected anymore still connected")
+	}
+	if b.isNextValidator(nextAddr) {
+		t.Errorf("validator not elected anymore still recorded")
+	}
+
+	// Nothing is connected to if this validator isn't elected
+	b.connectNextValidators([]common.Address{nextAddr}, valSet)
+	if server.peers[nextNode.ID()] || b.isNextValidator(nextAddr) {
+		t.Errorf("next validator connected while not elected")
+	}
+}
+
+func TestDropLeavingValPeers(t *testing.T) {
+	_, b := newBlockChain(4, true)
+	block := b.currentBlock()
+	valSet := b.getValidators(block.Number().Uint64(), block.Hash())
+
+	server := &recordingP2PServer{peers: make(map[enode.ID]bool)}
+	b.SetP2PServer(server)
+
+	var (
+		leftNode    = newTestValEnode(t, b, common.Address{1})
+		waitingNode = newTestValEnode(t, b, common.Address{2})
+		electedNode = newTestValEnode(t, b, valSet.GetByIndex(1).Address())
+	)
+	for _, node := range []*enode.Node{leftNode, waitingNode, electedNode} {
+		server.AddPeer(node, p2p.ValidatorPurpose)
+	}
+
+	// Leaving validators are kept connected during the grace period
+	for _, node := range []*enode.Node{leftNode, waitingNode, electedNode} {
+		b.dropValidatorPeer(node)
+	}
+	if len(server.peers) != 3 {
+		t.Fatalf("leaving validators dropped before their grace period: have %d peers, want 3", len(server.peers))
+	}
+
+	// Once it's over, only the ones that weren't elected again are dropped
+	b.leavingValPeers[leftNode.ID()].until = block.NumberU64()
+	b.leavingValPeers[electedNode.ID()].until = block.NumberU64()
+	b.dropLeavingValPeers(block)
+	if server.peers[leftNode.ID()] {
+		t.Errorf("leaving validator not dropped after its grace period")
+	}
+	if !server.peers[waitingNode.ID()] {
+		t.Errorf("leaving validator dropped during its grace period")
+	}
+	if !server.peers[electedNode.ID()] {
+		t.Errorf("validator elected again dropped")
+	}
+	if _, ok := b.leavingValPeers[waitingNode.ID()]; !ok || len(b.leavingValPeers) != 1 {
+		t.Errorf("leaving validators mismatch: have %v", b.leavingValPeers)
+	}
+}
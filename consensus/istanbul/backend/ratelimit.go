@@ -0,0 +1,96 @@
+// Copyright 2017 The celo Authors
+// This file is part of the celo library.
+//
+// The celo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The celo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the celo library. If not, see <http://www.gnu.org/licenses/>.
+
+package backend
+
+import (
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/metrics"
+	lru "github.com/hashicorp/golang-lru"
+)
+
+const (
+	rateLimitedSources = 1024 // Number of sources whose message rate is tracked
+
+	announceRegossipRate  = 1.0 / 20 // Announce messages regossiped per second for a single validator
+	announceRegossipBurst = 3        // Announce messages regossiped at once for a single validator
+)
+
+var (
+	// meters of the messages dropped for exceeding the rate limit of their source
+	droppedAnnounceMeter        = metrics.NewRegisteredMeter("consensus/istanbul/backend/dropped/announce", nil)
+	droppedAnnounceRequestMeter = metrics.NewRegisteredMeter("consensus/istanbul/backend/dropped/announcerequest", nil)
+	droppedFwdMeter             = metrics.NewRegisteredMeter("consensus/istanbul/backend/dropped/forward", nil)
+	droppedRegossipMeter        = metrics.NewRegisteredMeter("consensus/istanbul/backend/dropped/regossip", nil)
+)
+
+// rateLimiter limits the rate of the messages accepted from each source address,
+// with a token bucket per source. Only the most recently seen sources are tracked.
+type rateLimiter struct {
+	rate  float64 // Tokens added per second
+	burst float64 // Maximum number of tokens
+
+	mu      sync.Mutex
+	buckets *lru.Cache // Token buckets by source address
+}
+
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// newRateLimiter creates a rate limiter accepting rate messages per second from
+// each source, and up to burst messages at once. A zero rate disables it.
+func newRateLimiter(rate float64, burst float64) *rateLimiter {
+	buckets, _ := lru.New(rateLimitedSources)
+	return &rateLimiter{
+		rate:    rate,
+		burst:   burst,
+		buckets: buckets,
+	}
+}
+
+// allow returns whether a message from the given source is accepted at the given
+// time, consuming a token if so.
+func (rl *rateLimiter) allow(source common.Address, now time.Time) bool {
+	if rl == nil || rl.rate == 0 {
+		return true
+	}
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	bucket := &tokenBucket{tokens: rl.burst, last: now}
+	if b, ok := rl.buckets.Get(source); ok {
+		bucket = b.(*tokenBucket)
+		if elapsed := now.Sub(bucket.last); elapsed > 0 {
+			bucket.tokens += elapsed.Seconds() * rl.rate
+			if bucket.tokens > rl.burst {
+				bucket.tokens = rl.burst
+			}
+			bucket.last = now
+		}
+	} else {
+		rl.buckets.Add(source, bucket)
+	}
+	if bucket.tokens < 1 {
+		return false
+	}
+	bucket.tokens--
+	return true
+}
@@ -0,0 +1,72 @@
+// Copyright 2017 The celo Authors
+// This file is part of the celo library.
+//
+// The celo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The celo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the celo library. If not, see <http://www.gnu.org/licenses/>.
+
+package backend
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestRateLimiter(t *testing.T) {
+	var (
+		rl    = newRateLimiter(2, 3)
+		now   = time.Unix(1000, 0)
+		addr1 = common.Address{1}
+		addr2 = common.Address{2}
+	)
+	// A source can send a burst of messages, but no more
+	for i := 0; i < 3; i++ {
+		if !rl.allow(addr1, now) {
+			t.Fatalf("message %d of the burst dropped", i)
+		}
+	}
+	if rl.allow(addr1, now) {
+		t.Fatalf("message exceeding the burst accepted")
+	}
+	// Other sources are limited independently
+	if !rl.allow(addr2, now) {
+		t.Fatalf("message from another source dropped")
+	}
+	// Tokens are refilled at the given rate, up to the burst
+	now = now.Add(time.Second)
+	for i := 0; i < 2; i++ {
+		if !rl.allow(addr1, now) {
+			t.Fatalf("message %d after refill dropped", i)
+		}
+	}
+	if rl.allow(addr1, now) {
+		t.Fatalf("message exceeding the rate accepted")
+	}
+	now = now.Add(time.Hour)
+	for i := 0; i < 3; i++ {
+		if !rl.allow(addr1, now) {
+			t.Fatalf("message %d of the refilled burst dropped", i)
+		}
+	}
+	if rl.allow(addr1, now) {
+		t.Fatalf("refill exceeded the burst")
+	}
+	// A zero rate disables the limit
+	rl = newRateLimiter(0, 0)
+	for i := 0; i < 100; i++ {
+		if !rl.allow(addr1, now) {
+			t.Fatalf("message dropped without rate limit")
+		}
+	}
+}
@@ -0,0 +1,246 @@
+// Copyright 2017 The celo Authors
+// This file is part of the celo library.
+//
+// The celo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The celo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the celo library. If not, see <http://www.gnu.org/licenses/>.
+
+package backend
+
+import (
+	"errors"
+	"math/big"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+const dbKeyReplicaState = "istanbul-replica-state"
+
+var (
+	// errAlreadyPrimary is returned if a primary is asked to start validating.
+	errAlreadyPrimary = errors.New("validator is already primary")
+	// errAlreadyReplica is returned if a replica is asked to stop validating.
+	errAlreadyReplica = errors.New("validator is already a replica")
+	// errPastHandover is returned if a handover is scheduled at a block that
+	// was already reached.
+	errPastHandover = errors.New("handover block must be in the future")
+)
+
+// replicaState tracks whether the node signs for the validator (primary), or only
+// follows consensus without ever signing (replica), and the scheduled handover
+// between the two. The state is persisted, so that a demoted primary doesn't
+// start signing again after a restart.
+type replicaState struct {
+	db ethdb.Database
+	mu sync.RWMutex
+
+	primary  bool
+	startSeq uint64 // Sequence from which a replica acts as primary, 0 if not scheduled
+	stopSeq  uint64 // Sequence from which a primary acts as replica, 0 if not scheduled
+}
+
+// replicaStateRLP is the storage format of the replica state.
+type replicaStateRLP struct {
+	Primary  bool
+	StartSeq uint64
+	StopSeq  uint64
+}
+
+// newReplicaState loads the replica state from the database. A node configured
+// as replica always starts as one, a primary starts as replica if it was demoted
+// before it was restarted.
+func newReplicaState(db ethdb.Database, replica bool) *replicaState {
+	rs := &replicaState{db: db, primary: !replica}
+
+	if enc, err := db.Get([]byte(dbKeyReplicaState)); err == nil {
+		var stored replicaStateRLP
+		if err := rlp.DecodeBytes(enc, &stored); err != nil {
+			log.Error("Failed to decode replica state", "err", err)
+		} else {
+			rs.primary = rs.primary && stored.Primary
+			if rs.primary {
+				rs.stopSeq = stored.StopSeq
+			} else {
+				rs.startSeq = stored.StartSeq
+			}
+		}
+	}
+	rs.store()
+	return rs
+}
+
+// store persists the replica state. The lock must be held.
+func (rs *replicaState) store() {
+	enc, err := rlp.EncodeToBytes(&replicaStateRLP{Primary: rs.primary, StartSeq: rs.startSeq, StopSeq: rs.stopSeq})
+	if err != nil {
+		log.Error("Failed to encode replica state", "err", err)
+		return
+	}
+	if err := rs.db.Put([]byte(dbKeyReplicaState), enc); err != nil {
+		log.Error("Failed to store replica state", "err", err)
+	}
+}
+
+// isPrimaryForSeq returns whether the node signs for the given sequence, taking
+// the scheduled handover into account.
+func (rs *replicaState) isPrimaryForSeq(seq *big.Int) bool {
+	rs.mu.RLock()
+	defer rs.mu.RUnlock()
+
+	if rs.primary {
+		return rs.stopSeq == 0 || seq.Cmp(new(big.Int).SetUint64(rs.stopSeq)) < 0
+	}
+	return rs.startSeq != 0 && seq.Cmp(new(big.Int).SetUint64(rs.startSeq)) >= 0
+}
+
+// update applies the scheduled handover once the given sequence reached it.
+func (rs *replicaState) update(seq *big.Int) {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+
+	switch {
+	case rs.primary && rs.stopSeq != 0 && seq.Cmp(new(big.Int).SetUint64(rs.stopSeq)) >= 0:
+		log.Info("Stopped validating, acting as replica", "seq", seq)
+		rs.primary, rs.stopSeq = false, 0
+		rs.store()
+	case !rs.primary && rs.startSeq != 0 && seq.Cmp(new(big.Int).SetUint64(rs.startSeq)) >= 0:
+		log.Info("Started validating, acting as primary", "seq", seq)
+		rs.primary, rs.startSeq = true, 0
+		rs.store()
+	}
+}
+
+// setPrimary immediately promotes the node to primary, or demotes it to replica,
+// and cancels any scheduled handover.
+func (rs *replicaState) setPrimary(primary bool) error {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+
+	if rs.primary == primary {
+		if primary {
+			return errAlreadyPrimary
+		}
+		return errAlreadyReplica
+	}
+	rs.primary, rs.startSeq, rs.stopSeq = primary, 0, 0
+	rs.store()
+	return nil
+}
+
+// schedule sets the sequence at which a replica is promoted to primary, or a
+// primary is demoted to replica.
+func (rs *replicaState) schedule(primary bool, seq uint64) error {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+
+	if primary {
+		if rs.primary {
+			return errAlreadyPrimary
+		}
+		rs.startSeq = seq
+	} else {
+		if !rs.primary {
+			return errAlreadyReplica
+		}
+		rs.stopSeq = seq
+	}
+	rs.store()
+	return nil
+}
+
+// ReplicaInfo describes the replica state of the validator.
+type ReplicaInfo struct {
+	Primary    bool    `json:"primary"`
+	StartBlock *uint64 `json:"startBlock"` // Block from which a replica acts as primary
+	StopBlock  *uint64 `json:"stopBlock"`  // Block from which a primary acts as replica
+}
+
+func (rs *replicaState) info() *ReplicaInfo {
+	rs.mu.RLock()
+	defer rs.mu.RUnlock()
+
+	info := &ReplicaInfo{Primary: rs.primary}
+	if rs.startSeq != 0 {
+		start := rs.startSeq
+		info.StartBlock = &start
+	}
+	if rs.stopSeq != 0 {
+		stop := rs.stopSeq
+		info.StopBlock = &stop
+	}
+	return info
+}
+
+// IsPrimary returns whether the node signs for the validator at the sequence
+// following the current head.
+func (sb *Backend) IsPrimary() bool {
+	seq := common.Big1
+	if sb.currentBlock != nil {
+		seq = new(big.Int).Add(sb.currentBlock().Number(), common.Big1)
+	}
+	return sb.replicaState.isPrimaryForSeq(seq)
+}
+
+// IsPrimaryForSeq implements istanbul.Backend.IsPrimaryForSeq
+func (sb *Backend) IsPrimaryForSeq(seq *big.Int) bool {
+	return sb.replicaState.isPrimaryForSeq(seq)
+}
+
+// UpdateReplicaState implements istanbul.Backend.UpdateReplicaState
+func (sb *Backend) UpdateReplicaState(seq *big.Int) {
+	sb.replicaState.update(seq)
+}
+
+// StartValidating promotes a replica to primary immediately.
+func (sb *Backend) StartValidating() error {
+	return sb.replicaState.setPrimary(true)
+}
+
+// StopValidating demotes a primary to replica immediately.
+func (sb *Backend) StopValidating() error {
+	return sb.replicaState.setPrimary(false)
+}
+
+// StartValidatingAtBlock schedules the promotion of a replica to primary, from
+// the given block on. The primary should be scheduled to stop at the same block.
+func (sb *Backend) StartValidatingAtBlock(number uint64) error {
+	if err := sb.checkHandoverBlock(number); err != nil {
+		return err
+	}
+	return sb.replicaState.schedule(true, number)
+}
+
+// StopValidatingAtBlock schedules the demotion of a primary to replica, from the
+// given block on. The replica should be scheduled to start at the same block.
+func (sb *Backend) StopValidatingAtBlock(number uint64) error {
+	if err := sb.checkHandoverBlock(number); err != nil {
+		return err
+	}
+	return sb.replicaState.schedule(false, number)
+}
+
+// checkHandoverBlock ensures that consensus didn't reach the handover block yet.
+func (sb *Backend) checkHandoverBlock(number uint64) error {
+	if sb.currentBlock != nil && number <= sb.currentBlock().NumberU64()+1 {
+		return errPastHandover
+	}
+	return nil
+}
+
+// ReplicaState returns the replica state of the validator.
+func (sb *Backend) ReplicaState() *ReplicaInfo {
+	return sb.replicaState.info()
+}
@@ -0,0 +1,63 @@
+// Copyright 2017 The celo Authors
+// This file is part of the celo library.
+//
+// The celo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The celo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the celo library. If not, see <http://www.gnu.org/licenses/>.
+
+package backend
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/ethdb"
+)
+
+func TestReplicaHandover(t *testing.T) {
+	var (
+		primaryDb = ethdb.NewMemDatabase()
+		replicaDb = ethdb.NewMemDatabase()
+		primary   = newReplicaState(primaryDb, false)
+		replica   = newReplicaState(replicaDb, true)
+	)
+	if err := primary.schedule(false, 10); err != nil {
+		t.Fatalf("failed to schedule primary stop: %v", err)
+	}
+	if err := replica.schedule(true, 10); err != nil {
+		t.Fatalf("failed to schedule replica start: %v", err)
+	}
+	if err := replica.schedule(false, 10); err != errAlreadyReplica {
+		t.Fatalf("error mismatch: have %v, want %v", err, errAlreadyReplica)
+	}
+	// Exactly one of the two signs for every sequence
+	for seq := int64(1); seq < 20; seq++ {
+		primary.update(big.NewInt(seq))
+		replica.update(big.NewInt(seq))
+
+		p, r := primary.isPrimaryForSeq(big.NewInt(seq)), replica.isPrimaryForSeq(big.NewInt(seq))
+		if p == r {
+			t.Fatalf("seq %d: primary signing %v, replica signing %v", seq, p, r)
+		}
+		if want := seq < 10; p != want {
+			t.Fatalf("seq %d: primary signing mismatch: have %v, want %v", seq, p, want)
+		}
+	}
+	// The demoted primary must not sign after a restart
+	if newReplicaState(primaryDb, false).isPrimaryForSeq(big.NewInt(20)) {
+		t.Errorf("demoted primary signing after restart")
+	}
+	// The promoted replica follows its configuration after a restart
+	if newReplicaState(replicaDb, true).isPrimaryForSeq(big.NewInt(20)) {
+		t.Errorf("replica signing after restart")
+	}
+}
@@ -17,7 +17,9 @@
 package backend
 
 import (
+	"encoding/binary"
 	"encoding/json"
+	"io"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/consensus/istanbul"
@@ -25,12 +27,30 @@ import (
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/ethdb"
 	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rlp"
 )
 
 const (
-	dbKeySnapshotPrefix = "istanbul-snapshot"
+	dbKeySnapshotPrefix      = "istanbul-snapshot"
+	dbKeySnapshotIndexPrefix = "istanbul-snapshot-index" // dbKeySnapshotIndexPrefix + num (uint64 big endian) -> snapshot hash
+	dbKeySnapshotVersion     = "istanbul-snapshot-version"
+
+	// snapshotVersion is the version of the snapshot storage. Version 1 stores
+	// the snapshots RLP encoded and indexed by block number, version 0 stored
+	// them JSON encoded.
+	snapshotVersion = 1
 )
 
+func snapshotKey(hash common.Hash) []byte {
+	return append([]byte(dbKeySnapshotPrefix), hash[:]...)
+}
+
+func snapshotIndexKey(number uint64) []byte {
+	enc := make([]byte, 8)
+	binary.BigEndian.PutUint64(enc, number)
+	return append([]byte(dbKeySnapshotIndexPrefix), enc...)
+}
+
 // Snapshot is the state of the authorization voting at a given point in time.
 type Snapshot struct {
 	Epoch uint64 // The number of blocks for each epoch
@@ -53,14 +73,22 @@ func newSnapshot(epoch uint64, number uint64, hash common.Hash, valSet istanbul.
 	return snap
 }
 
-// loadSnapshot loads an existing snapshot from the database.
+// loadSnapshot loads an existing snapshot from the database. Snapshots still
+// stored in the legacy JSON encoding are converted to the RLP encoding.
 func loadSnapshot(epoch uint64, db ethdb.Database, hash common.Hash) (*Snapshot, error) {
-	blob, err := db.Get(append([]byte(dbKeySnapshotPrefix), hash[:]...))
+	blob, err := db.Get(snapshotKey(hash))
 	if err != nil {
 		return nil, err
 	}
 	snap := new(Snapshot)
-	if err := json.Unmarshal(blob, snap); err != nil {
+	if len(blob) > 0 && blob[0] == '{' {
+		if err := json.Unmarshal(blob, snap); err != nil {
+			return nil, err
+		}
+		if err := snap.store(db); err != nil {
+			log.Warn("Failed to convert legacy validator set snapshot", "number", snap.Number, "hash", snap.Hash, "err", err)
+		}
+	} else if err := rlp.DecodeBytes(blob, snap); err != nil {
 		return nil, err
 	}
 	snap.Epoch = epoch
@@ -70,11 +98,43 @@ func loadSnapshot(epoch uint64, db ethdb.Database, hash common.Hash) (*Snapshot,
 
 // store inserts the snapshot into the database.
 func (s *Snapshot) store(db ethdb.Database) error {
-	blob, err := json.Marshal(s)
+	blob, err := rlp.EncodeToBytes(s)
 	if err != nil {
 		return err
 	}
-	return db.Put(append([]byte(dbKeySnapshotPrefix), s.Hash[:]...), blob)
+	batch := db.NewBatch()
+	batch.Put(snapshotKey(s.Hash), blob)
+	batch.Put(snapshotIndexKey(s.Number), s.Hash[:])
+	return batch.Write()
+}
+
+// deleteSnapshot removes the snapshot at the given block number from the
+// database, if there is one.
+func deleteSnapshot(db ethdb.Database, number uint64) error {
+	enc, err := db.Get(snapshotIndexKey(number))
+	if err != nil {
+		return nil
+	}
+	batch := db.NewBatch()
+	batch.Delete(snapshotKey(common.BytesToHash(enc)))
+	batch.Delete(snapshotIndexKey(number))
+	return batch.Write()
+}
+
+// readSnapshotVersion retrieves the version of the snapshot storage.
+func readSnapshotVersion(db ethdb.Database) uint64 {
+	enc, err := db.Get([]byte(dbKeySnapshotVersion))
+	if err != nil || len(enc) != 8 {
+		return 0
+	}
+	return binary.BigEndian.Uint64(enc)
+}
+
+// writeSnapshotVersion stores the version of the snapshot storage.
+func writeSnapshotVersion(db ethdb.Database, version uint64) error {
+	enc := make([]byte, 8)
+	binary.BigEndian.PutUint64(enc, version)
+	return db.Put([]byte(dbKeySnapshotVersion), enc)
 }
 
 // copy creates a deep copy of the snapshot, though not the individual votes.
@@ -90,8 +150,9 @@ func (s *Snapshot) copy() *Snapshot {
 }
 
 // apply creates a new authorization snapshot by applying the given headers to
-// the original one.
-func (s *Snapshot) apply(headers []*types.Header, db ethdb.Database) (*Snapshot, error) {
+// the original one. The store callback is invoked with each intermediate
+// snapshot, so that the caller can decide which of them to persist.
+func (s *Snapshot) apply(headers []*types.Header, store func(*Snapshot)) (*Snapshot, error) {
 	// Allow passing in no headers for cleaner code
 	if len(headers) == 0 {
 		return s, nil
@@ -145,8 +206,9 @@ func (s *Snapshot) apply(headers []*types.Header, db ethdb.Database) (*Snapshot,
 		snap.Epoch = s.Epoch
 		snap.Number += s.Epoch
 		snap.Hash = header.Hash()
-		snap.store(db)
-		log.Trace("Stored voting snapshot to disk", "number", snap.Number, "hash", snap.Hash)
+		if store != nil {
+			store(snap)
+		}
 	}
 
 	return snap, nil
@@ -163,6 +225,36 @@ func (s *Snapshot) validators() []istanbul.ValidatorData {
 	return validators
 }
 
+// snapshotRLP is the compact storage format of a snapshot. The epoch size is
+// not stored, it's taken from the configuration on load.
+type snapshotRLP struct {
+	Number     uint64
+	Hash       common.Hash
+	Policy     istanbul.ProposerPolicy
+	Validators []istanbul.ValidatorData
+}
+
+// EncodeRLP serializes the snapshot into the Ethereum RLP format.
+func (s *Snapshot) EncodeRLP(w io.Writer) error {
+	return rlp.Encode(w, &snapshotRLP{
+		Number:     s.Number,
+		Hash:       s.Hash,
+		Policy:     s.ValSet.Policy(),
+		Validators: s.validators(),
+	})
+}
+
+// DecodeRLP implements rlp.Decoder, and loads the snapshot fields from a RLP stream.
+func (s *Snapshot) DecodeRLP(stream *rlp.Stream) error {
+	var enc snapshotRLP
+	if err := stream.Decode(&enc); err != nil {
+		return err
+	}
+	s.Number, s.Hash = enc.Number, enc.Hash
+	s.ValSet = validator.NewSet(enc.Validators, enc.Policy)
+	return nil
+}
+
 type snapshotJSON struct {
 	Epoch  uint64      `json:"epoch"`
 	Number uint64      `json:"number"`
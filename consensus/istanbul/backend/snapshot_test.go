@@ -19,6 +19,7 @@ package backend
 import (
 	"bytes"
 	"crypto/ecdsa"
+	"encoding/json"
 	"math/big"
 	"reflect"
 	"sort"
@@ -449,3 +450,57 @@ func TestSaveAndLoad(t *testing.T) {
 		t.Errorf("validator set mismatch: have %v, want %v", snap1.ValSet, snap.ValSet)
 	}
 }
+
+func TestLegacySnapshotMigration(t *testing.T) {
+	snap := &Snapshot{
+		Epoch:  5,
+		Number: 10,
+		Hash:   common.HexToHash("1234567890"),
+		ValSet: validator.NewSet([]istanbul.ValidatorData{
+			{Address: common.BytesToAddress([]byte("1234567894"))},
+		}, istanbul.RoundRobin),
+	}
+	db := ethdb.NewMemDatabase()
+	blob, err := json.Marshal(snap)
+	if err != nil {
+		t.Fatalf("failed to encode legacy snapshot: %v", err)
+	}
+	db.Put(snapshotKey(snap.Hash), blob)
+
+	snap1, err := loadSnapshot(snap.Epoch, db, snap.Hash)
+	if err != nil {
+		t.Fatalf("load legacy snapshot failed: %v", err)
+	}
+	if snap1.Number != snap.Number || snap1.Hash != snap.Hash || snap1.ValSet.Size() != 1 {
+		t.Errorf("snapshot mismatch: have %v, want %v", snap1, snap)
+	}
+	// The snapshot should have been converted to the RLP format and indexed
+	if blob, _ := db.Get(snapshotKey(snap.Hash)); len(blob) == 0 || blob[0] == '{' {
+		t.Errorf("snapshot not converted: %x", blob)
+	}
+	if enc, _ := db.Get(snapshotIndexKey(snap.Number)); common.BytesToHash(enc) != snap.Hash {
+		t.Errorf("snapshot index mismatch: have %x, want %x", enc, snap.Hash)
+	}
+}
+
+func TestSnapshotRetention(t *testing.T) {
+	db := ethdb.NewMemDatabase()
+	sb := &Backend{
+		config: &istanbul.Config{Epoch: 10, SnapshotRetention: 2, SnapshotCheckpointInterval: 4},
+		db:     db,
+	}
+	for number := uint64(0); number <= 100; number += 10 {
+		snap := newSnapshot(10, number, common.BigToHash(new(big.Int).SetUint64(number+1)), validator.NewSet(nil, istanbul.RoundRobin))
+		sb.storeSnapshot(snap, number)
+	}
+	retained := map[uint64]bool{0: true, 40: true, 80: true, 90: true, 100: true}
+	for number := uint64(0); number <= 100; number += 10 {
+		hash := common.BigToHash(new(big.Int).SetUint64(number + 1))
+		if _, err := loadSnapshot(10, db, hash); (err == nil) != retained[number] {
+			t.Errorf("snapshot %d: retained mismatch: have %v, want %v", number, err == nil, retained[number])
+		}
+	}
+	if have := sb.lastCheckpointSnapshot(70); have != 40 {
+		t.Errorf("checkpoint mismatch: have %d, want %d", have, 40)
+	}
+}
@@ -35,9 +35,10 @@ import (
 // define the validator enode share message
 
 type sharedValidatorEnode struct {
-	Address  common.Address
-	EnodeURL string
-	View     *istanbul.View
+	Address     common.Address
+	EnodeURL    string
+	View        *istanbul.View
+	Certificate *istanbul.EnodeCertificate // nil for the entries of istanbul64 validators, and for istanbul64 proxies
 }
 
 type valEnodesShareData struct {
@@ -60,6 +61,43 @@ func (sd *valEnodesShareData) String() string {
 //
 // define the functions that needs to be provided for rlp Encoder/Decoder.
 
+// EncodeRLP serializes sve into the Ethereum RLP format, leaving the certificate
+// out if there is none, as in the layout of istanbul64 peers.
+func (sve *sharedValidatorEnode) EncodeRLP(w io.Writer) error {
+	if sve.Certificate == nil {
+		return rlp.Encode(w, []interface{}{sve.Address, sve.EnodeURL, sve.View})
+	}
+	return rlp.Encode(w, []interface{}{sve.Address, sve.EnodeURL, sve.View, sve.Certificate})
+}
+
+// DecodeRLP implements rlp.Decoder, and load the sve fields from a RLP stream.
+func (sve *sharedValidatorEnode) DecodeRLP(s *rlp.Stream) error {
+	var entry sharedValidatorEnode
+	if _, err := s.List(); err != nil {
+		return err
+	}
+	if err := s.Decode(&entry.Address); err != nil {
+		return err
+	}
+	if err := s.Decode(&entry.EnodeURL); err != nil {
+		return err
+	}
+	if err := s.Decode(&entry.View); err != nil {
+		return err
+	}
+	var certificate istanbul.EnodeCertificate
+	if err := s.Decode(&certificate); err == nil {
+		entry.Certificate = &certificate
+	} else if err != rlp.EOL {
+		return err
+	}
+	if err := s.ListEnd(); err != nil {
+		return err
+	}
+	*sve = entry
+	return nil
+}
+
 // EncodeRLP serializes sd into the Ethereum RLP format.
 func (sd *valEnodesShareData) EncodeRLP(w io.Writer) error {
 	return rlp.Encode(w, []interface{}{sd.ValEnodes})
@@ -100,7 +138,9 @@ func (sb *Backend) sendValEnodesShareMsgs() {
 	}
 }
 
-func (sb *Backend) generateValEnodesShareMsg() (*istanbul.Message, error) {
+// generateValEnodesShareMsg generates a validator enode share message in the
+// layout of the peers of the given protocol version.
+func (sb *Backend) generateValEnodesShareMsg(version int) (*istanbul.Message, error) {
 	vetEntries, err := sb.valEnodeTable.GetAllValEnodes()
 
 	if err != nil {
@@ -110,11 +150,16 @@ func (sb *Backend) generateValEnodesShareMsg() (*istanbul.Message, error) {
 
 	sharedValidatorEnodes := make([]sharedValidatorEnode, 0, len(vetEntries))
 	for address, vetEntry := range vetEntries {
-		sharedValidatorEnodes = append(sharedValidatorEnodes, sharedValidatorEnode{
+		sharedValidatorEnode := sharedValidatorEnode{
 			Address:  address,
 			EnodeURL: vetEntry.Node.String(),
 			View:     vetEntry.View,
-		})
+		}
+		// istanbul64 peers can't decode the certificates
+		if hasCertifiedAnnounces(version) {
+			sharedValidatorEnode.Certificate = vetEntry.Certificate
+		}
+		sharedValidatorEnodes = append(sharedValidatorEnodes, sharedValidatorEnode)
 	}
 
 	valEnodesShareData := &valEnodesShareData{
@@ -144,8 +189,12 @@ func (sb *Backend) sendValEnodesShareMsg() error {
 		sb.logger.Error("No proxy peers, cannot send Istanbul Validator Enodes Share message")
 		return nil
 	}
+	// Replicas don't sign messages on behalf of the validator
+	if !sb.IsPrimary() {
+		return nil
+	}
 
-	msg, err := sb.generateValEnodesShareMsg()
+	msg, err := sb.generateValEnodesShareMsg(sb.proxyNode.peer.Version())
 	if err != nil {
 		return err
 	}
@@ -197,11 +246,19 @@ func (sb *Backend) handleValEnodesShareMsg(payload []byte) error {
 
 	upsertBatch := make(map[common.Address]*vet.AddressEntry)
 	for _, sharedValidatorEnode := range valEnodesShareData.ValEnodes {
+		// Verify the enode was certified by its validator, and is still fresh.
+		// The entries of istanbul64 validators have no certificate.
+		if sharedValidatorEnode.Certificate == nil {
+			sb.logger.Trace("Received an uncertified validator enode", "address", sharedValidatorEnode.Address)
+		} else if err := sharedValidatorEnode.Certificate.Verify(sharedValidatorEnode.Address, sharedValidatorEnode.EnodeURL, now(), enodeCertificateTTL); err != nil {
+			sb.logger.Debug("Skipping shared validator enode with a bad certificate", "address", sharedValidatorEnode.Address, "err", err)
+			continue
+		}
 		if node, err := enode.ParseV4(sharedValidatorEnode.EnodeURL); err != nil {
 			sb.logger.Warn("Error in parsing enodeURL", "enodeURL", sharedValidatorEnode.EnodeURL)
 			continue
 		} else {
-			upsertBatch[sharedValidatorEnode.Address] = &vet.AddressEntry{Node: node, View: sharedValidatorEnode.View}
+			upsertBatch[sharedValidatorEnode.Address] = &vet.AddressEntry{Node: node, View: sharedValidatorEnode.View, Certificate: sharedValidatorEnode.Certificate}
 		}
 	}
 
@@ -4,11 +4,13 @@ import (
 	"math/big"
 	"net"
 	"testing"
+	"time"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/consensus/istanbul"
 	vet "github.com/ethereum/go-ethereum/consensus/istanbul/backend/internal/enodes"
 	"github.com/ethereum/go-ethereum/p2p/enode"
+	"github.com/ethereum/go-ethereum/rlp"
 )
 
 func TestHandleValEnodeShareMsg(t *testing.T) {
@@ -22,7 +24,7 @@ func TestHandleValEnodeShareMsg(t *testing.T) {
 
 	// Tests that a validator enode share message without any validator info
 	// in the payload will not result in errors
-	msg, err := b.generateValEnodesShareMsg()
+	msg, err := b.generateValEnodesShareMsg(istanbul65)
 	if err != nil {
 		t.Errorf("error %v", err)
 	}
@@ -52,15 +54,18 @@ func TestHandleValEnodeShareMsg(t *testing.T) {
 
 	// Test that a validator enode share message will result in the enode
 	// being inserted into the valEnodeTable
+	certificate := istanbul.NewEnodeCertificate(testNode.String(), time.Now())
+	certificate.Sign(b.Sign)
 	b.valEnodeTable.Upsert(map[common.Address]*vet.AddressEntry{testAddress: {
 		Node: testNode,
 		View: &istanbul.View{
 			Round:    big.NewInt(0),
 			Sequence: big.NewInt(0),
 		},
+		Certificate: certificate,
 	}})
 	senderAddress = b.Address()
-	newMsg, err := b.generateValEnodesShareMsg()
+	newMsg, err := b.generateValEnodesShareMsg(istanbul65)
 	if err != nil {
 		t.Errorf("error %v", err)
 	}
@@ -83,3 +88,36 @@ func TestHandleValEnodeShareMsg(t *testing.T) {
 		t.Errorf("Expected %v, but got %v instead", testNode.String(), node.String())
 	}
 }
+
+func TestSharedValidatorEnodeLegacyLayout(t *testing.T) {
+	view := &istanbul.View{Round: big.NewInt(1), Sequence: big.NewInt(2)}
+
+	// Entries without a certificate are in the layout of istanbul64 peers
+	legacy, err := rlp.EncodeToBytes([]interface{}{common.Address{1}, "enode", view})
+	if err != nil {
+		t.Fatalf("failed to encode legacy entry: %v", err)
+	}
+	if enc, _ := rlp.EncodeToBytes(&sharedValidatorEnode{Address: common.Address{1}, EnodeURL: "enode", View: view}); string(enc) != string(legacy) {
+		t.Errorf("legacy entry encoding mismatch: have %x, want %x", enc, legacy)
+	}
+	var decoded sharedValidatorEnode
+	if err := rlp.DecodeBytes(legacy, &decoded); err != nil {
+		t.Fatalf("failed to decode legacy entry: %v", err)
+	}
+	if decoded.Address != (common.Address{1}) || decoded.EnodeURL != "enode" || decoded.Certificate != nil {
+		t.Errorf("legacy entry mismatch: have %v", decoded.String())
+	}
+
+	// Entries with a certificate keep it
+	certificate := istanbul.NewEnodeCertificate("enode", time.Now())
+	enc, err := rlp.EncodeToBytes(&sharedValidatorEnode{Address: common.Address{1}, EnodeURL: "enode", View: view, Certificate: certificate})
+	if err != nil {
+		t.Fatalf("failed to encode entry: %v", err)
+	}
+	if err := rlp.DecodeBytes(enc, &decoded); err != nil {
+		t.Fatalf("failed to decode entry: %v", err)
+	}
+	if decoded.Certificate == nil || decoded.Certificate.EnodeURLHash != certificate.EnodeURLHash {
+		t.Errorf("certificate mismatch: have %v, want %v", decoded.Certificate, certificate)
+	}
+}
@@ -0,0 +1,98 @@
+// Copyright 2017 The celo Authors
+// This file is part of the celo library.
+//
+// The celo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The celo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the celo library. If not, see <http://www.gnu.org/licenses/>.
+
+package istanbul
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+var (
+	// ErrInvalidEnodeCertificate is returned if an enode certificate is not signed
+	// by the validator, or doesn't match the enode URL it certifies.
+	ErrInvalidEnodeCertificate = errors.New("invalid enode certificate")
+	// ErrExpiredEnodeCertificate is returned if an enode certificate is too old,
+	// or issued too far in the future.
+	ErrExpiredEnodeCertificate = errors.New("expired enode certificate")
+)
+
+// EnodeCertificate is a validator's signed statement of the enode URL it can be
+// reached at, issued at a given time. It is verified independently of the
+// message carrying it, so it can be relayed and shared between nodes.
+type EnodeCertificate struct {
+	EnodeURLHash common.Hash
+	Timestamp    uint64 // Unix time in seconds at which the certificate was issued
+	Signature    []byte
+}
+
+// NewEnodeCertificate creates an unsigned certificate of the given enode URL.
+func NewEnodeCertificate(enodeURL string, timestamp time.Time) *EnodeCertificate {
+	return &EnodeCertificate{
+		EnodeURLHash: RLPHash(enodeURL),
+		Timestamp:    uint64(timestamp.Unix()),
+	}
+}
+
+func (ec *EnodeCertificate) String() string {
+	return fmt.Sprintf("{EnodeURLHash: %v, Timestamp: %d}", ec.EnodeURLHash.Hex(), ec.Timestamp)
+}
+
+func (ec *EnodeCertificate) payloadNoSig() ([]byte, error) {
+	return rlp.EncodeToBytes([]interface{}{ec.EnodeURLHash, ec.Timestamp})
+}
+
+// Sign signs the certificate with the given signing function.
+func (ec *EnodeCertificate) Sign(signingFn func(data []byte) ([]byte, error)) error {
+	payload, err := ec.payloadNoSig()
+	if err != nil {
+		return err
+	}
+	ec.Signature, err = signingFn(payload)
+	return err
+}
+
+// Verify checks that the certificate was signed by the given validator, and was
+// issued within ttl of now. If enodeURL is not empty, it also checks that the
+// certificate is for that enode URL.
+func (ec *EnodeCertificate) Verify(address common.Address, enodeURL string, now time.Time, ttl time.Duration) error {
+	payload, err := ec.payloadNoSig()
+	if err != nil {
+		return err
+	}
+	signer, err := GetSignatureAddress(payload, ec.Signature)
+	if err != nil || signer != address {
+		return ErrInvalidEnodeCertificate
+	}
+	if enodeURL != "" && RLPHash(enodeURL) != ec.EnodeURLHash {
+		return ErrInvalidEnodeCertificate
+	}
+	if ec.IsExpired(now, ttl) {
+		return ErrExpiredEnodeCertificate
+	}
+	return nil
+}
+
+// IsExpired returns whether the certificate was issued more than ttl before now.
+// Certificates issued more than ttl after now are considered expired as well.
+func (ec *EnodeCertificate) IsExpired(now time.Time, ttl time.Duration) bool {
+	issued := time.Unix(int64(ec.Timestamp), 0)
+	return now.Sub(issued) > ttl || issued.Sub(now) > ttl
+}
@@ -0,0 +1,72 @@
+// Copyright 2017 The celo Authors
+// This file is part of the celo library.
+//
+// The celo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The celo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the celo library. If not, see <http://www.gnu.org/licenses/>.
+
+package istanbul
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+func TestEnodeCertificate(t *testing.T) {
+	const (
+		enodeURL = "enode://1dd9d65c4552b5eb43d5ad55a2ee3f56c6cbc1c64a5c8d659f51fcd51bace24351232b8d7821617d2b29b54b81cdefb9b3e9c37d7fd5f63270bcc9e1a6f6a439@127.0.0.1:52150"
+		ttl      = 10 * time.Minute
+	)
+	key, _ := crypto.GenerateKey()
+	address := crypto.PubkeyToAddress(key.PublicKey)
+	issued := time.Unix(1000000, 0)
+
+	certificate := NewEnodeCertificate(enodeURL, issued)
+	if err := certificate.Sign(func(data []byte) ([]byte, error) {
+		return crypto.Sign(crypto.Keccak256(data), key)
+	}); err != nil {
+		t.Fatalf("failed to sign certificate: %v", err)
+	}
+
+	// The certificate survives a round trip through RLP
+	enc, err := rlp.EncodeToBytes(certificate)
+	if err != nil {
+		t.Fatalf("failed to encode certificate: %v", err)
+	}
+	var decoded EnodeCertificate
+	if err := rlp.DecodeBytes(enc, &decoded); err != nil {
+		t.Fatalf("failed to decode certificate: %v", err)
+	}
+
+	tests := []struct {
+		address  common.Address
+		enodeURL string
+		now      time.Time
+		err      error
+	}{
+		{address, enodeURL, issued.Add(time.Minute), nil},
+		{address, "", issued.Add(time.Minute), nil},
+		{common.Address{1}, enodeURL, issued.Add(time.Minute), ErrInvalidEnodeCertificate},
+		{address, enodeURL + "1", issued.Add(time.Minute), ErrInvalidEnodeCertificate},
+		{address, enodeURL, issued.Add(ttl + time.Second), ErrExpiredEnodeCertificate},
+		{address, enodeURL, issued.Add(-ttl - time.Second), ErrExpiredEnodeCertificate},
+	}
+	for i, tt := range tests {
+		if err := decoded.Verify(tt.address, tt.enodeURL, tt.now, ttl); err != tt.err {
+			t.Errorf("test %d: error mismatch: have %v, want %v", i, err, tt.err)
+		}
+	}
+}
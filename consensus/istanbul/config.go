@@ -37,6 +37,30 @@ type Config struct {
 	LookbackWindow       uint64         `toml:",omitempty"` // The window of blocks in which a validator is forgived from voting
 	ValidatorEnodeDBPath string         `toml:",omitempty"` // The location for the validator enodes DB
 
+	// Validator set snapshot retention. Snapshots of the last SnapshotRetention
+	// epochs and of every SnapshotCheckpointInterval-th epoch are kept on disk,
+	// the others are rebuilt from the epoch headers when needed. A retention of
+	// zero keeps all snapshots.
+	SnapshotRetention          uint64 `toml:",omitempty"` // The number of recent epochs whose validator set snapshots are kept
+	SnapshotCheckpointInterval uint64 `toml:",omitempty"` // The number of epochs between validator set snapshots that are never pruned
+
+	// Backlog Configs. Future messages further than the sequence or round gap
+	// from the current view are dropped, and a validator's backlog holding more
+	// than the limit evicts its furthest messages first. Zero disables a limit.
+	BacklogPerValidatorLimit uint64 `toml:",omitempty"` // The maximum number of future messages kept per validator
+	BacklogSequenceGap       uint64 `toml:",omitempty"` // The number of sequences ahead of the current one from which future messages are kept
+	BacklogRoundGap          uint64 `toml:",omitempty"` // The number of rounds ahead of the current one from which future messages are kept
+
+	// Announce and forward messages rate limit. Announce messages are limited per
+	// origin validator, forward messages per peer except for the proxied validator.
+	PeerMessageRate uint64 `toml:",omitempty"` // The number of announce or forward messages accepted per second from a single source
+
+	// Validator connections
+	ValidatorPreconnectBlocks uint64 `toml:",omitempty"` // The number of blocks before an epoch switch to connect to the next validators, and after it to drop the leaving ones
+
+	// Replica Configs
+	Replica bool `toml:",omitempty"` // Specifies if this node follows consensus as a hot-standby replica of the validator, without signing
+
 	// Proxy Configs
 	Proxy                   bool           `toml:",omitempty"` // Specifies if this node is a proxy
 	ProxiedValidatorAddress common.Address `toml:",omitempty"` // The address of the proxied validator
@@ -48,12 +72,19 @@ type Config struct {
 }
 
 var DefaultConfig = &Config{
-	RequestTimeout:       3000,
-	BlockPeriod:          1,
-	ProposerPolicy:       ShuffledRoundRobin,
-	Epoch:                30000,
-	LookbackWindow:       12,
-	ValidatorEnodeDBPath: "validatorenodes",
-	Proxy:                false,
-	Proxied:              false,
+	RequestTimeout:             3000,
+	BlockPeriod:                1,
+	ProposerPolicy:             ShuffledRoundRobin,
+	Epoch:                      30000,
+	LookbackWindow:             12,
+	ValidatorEnodeDBPath:       "validatorenodes",
+	SnapshotRetention:          32,
+	SnapshotCheckpointInterval: 128,
+	BacklogPerValidatorLimit:   100,
+	BacklogSequenceGap:         10,
+	BacklogRoundGap:            10,
+	PeerMessageRate:            10,
+	ValidatorPreconnectBlocks:  20,
+	Proxy:                      false,
+	Proxied:                    false,
 }
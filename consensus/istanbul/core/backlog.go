@@ -17,8 +17,12 @@
 package core
 
 import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/prque"
 	"github.com/ethereum/go-ethereum/consensus/istanbul"
+	"github.com/ethereum/go-ethereum/metrics"
 )
 
 var (
@@ -29,6 +33,11 @@ var (
 		istanbul.MsgCommit:     2,
 		istanbul.MsgPrepare:    3,
 	}
+
+	// meters of the future messages dropped for being too far ahead of the
+	// current view, and for overflowing the backlog of their validator
+	backlogFarMeter  = metrics.NewRegisteredMeter("consensus/istanbul/core/backlog/dropped/far", nil)
+	backlogFullMeter = metrics.NewRegisteredMeter("consensus/istanbul/core/backlog/dropped/full", nil)
 )
 
 // checkMessage checks the message state
@@ -95,90 +104,176 @@ func (c *core) storeBacklog(msg *istanbul.Message, src istanbul.Validator) {
 		return
 	}
 
+	view := backlogView(msg)
+	if view == nil {
+		return
+	}
+	if c.isTooFarAhead(view) {
+		logger.Trace("Drop future message too far ahead", "msg_seq", view.Sequence, "msg_round", view.Round)
+		backlogFarMeter.Mark(1)
+		return
+	}
+
 	logger.Trace("Store future message")
 
 	c.backlogsMu.Lock()
 	defer c.backlogsMu.Unlock()
 
-	backlog := c.backlogs[src]
+	// Backlogs are kept by address, as the validator of a source changes along
+	// with the validator set
+	backlog := c.backlogs[msg.Address]
 	if backlog == nil {
-		backlog = prque.New(nil)
+		backlog = newMsgBacklog()
+	}
+	backlog.src = src
+	priority := toPriority(msg.Code, view)
+
+	// Make room by evicting the furthest messages, keeping the near future ones
+	if limit := c.config.BacklogPerValidatorLimit; limit > 0 {
+		for uint64(backlog.Size()) >= limit {
+			backlogFullMeter.Mark(1)
+			if !backlog.evictFurthest(priority) {
+				logger.Trace("Drop future message, backlog full", "msg_seq", view.Sequence, "msg_round", view.Round)
+				return
+			}
+		}
+	}
+	backlog.Push(msg, priority)
+	c.backlogs[msg.Address] = backlog
+}
+
+// isTooFarAhead returns whether the view of a future message is further ahead of
+// the current view than the configured sequence and round gaps allow.
+func (c *core) isTooFarAhead(view *istanbul.View) bool {
+	seq, round := c.current.Sequence(), common.Big0
+	if view.Sequence.Cmp(seq) == 0 {
+		round = c.current.DesiredRound()
+	}
+	if gap := c.config.BacklogSequenceGap; gap > 0 && view.Sequence.Cmp(new(big.Int).Add(seq, new(big.Int).SetUint64(gap))) > 0 {
+		return true
+	}
+	if gap := c.config.BacklogRoundGap; gap > 0 && view.Round.Cmp(new(big.Int).Add(round, new(big.Int).SetUint64(gap))) > 0 {
+		return true
+	}
+	return false
+}
+
+// backlogItem is a message of a backlog, with its index in both of its queues.
+type backlogItem struct {
+	msg       *istanbul.Message
+	priority  int64
+	nearIndex int
+	farIndex  int
+}
+
+// msgBacklog holds the future messages of a single source, popped nearest first,
+// with the furthest message indexed for eviction.
+type msgBacklog struct {
+	src  istanbul.Validator // Latest validator of the source
+	near *prque.Prque       // Messages by priority, nearest first
+	far  *prque.Prque       // Messages by negated priority, furthest first
+}
+
+func newMsgBacklog() *msgBacklog {
+	return &msgBacklog{
+		near: prque.New(func(data interface{}, index int) { data.(*backlogItem).nearIndex = index }),
+		far:  prque.New(func(data interface{}, index int) { data.(*backlogItem).farIndex = index }),
 	}
+}
+
+// Push adds a message with the given priority to the backlog.
+func (b *msgBacklog) Push(msg *istanbul.Message, priority int64) {
+	item := &backlogItem{msg: msg, priority: priority}
+	b.near.Push(item, priority)
+	b.far.Push(item, -priority)
+}
+
+// Pop removes the nearest message from the backlog, returning it along with its
+// priority.
+func (b *msgBacklog) Pop() (*istanbul.Message, int64) {
+	item := b.near.PopItem().(*backlogItem)
+	b.far.Remove(item.farIndex)
+	return item.msg, item.priority
+}
+
+// PopItem removes the nearest message from the backlog and returns it.
+func (b *msgBacklog) PopItem() *istanbul.Message {
+	msg, _ := b.Pop()
+	return msg
+}
+
+// Empty returns whether the backlog holds no messages.
+func (b *msgBacklog) Empty() bool {
+	return b.near.Empty()
+}
+
+// Size returns the number of messages in the backlog.
+func (b *msgBacklog) Size() int {
+	return b.near.Size()
+}
+
+// evictFurthest removes the message furthest in the future from the backlog if
+// it is further than a message of the given priority, and returns whether it did.
+func (b *msgBacklog) evictFurthest(priority int64) bool {
+	if b.far.Empty() {
+		return false
+	}
+	data, negated := b.far.Pop()
+	if -negated >= priority {
+		b.far.Push(data, negated)
+		return false
+	}
+	b.near.Remove(data.(*backlogItem).nearIndex)
+	return true
+}
+
+// backlogView decodes the view of a message stored in the backlog, returning nil
+// if the message can't be decoded.
+func backlogView(msg *istanbul.Message) *istanbul.View {
 	switch msg.Code {
 	case istanbul.MsgPreprepare:
 		var p *istanbul.Preprepare
-		err := msg.Decode(&p)
-		if err == nil {
-			backlog.Push(msg, toPriority(msg.Code, p.View))
+		if err := msg.Decode(&p); err == nil {
+			return p.View
 		}
 	case istanbul.MsgPrepare:
 		var p *istanbul.Subject
-		err := msg.Decode(&p)
-		if err == nil {
-			backlog.Push(msg, toPriority(msg.Code, p.View))
+		if err := msg.Decode(&p); err == nil {
+			return p.View
 		}
 	case istanbul.MsgCommit:
 		var cs *istanbul.CommittedSubject
-		err := msg.Decode(&cs)
-		if err == nil {
-			backlog.Push(msg, toPriority(msg.Code, cs.Subject.View))
+		if err := msg.Decode(&cs); err == nil {
+			return cs.Subject.View
 		}
 	case istanbul.MsgRoundChange:
 		var p *istanbul.RoundChange
-		err := msg.Decode(&p)
-		if err == nil {
-			backlog.Push(msg, toPriority(msg.Code, p.View))
+		if err := msg.Decode(&p); err == nil {
+			return p.View
 		}
 	}
-	c.backlogs[src] = backlog
+	return nil
 }
 
 func (c *core) processBacklog() {
 	c.backlogsMu.Lock()
 	defer c.backlogsMu.Unlock()
 
-	for src, backlog := range c.backlogs {
+	for addr, backlog := range c.backlogs {
 		if backlog == nil {
 			continue
 		}
 
-		logger := c.newLogger("func", "processBacklog", "from", src)
+		src := backlog.src
+		logger := c.newLogger("func", "processBacklog", "from", addr)
 		isFuture := false
 
 		// We stop processing if
 		//   1. backlog is empty
 		//   2. The first message in queue is a future message
 		for !(backlog.Empty() || isFuture) {
-			m, prio := backlog.Pop()
-			msg := m.(*istanbul.Message)
-			var view *istanbul.View
-			switch msg.Code {
-			case istanbul.MsgPreprepare:
-				var m *istanbul.Preprepare
-				err := msg.Decode(&m)
-				if err == nil {
-					view = m.View
-				}
-			case istanbul.MsgPrepare:
-				var sub *istanbul.Subject
-				err := msg.Decode(&sub)
-				if err == nil {
-					view = sub.View
-				}
-			case istanbul.MsgCommit:
-				var cs *istanbul.CommittedSubject
-				err := msg.Decode(&cs)
-				if err == nil {
-					view = cs.Subject.View
-				}
-			case istanbul.MsgRoundChange:
-				var rc *istanbul.RoundChange
-				err := msg.Decode(&rc)
-				if err == nil {
-					view = rc.View
-				}
-			}
-
+			msg, prio := backlog.Pop()
+			view := backlogView(msg)
 			if view == nil {
 				logger.Debug("Nil view", "msg", msg)
 				continue
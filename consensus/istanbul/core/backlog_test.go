@@ -24,7 +24,6 @@ import (
 	"time"
 
 	"github.com/ethereum/go-ethereum/common"
-	"github.com/ethereum/go-ethereum/common/prque"
 	"github.com/ethereum/go-ethereum/consensus/istanbul"
 	"github.com/ethereum/go-ethereum/consensus/istanbul/validator"
 	"github.com/ethereum/go-ethereum/event"
@@ -219,9 +218,14 @@ func TestCheckMessage(t *testing.T) {
 func TestStoreBacklog(t *testing.T) {
 	testLogger.SetHandler(elog.StdoutHandler)
 	c := &core{
+		config:     istanbul.DefaultConfig,
 		logger:     testLogger,
-		backlogs:   make(map[istanbul.Validator]*prque.Prque),
+		backlogs:   make(map[common.Address]*msgBacklog),
 		backlogsMu: new(sync.Mutex),
+		current: newRoundState(&istanbul.View{
+			Sequence: big.NewInt(9),
+			Round:    big.NewInt(0),
+		}, newTestValidatorSet(4), nil, nil, istanbul.EmptyPreparedCertificate(), nil),
 	}
 	v := &istanbul.View{
 		Round:    big.NewInt(10),
@@ -240,7 +244,7 @@ func TestStoreBacklog(t *testing.T) {
 		Address: p.Address(),
 	}
 	c.storeBacklog(m, p)
-	msg := c.backlogs[p].PopItem()
+	msg := c.backlogs[p.Address()].PopItem()
 	if !reflect.DeepEqual(msg, m) {
 		t.Errorf("message mismatch: have %v, want %v", msg, m)
 	}
@@ -258,7 +262,7 @@ func TestStoreBacklog(t *testing.T) {
 		Address: p.Address(),
 	}
 	c.storeBacklog(m, p)
-	msg = c.backlogs[p].PopItem()
+	msg = c.backlogs[p.Address()].PopItem()
 	if !reflect.DeepEqual(msg, m) {
 		t.Errorf("message mismatch: have %v, want %v", msg, m)
 	}
@@ -277,7 +281,7 @@ func TestStoreBacklog(t *testing.T) {
 		Address: p.Address(),
 	}
 	c.storeBacklog(m, p)
-	msg = c.backlogs[p].PopItem()
+	msg = c.backlogs[p.Address()].PopItem()
 	if !reflect.DeepEqual(msg, m) {
 		t.Errorf("message mismatch: have %v, want %v", msg, m)
 	}
@@ -295,20 +299,101 @@ func TestStoreBacklog(t *testing.T) {
 		Address: p.Address(),
 	}
 	c.storeBacklog(m, p)
-	msg = c.backlogs[p].PopItem()
+	msg = c.backlogs[p.Address()].PopItem()
 	if !reflect.DeepEqual(msg, m) {
 		t.Errorf("message mismatch: have %v, want %v", msg, m)
 	}
 }
 
+func TestStoreBacklogLimits(t *testing.T) {
+	testLogger.SetHandler(elog.StdoutHandler)
+	config := *istanbul.DefaultConfig
+	config.BacklogPerValidatorLimit = 3
+	config.BacklogSequenceGap = 5
+	config.BacklogRoundGap = 2
+
+	c := &core{
+		config:     &config,
+		logger:     testLogger,
+		backlogs:   make(map[common.Address]*msgBacklog),
+		backlogsMu: new(sync.Mutex),
+		current: newRoundState(&istanbul.View{
+			Sequence: big.NewInt(10),
+			Round:    big.NewInt(1),
+		}, newTestValidatorSet(4), nil, nil, istanbul.EmptyPreparedCertificate(), nil),
+	}
+	p := validator.New(common.BytesToAddress([]byte("12345667890")), []byte{})
+	prepare := func(seq, round int64) *istanbul.Message {
+		payload, _ := Encode(&istanbul.Subject{
+			View:   &istanbul.View{Sequence: big.NewInt(seq), Round: big.NewInt(round)},
+			Digest: common.BytesToHash([]byte("1234567890")),
+		})
+		return &istanbul.Message{Code: istanbul.MsgPrepare, Msg: payload, Address: p.Address()}
+	}
+	sizeOf := func() int {
+		if backlog := c.backlogs[p.Address()]; backlog != nil {
+			return backlog.Size()
+		}
+		return 0
+	}
+
+	// Messages too far ahead of the current view are dropped
+	c.storeBacklog(prepare(16, 0), p)
+	c.storeBacklog(prepare(10, 4), p)
+	c.storeBacklog(prepare(11, 3), p)
+	if size := sizeOf(); size != 0 {
+		t.Fatalf("backlog size mismatch: have %d, want 0", size)
+	}
+
+	// A full backlog evicts the furthest messages to keep the nearest ones
+	far, near := prepare(15, 0), prepare(10, 3)
+	c.storeBacklog(far, p)
+	c.storeBacklog(prepare(12, 0), p)
+	c.storeBacklog(prepare(11, 0), p)
+	c.storeBacklog(near, p)
+	c.storeBacklog(prepare(14, 0), p)
+	if size := sizeOf(); size != 3 {
+		t.Fatalf("backlog size mismatch: have %d, want 3", size)
+	}
+	if msg := c.backlogs[p.Address()].PopItem(); !reflect.DeepEqual(msg, near) {
+		t.Errorf("nearest message mismatch: have %v, want %v", msg, near)
+	}
+	for !c.backlogs[p.Address()].Empty() {
+		if msg := c.backlogs[p.Address()].PopItem(); reflect.DeepEqual(msg, far) {
+			t.Errorf("furthest message not evicted")
+		}
+	}
+
+	// The limit applies per source, even across the validators of different
+	// validator sets, and doesn't affect other sources
+	c.storeBacklog(prepare(11, 0), p)
+	c.storeBacklog(prepare(12, 0), validator.New(p.Address(), []byte{}))
+	c.storeBacklog(prepare(13, 0), validator.New(p.Address(), []byte{}))
+	c.storeBacklog(prepare(11, 1), validator.New(p.Address(), []byte{}))
+	if size := sizeOf(); size != 3 {
+		t.Fatalf("backlog size mismatch: have %d, want 3", size)
+	}
+	other := validator.New(common.BytesToAddress([]byte("other")), []byte{})
+	msg := prepare(13, 0)
+	msg.Address = other.Address()
+	c.storeBacklog(msg, other)
+	if size := c.backlogs[other.Address()].Size(); size != 1 {
+		t.Fatalf("other backlog size mismatch: have %d, want 1", size)
+	}
+	if size := sizeOf(); size != 3 {
+		t.Fatalf("backlog size mismatch: have %d, want 3", size)
+	}
+}
+
 func TestProcessFutureBacklog(t *testing.T) {
 	backend := &testSystemBackend{
 		events: new(event.TypeMux),
 	}
 	testLogger.SetHandler(elog.StdoutHandler)
 	c := &core{
+		config:     istanbul.DefaultConfig,
 		logger:     testLogger,
-		backlogs:   make(map[istanbul.Validator]*prque.Prque),
+		backlogs:   make(map[common.Address]*msgBacklog),
 		backlogsMu: new(sync.Mutex),
 		backend:    backend,
 		current: newRoundState(&istanbul.View{
@@ -421,8 +506,9 @@ func testProcessBacklog(t *testing.T, msg *istanbul.Message) {
 	}
 	testLogger.SetHandler(elog.StdoutHandler)
 	c := &core{
+		config:     istanbul.DefaultConfig,
 		logger:     testLogger,
-		backlogs:   make(map[istanbul.Validator]*prque.Prque),
+		backlogs:   make(map[common.Address]*msgBacklog),
 		backlogsMu: new(sync.Mutex),
 		backend:    backend,
 		state:      State(msg.Code),
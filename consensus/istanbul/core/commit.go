@@ -84,6 +84,7 @@ func (c *core) handleCommit(msg *istanbul.Message) error {
 	if err := c.checkMessage(istanbul.MsgCommit, commit.Subject.View); err != nil {
 		return err
 	}
+	c.recordMessage(msg, commit.Subject.View, &commit.Subject.Digest)
 
 	// Valid commit messages may be for the current, or previous sequence. We compare against our
 	// current view to find out which.
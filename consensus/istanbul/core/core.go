@@ -29,13 +29,14 @@ import (
 
 	"github.com/ethereum/go-ethereum/core/types"
 	blscrypto "github.com/ethereum/go-ethereum/crypto/bls"
+	"github.com/ethereum/go-ethereum/ethdb"
 	"github.com/ethereum/go-ethereum/event"
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/metrics"
 )
 
 // New creates an Istanbul consensus core
-func New(backend istanbul.Backend, config *istanbul.Config) Engine {
+func New(backend istanbul.Backend, config *istanbul.Config, db ethdb.Database) Engine {
 	c := &core{
 		config:             config,
 		address:            backend.Address(),
@@ -43,7 +44,7 @@ func New(backend istanbul.Backend, config *istanbul.Config) Engine {
 		handlerWg:          new(sync.WaitGroup),
 		logger:             log.New("address", backend.Address()),
 		backend:            backend,
-		backlogs:           make(map[istanbul.Validator]*prque.Prque),
+		backlogs:           make(map[common.Address]*msgBacklog),
 		backlogsMu:         new(sync.Mutex),
 		pendingRequests:    prque.New(nil),
 		pendingRequestsMu:  new(sync.Mutex),
@@ -51,6 +52,8 @@ func New(backend istanbul.Backend, config *istanbul.Config) Engine {
 		roundMeter:         metrics.NewRegisteredMeter("consensus/istanbul/core/round", nil),
 		sequenceMeter:      metrics.NewRegisteredMeter("consensus/istanbul/core/sequence", nil),
 		consensusTimer:     metrics.NewRegisteredTimer("consensus/istanbul/core/consensus", nil),
+		timelines:          newTimelineStore(),
+		lastSigned:         newLastSignedStore(db),
 	}
 	c.validateFn = c.checkValidatorSignature
 	return c
@@ -73,11 +76,12 @@ type core struct {
 	valSet     istanbul.ValidatorSet
 	validateFn func([]byte, []byte) (common.Address, error)
 
-	backlogs   map[istanbul.Validator]*prque.Prque
+	backlogs   map[common.Address]*msgBacklog
 	backlogsMu *sync.Mutex
 
 	current   RoundState
 	handlerWg *sync.WaitGroup
+	handlerMu sync.Mutex // Held while handling an event, so the address doesn't change mid-event
 
 	roundChangeSet   *roundChangeSet
 	roundChangeTimer *time.Timer
@@ -92,6 +96,12 @@ type core struct {
 	sequenceMeter metrics.Meter
 	// the timer to record consensus duration (from accepting a preprepare to final committed stage)
 	consensusTimer metrics.Timer
+
+	// per sequence record of received messages, timeouts and round changes
+	timelines *timelineStore
+
+	// last signed message of each type, persisted to avoid equivocation after a restart
+	lastSigned *lastSignedStore
 }
 
 // Appends the current view and state to the given context.
@@ -109,7 +119,12 @@ func (c *core) newLogger(ctx ...interface{}) log.Logger {
 	return tmp.New("cur_seq", seq, "cur_round", round, "state", state, "address", c.address)
 }
 
+// SetAddress switches the address of the validator's signing key, in between two
+// events handled by the core.
 func (c *core) SetAddress(address common.Address) {
+	c.handlerMu.Lock()
+	defer c.handlerMu.Unlock()
+
 	c.address = address
 	c.logger = log.New("address", address)
 }
@@ -134,6 +149,18 @@ func (c *core) finalizeMessage(msg *istanbul.Message) ([]byte, error) {
 func (c *core) broadcast(msg *istanbul.Message) {
 	logger := c.logger.New("state", c.state, "cur_round", c.current.Round(), "cur_seq", c.current.Sequence())
 
+	// A replica follows consensus without ever signing messages
+	if !c.backend.IsPrimaryForSeq(c.current.Sequence()) {
+		logger.Trace("Not broadcasting message as replica", "msg", msg)
+		return
+	}
+
+	// Refuse to sign a message conflicting with one signed before a restart
+	if err := c.checkSigned(msg); err != nil {
+		logger.Error("Refusing to sign message", "msg", msg, "err", err)
+		return
+	}
+
 	payload, err := c.finalizeMessage(msg)
 	if err != nil {
 		logger.Error("Failed to finalize message", "msg", msg, "err", err)
@@ -161,6 +188,7 @@ func (c *core) commit() {
 			c.sendNextRoundChange()
 			return
 		}
+		c.recordCommitted(c.current.View(), proposal.Hash())
 	}
 }
 
@@ -314,6 +342,8 @@ func (c *core) startNewRound(round *big.Int) {
 			logger.Error("Unable to produce round change certificate", "err", err, "new_round", round)
 			return
 		}
+		c.roundMeter.Mark(1)
+		c.recordRoundChangeCertificate(newView, roundChangeCertificate)
 	} else {
 		if c.current != nil {
 			request = c.current.PendingRequest()
@@ -322,6 +352,7 @@ func (c *core) startNewRound(round *big.Int) {
 			Sequence: new(big.Int).Add(headBlock.Number(), common.Big1),
 			Round:    new(big.Int),
 		}
+		c.backend.UpdateReplicaState(newView.Sequence)
 		c.valSet = c.backend.Validators(headBlock)
 		c.roundChangeSet = newRoundChangeSet(c.valSet)
 	}
@@ -332,6 +363,9 @@ func (c *core) startNewRound(round *big.Int) {
 	c.updateRoundState(newView, c.valSet, roundChange)
 	// Calculate new proposer
 	c.valSet.CalcProposer(headAuthor, newView.Round.Uint64())
+	if proposer := c.valSet.GetProposer(); proposer != nil {
+		c.recordNewRound(newView, proposer.Address())
+	}
 	c.setState(StateAcceptRequest)
 	if roundChange && c.current != nil && c.isProposer() && request != nil {
 		c.sendPreprepare(request, roundChangeCertificate)
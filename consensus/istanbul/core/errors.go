@@ -31,6 +31,9 @@ var (
 	// errOldMessage is returned when the received message's view is earlier
 	// than current view.
 	errOldMessage = errors.New("old message")
+	// errConflictingSignature is returned when a message to be signed conflicts
+	// with a message the validator signed before.
+	errConflictingSignature = errors.New("message conflicts with a previously signed message")
 	// errInvalidMessage is returned when the message is malformed.
 	errInvalidMessage = errors.New("invalid message")
 	// errFailedDecodePreprepare is returned when the PRE-PREPARE message is malformed.
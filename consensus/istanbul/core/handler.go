@@ -33,7 +33,9 @@ func (c *core) ParentCommits() MessageSet {
 // Start implements core.Engine.Start
 func (c *core) Start() error {
 	// Start a new round from last sequence + 1
+	c.handlerMu.Lock()
 	c.startNewRound(common.Big0)
+	c.handlerMu.Unlock()
 
 	// Tests will handle events itself, so we have to make subscribeEvents()
 	// be able to call in test.
@@ -45,11 +47,11 @@ func (c *core) Start() error {
 
 // Stop implements core.Engine.Stop
 func (c *core) Stop() error {
-	c.stopTimer()
 	c.unsubscribeEvents()
 
-	// Make sure the handler goroutine exits
+	// Make sure the handler goroutine exits before stopping the timers it sets
 	c.handlerWg.Wait()
+	c.stopTimer()
 	return nil
 }
 
@@ -98,6 +100,7 @@ func (c *core) handleEvents() {
 			if !ok {
 				return
 			}
+			c.handlerMu.Lock()
 			// A real event arrived, process interesting content
 			switch ev := event.Data.(type) {
 			case istanbul.RequestEvent:
@@ -118,22 +121,27 @@ func (c *core) handleEvents() {
 					c.logger.Warn("Error in handling istanbul message that was sent from a backlog event", "err", err)
 				}
 			}
+			c.handlerMu.Unlock()
 		case event, ok := <-c.timeoutSub.Chan():
 			if !ok {
 				return
 			}
+			c.handlerMu.Lock()
 			switch ev := event.Data.(type) {
 			case timeoutEvent:
 				c.handleTimeoutMsg(ev.view)
 			}
+			c.handlerMu.Unlock()
 		case event, ok := <-c.finalCommittedSub.Chan():
 			if !ok {
 				return
 			}
+			c.handlerMu.Lock()
 			switch event.Data.(type) {
 			case istanbul.FinalCommittedEvent:
 				c.handleFinalCommitted()
 			}
+			c.handlerMu.Unlock()
 		}
 	}
 }
@@ -194,6 +202,7 @@ func (c *core) handleCheckedMsg(msg *istanbul.Message, src istanbul.Validator) e
 func (c *core) handleTimeoutMsg(timeoutView *istanbul.View) {
 	logger := c.newLogger("func", "handleTimeoutMsg", "round", timeoutView.Round)
 	logger.Trace("Timed out, trying to wait for next round")
+	c.recordTimeout(timeoutView)
 
 	nextRound := new(big.Int).Add(timeoutView.Round, common.Big1)
 	c.waitForDesiredRound(nextRound)
@@ -0,0 +1,150 @@
+// Copyright 2017 The Celo Authors
+// This file is part of the celo library.
+//
+// The celo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The celo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the celo library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/istanbul"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+const dbKeyLastSignedPrefix = "istanbul-last-signed-" // dbKeyLastSignedPrefix + message code -> lastSigned
+
+// lastSigned is the view and digest of the last message of a type the validator
+// signed.
+type lastSigned struct {
+	View   *istanbul.View
+	Digest common.Hash
+}
+
+// lastSignedStore keeps the view and digest of the last PRE-PREPARE, PREPARE and
+// COMMIT message the validator signed, and persists them before the message is
+// broadcast. A validator that crashed and restarted in the same sequence uses
+// them to refuse signing a message that conflicts with one it already sent.
+type lastSignedStore struct {
+	db      ethdb.Database
+	lock    sync.Mutex
+	entries map[uint64]*lastSigned
+}
+
+func newLastSignedStore(db ethdb.Database) *lastSignedStore {
+	return &lastSignedStore{
+		db:      db,
+		entries: make(map[uint64]*lastSigned),
+	}
+}
+
+func lastSignedKey(code uint64) []byte {
+	return append([]byte(dbKeyLastSignedPrefix), byte(code))
+}
+
+// get returns the last signed message of the given type, loading it from the
+// database if needed. The lock must be held.
+func (s *lastSignedStore) get(code uint64) *lastSigned {
+	if entry, ok := s.entries[code]; ok {
+		return entry
+	}
+	var entry *lastSigned
+	if s.db != nil {
+		if enc, err := s.db.Get(lastSignedKey(code)); err == nil {
+			entry = new(lastSigned)
+			if err := rlp.DecodeBytes(enc, entry); err != nil {
+				entry = nil
+			}
+		}
+	}
+	s.entries[code] = entry
+	return entry
+}
+
+// check verifies that signing the message of the given type with the given view
+// and digest doesn't conflict with the last signed one, and records it if so.
+// Messages for a lower round of the same sequence, or for the same view with a
+// different digest, conflict. Messages for a lower sequence are only allowed for
+// blocks that were already committed, as the COMMIT resent for an old block.
+func (s *lastSignedStore) check(code uint64, view *istanbul.View, digest common.Hash, committed func(common.Hash, *istanbul.View) bool) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if last := s.get(code); last != nil {
+		switch seq := view.Sequence.Cmp(last.View.Sequence); {
+		case seq < 0:
+			if !committed(digest, view) {
+				return errConflictingSignature
+			}
+			return nil
+		case seq == 0:
+			round := view.Round.Cmp(last.View.Round)
+			if round < 0 || (round == 0 && digest != last.Digest) {
+				return errConflictingSignature
+			}
+			if round == 0 {
+				return nil
+			}
+		}
+	}
+	entry := &lastSigned{View: view, Digest: digest}
+	if s.db != nil {
+		enc, err := rlp.EncodeToBytes(entry)
+		if err != nil {
+			return err
+		}
+		if err := s.db.Put(lastSignedKey(code), enc); err != nil {
+			return err
+		}
+	}
+	s.entries[code] = entry
+	return nil
+}
+
+// checkSigned ensures the message doesn't conflict with a message the validator
+// signed before, and persists its view and digest before it gets signed.
+func (c *core) checkSigned(msg *istanbul.Message) error {
+	var (
+		view   *istanbul.View
+		digest common.Hash
+	)
+	switch msg.Code {
+	case istanbul.MsgPreprepare:
+		var preprepare *istanbul.Preprepare
+		if err := msg.Decode(&preprepare); err != nil {
+			return errFailedDecodePreprepare
+		}
+		view, digest = preprepare.View, preprepare.Proposal.Hash()
+	case istanbul.MsgPrepare:
+		var prepare *istanbul.Subject
+		if err := msg.Decode(&prepare); err != nil {
+			return errFailedDecodePrepare
+		}
+		view, digest = prepare.View, prepare.Digest
+	case istanbul.MsgCommit:
+		var commit *istanbul.CommittedSubject
+		if err := msg.Decode(&commit); err != nil {
+			return errFailedDecodeCommit
+		}
+		view, digest = commit.Subject.View, commit.Subject.Digest
+	default:
+		// ROUND CHANGE messages don't commit to a proposal
+		return nil
+	}
+	return c.lastSigned.check(msg.Code, view, digest, func(hash common.Hash, view *istanbul.View) bool {
+		return c.backend.HasBlock(hash, view.Sequence)
+	})
+}
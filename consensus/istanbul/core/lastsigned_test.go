@@ -0,0 +1,99 @@
+// Copyright 2017 The Celo Authors
+// This file is part of the celo library.
+//
+// The celo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The celo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the celo library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/istanbul"
+	"github.com/ethereum/go-ethereum/ethdb"
+)
+
+func TestLastSignedStore(t *testing.T) {
+	var (
+		db        = ethdb.NewMemDatabase()
+		store     = newLastSignedStore(db)
+		digestA   = common.Hash{0xa}
+		digestB   = common.Hash{0xb}
+		committed = func(hash common.Hash, view *istanbul.View) bool { return hash == digestA }
+		view      = func(seq, round int64) *istanbul.View {
+			return &istanbul.View{Sequence: big.NewInt(seq), Round: big.NewInt(round)}
+		}
+	)
+	if err := store.check(istanbul.MsgPrepare, view(2, 1), digestA, committed); err != nil {
+		t.Fatalf("failed to sign prepare: %v", err)
+	}
+	// Crash and restart the validator in the middle of the round
+	store = newLastSignedStore(db)
+
+	tests := []struct {
+		code   uint64
+		view   *istanbul.View
+		digest common.Hash
+		err    error
+	}{
+		{istanbul.MsgPrepare, view(2, 1), digestA, nil},                     // Same message again
+		{istanbul.MsgPrepare, view(2, 1), digestB, errConflictingSignature}, // Same view, other proposal
+		{istanbul.MsgPrepare, view(2, 0), digestA, errConflictingSignature}, // Lower round
+		{istanbul.MsgPrepare, view(1, 3), digestA, nil},                     // Committed block of a lower sequence
+		{istanbul.MsgPrepare, view(1, 3), digestB, errConflictingSignature}, // Uncommitted block of a lower sequence
+		{istanbul.MsgCommit, view(2, 0), digestB, nil},                      // Other message types are tracked apart
+		{istanbul.MsgPrepare, view(2, 2), digestB, nil},                     // Higher round
+		{istanbul.MsgPrepare, view(2, 1), digestA, errConflictingSignature}, // Round lower than the last signed one
+	}
+	for i, tt := range tests {
+		if err := store.check(tt.code, tt.view, tt.digest, committed); err != tt.err {
+			t.Errorf("test %d: error mismatch: have %v, want %v", i, err, tt.err)
+		}
+	}
+}
+
+func TestBroadcastAfterRestart(t *testing.T) {
+	sys := NewTestSystemWithBackend(4, 1)
+	closer := sys.Run(false)
+	defer closer()
+
+	v0 := sys.backends[0]
+	c := v0.engine.(*core)
+	view := &istanbul.View{Sequence: big.NewInt(1), Round: big.NewInt(0)}
+	setProposal := func(c *core, proposal istanbul.Proposal) {
+		c.current = newRoundState(view, c.valSet, &istanbul.Preprepare{View: view, Proposal: proposal}, nil, istanbul.EmptyPreparedCertificate(), nil)
+	}
+	setProposal(c, makeBlock(1))
+	c.sendPrepare()
+	if len(v0.sentMsgs) != 1 {
+		t.Fatalf("prepare not sent: have %d messages", len(v0.sentMsgs))
+	}
+
+	// Crash the validator mid-round, and restart it with a different proposal
+	restarted := New(v0, c.config, v0.db).(*core)
+	restarted.valSet = c.valSet
+	setProposal(restarted, makeBlock(2))
+	restarted.sendPrepare()
+	if len(v0.sentMsgs) != 1 {
+		t.Fatalf("conflicting prepare sent after restart: have %d messages", len(v0.sentMsgs))
+	}
+
+	// Resending the prepare signed before the crash is fine
+	setProposal(restarted, makeBlock(1))
+	restarted.sendPrepare()
+	if len(v0.sentMsgs) != 2 {
+		t.Fatalf("prepare not resent after restart: have %d messages", len(v0.sentMsgs))
+	}
+}
@@ -141,6 +141,7 @@ func (c *core) handlePrepare(msg *istanbul.Message) error {
 	if err := c.checkMessage(istanbul.MsgPrepare, prepare.View); err != nil {
 		return err
 	}
+	c.recordMessage(msg, prepare.View, &prepare.Digest)
 
 	if err := c.verifyPrepare(prepare); err != nil {
 		return err
@@ -102,6 +102,8 @@ func (c *core) handlePreprepare(msg *istanbul.Message) error {
 		logger.Trace("Check pre-prepare failed", "cur_round", c.current.Round(), "err", err)
 		return err
 	}
+	digest := preprepare.Proposal.Hash()
+	c.recordMessage(msg, preprepare.View, &digest)
 
 	// Check if the message comes from current proposer
 	if !c.valSet.IsProposer(msg.Address) {
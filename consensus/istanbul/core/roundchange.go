@@ -144,6 +144,8 @@ func (c *core) handleRoundChangeCertificate(proposal istanbul.Subject, roundChan
 		return errInvalidPreparedCertificateDigestMismatch
 	}
 
+	c.recordRoundChangeCertificate(proposal.View, roundChangeCertificate)
+
 	// May have already moved to this round based on quorum round change messages.
 	logger.Trace("Trying to move to round change certificate's round", "target round", proposal.View.Round)
 	c.startNewRound(proposal.View.Round)
@@ -166,6 +168,7 @@ func (c *core) handleRoundChange(msg *istanbul.Message) error {
 		logger.Info("Check round change message failed", "err", err)
 		return err
 	}
+	c.recordMessage(msg, rc.View, nil)
 
 	// Verify the PREPARED certificate if present.
 	if rc.HasPreparedCertificate() {
@@ -23,13 +23,12 @@ import (
 	"testing"
 	"time"
 
-	bls "github.com/celo-org/bls-zexe/go"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/consensustest"
 	"github.com/ethereum/go-ethereum/consensus/istanbul"
 	"github.com/ethereum/go-ethereum/consensus/istanbul/validator"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/crypto"
-	blscrypto "github.com/ethereum/go-ethereum/crypto/bls"
 	"github.com/ethereum/go-ethereum/ethdb"
 	"github.com/ethereum/go-ethereum/event"
 	elog "github.com/ethereum/go-ethereum/log"
@@ -104,14 +103,7 @@ func (self *testSystemBackend) Gossip(validators []common.Address, message []byt
 }
 
 func (self *testSystemBackend) SignBlockHeader(data []byte) ([]byte, error) {
-	privateKey, _ := bls.DeserializePrivateKey(self.blsKey)
-	defer privateKey.Destroy()
-
-	signature, _ := privateKey.SignMessage(data, []byte{}, false)
-	defer signature.Destroy()
-	signatureBytes, _ := signature.Serialize()
-
-	return signatureBytes, nil
+	return consensustest.SignBLSMessage(self.blsKey, data)
 }
 
 func (self *testSystemBackend) Commit(proposal istanbul.Proposal, aggregatedSeal types.IstanbulAggregatedSeal) error {
@@ -284,6 +276,12 @@ func (self *testSystemBackend) Enode() *enode.Node {
 
 func (self *testSystemBackend) RefreshValPeers(valSet istanbul.ValidatorSet) {}
 
+func (self *testSystemBackend) IsPrimaryForSeq(seq *big.Int) bool {
+	return true
+}
+
+func (self *testSystemBackend) UpdateReplicaState(seq *big.Int) {}
+
 // ==============================================
 //
 // define the struct that need to be provided for integration tests.
@@ -312,20 +310,7 @@ func newTestSystem(n uint64, f uint64, keys [][]byte) *testSystem {
 }
 
 func generateValidators(n int) ([]istanbul.ValidatorData, [][]byte, []*ecdsa.PrivateKey) {
-	vals := make([]istanbul.ValidatorData, 0)
-	blsKeys := make([][]byte, 0)
-	keys := make([]*ecdsa.PrivateKey, 0)
-	for i := 0; i < n; i++ {
-		privateKey, _ := crypto.GenerateKey()
-		blsPrivateKey, _ := blscrypto.ECDSAToBLS(privateKey)
-		blsPublicKey, _ := blscrypto.PrivateToPublic(blsPrivateKey)
-		vals = append(vals, istanbul.ValidatorData{
-			crypto.PubkeyToAddress(privateKey.PublicKey),
-			blsPublicKey,
-		})
-		keys = append(keys, privateKey)
-		blsKeys = append(blsKeys, blsPrivateKey)
-	}
+	vals, blsKeys, keys, _ := consensustest.GenerateValidators(n)
 	return vals, blsKeys, keys
 }
 
@@ -359,7 +344,7 @@ func NewTestSystemWithBackendAndCurrentRoundState(n, f uint64, getRoundState fun
 		backend.key = *keys[i]
 		backend.blsKey = blsKeys[i]
 
-		core := New(backend, config).(*core)
+		core := New(backend, config, backend.db).(*core)
 		core.state = StateAcceptRequest
 		core.current = getRoundState(vset)
 		core.roundChangeSet = newRoundChangeSet(vset)
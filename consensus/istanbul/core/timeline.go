@@ -0,0 +1,227 @@
+// Copyright 2017 The Celo Authors
+// This file is part of the celo library.
+//
+// The celo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The celo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the celo library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/istanbul"
+	"github.com/ethereum/go-ethereum/metrics"
+)
+
+const (
+	// maxTimelineSequences is the number of sequences for which a timeline is kept.
+	maxTimelineSequences = 128
+	// maxTimelineEntries is the maximum number of entries recorded for a single sequence.
+	maxTimelineEntries = 2048
+)
+
+// Timeline event types
+const (
+	TimelinePreprepare             = "preprepare"
+	TimelinePrepare                = "prepare"
+	TimelineCommit                 = "commit"
+	TimelineRoundChange            = "roundChange"
+	TimelineTimeout                = "timeout"
+	TimelineRoundChangeCertificate = "roundChangeCertificate"
+	TimelineNewRound               = "newRound"
+	TimelineCommitted              = "committed"
+)
+
+var (
+	preprepareArrivalMeter  = metrics.NewRegisteredMeter("consensus/istanbul/core/timeline/preprepare", nil)
+	prepareArrivalMeter     = metrics.NewRegisteredMeter("consensus/istanbul/core/timeline/prepare", nil)
+	commitArrivalMeter      = metrics.NewRegisteredMeter("consensus/istanbul/core/timeline/commit", nil)
+	roundChangeArrivalMeter = metrics.NewRegisteredMeter("consensus/istanbul/core/timeline/roundchange", nil)
+	timeoutMeter            = metrics.NewRegisteredMeter("consensus/istanbul/core/timeline/timeout", nil)
+	roundChangeCertMeter    = metrics.NewRegisteredMeter("consensus/istanbul/core/timeline/roundchangecert", nil)
+	// committedRoundHistogram records the round in which each sequence was committed
+	committedRoundHistogram = metrics.NewRegisteredHistogram("consensus/istanbul/core/timeline/committedround", nil, metrics.NewExpDecaySample(1028, 0.015))
+)
+
+// TimelineEntry is a single consensus event observed while working on a sequence.
+type TimelineEntry struct {
+	Time    time.Time        `json:"time"`
+	Event   string           `json:"event"`
+	Round   uint64           `json:"round"`
+	From    *common.Address  `json:"from,omitempty"`
+	Digest  *common.Hash     `json:"digest,omitempty"`
+	Signers []common.Address `json:"signers,omitempty"`
+}
+
+// RoundTimeline is the ordered list of consensus events recorded for a sequence.
+type RoundTimeline struct {
+	Sequence  *big.Int        `json:"sequence"`
+	Truncated bool            `json:"truncated"`
+	Entries   []TimelineEntry `json:"entries"`
+}
+
+// timelineStore keeps a bounded number of round timelines, evicting the lowest sequences first.
+type timelineStore struct {
+	timelines map[uint64]*RoundTimeline
+	mu        *sync.RWMutex
+}
+
+func newTimelineStore() *timelineStore {
+	return &timelineStore{
+		timelines: make(map[uint64]*RoundTimeline),
+		mu:        new(sync.RWMutex),
+	}
+}
+
+// add appends an entry to the timeline of the given sequence.
+func (ts *timelineStore) add(sequence *big.Int, entry TimelineEntry) {
+	if sequence == nil {
+		return
+	}
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+
+	seq := sequence.Uint64()
+	timeline, ok := ts.timelines[seq]
+	if !ok {
+		if len(ts.timelines) >= maxTimelineSequences {
+			lowest := seq
+			for s := range ts.timelines {
+				if s < lowest {
+					lowest = s
+				}
+			}
+			// Don't evict a newer sequence to make room for an older one
+			if lowest == seq {
+				return
+			}
+			delete(ts.timelines, lowest)
+		}
+		timeline = &RoundTimeline{Sequence: new(big.Int).SetUint64(seq)}
+		ts.timelines[seq] = timeline
+	}
+
+	if len(timeline.Entries) >= maxTimelineEntries {
+		timeline.Truncated = true
+		return
+	}
+	timeline.Entries = append(timeline.Entries, entry)
+}
+
+// get returns a copy of the timeline of the given sequence, or nil if none was recorded.
+func (ts *timelineStore) get(sequence *big.Int) *RoundTimeline {
+	ts.mu.RLock()
+	defer ts.mu.RUnlock()
+
+	timeline, ok := ts.timelines[sequence.Uint64()]
+	if !ok {
+		return nil
+	}
+	entries := make([]TimelineEntry, len(timeline.Entries))
+	copy(entries, timeline.Entries)
+	return &RoundTimeline{
+		Sequence:  new(big.Int).Set(timeline.Sequence),
+		Truncated: timeline.Truncated,
+		Entries:   entries,
+	}
+}
+
+// RoundTimeline implements core.Engine.RoundTimeline
+func (c *core) RoundTimeline(sequence *big.Int) *RoundTimeline {
+	return c.timelines.get(sequence)
+}
+
+// recordMessage records the arrival of a consensus message from a validator. It is
+// called by the message handlers with the view and digest they decoded, once the
+// view of the message was accepted, so that messages for arbitrary sequences can't
+// take the place of the timelines of the sequences actually worked on.
+func (c *core) recordMessage(msg *istanbul.Message, view *istanbul.View, digest *common.Hash) {
+	var event string
+	switch msg.Code {
+	case istanbul.MsgPreprepare:
+		preprepareArrivalMeter.Mark(1)
+		event = TimelinePreprepare
+	case istanbul.MsgPrepare:
+		prepareArrivalMeter.Mark(1)
+		event = TimelinePrepare
+	case istanbul.MsgCommit:
+		commitArrivalMeter.Mark(1)
+		event = TimelineCommit
+	case istanbul.MsgRoundChange:
+		roundChangeArrivalMeter.Mark(1)
+		event = TimelineRoundChange
+	default:
+		return
+	}
+
+	if view == nil || view.Sequence == nil || view.Round == nil {
+		return
+	}
+	from := msg.Address
+	c.timelines.add(view.Sequence, TimelineEntry{
+		Time:   time.Now(),
+		Event:  event,
+		Round:  view.Round.Uint64(),
+		From:   &from,
+		Digest: digest,
+	})
+}
+
+// recordTimeout records the expiry of the round timer for the given view.
+func (c *core) recordTimeout(view *istanbul.View) {
+	timeoutMeter.Mark(1)
+	c.timelines.add(view.Sequence, TimelineEntry{
+		Time:  time.Now(),
+		Event: TimelineTimeout,
+		Round: view.Round.Uint64(),
+	})
+}
+
+// recordRoundChangeCertificate records a round change certificate formed or accepted for the given view.
+func (c *core) recordRoundChangeCertificate(view *istanbul.View, certificate istanbul.RoundChangeCertificate) {
+	roundChangeCertMeter.Mark(1)
+	signers := make([]common.Address, 0, len(certificate.RoundChangeMessages))
+	for _, message := range certificate.RoundChangeMessages {
+		signers = append(signers, message.Address)
+	}
+	c.timelines.add(view.Sequence, TimelineEntry{
+		Time:    time.Now(),
+		Event:   TimelineRoundChangeCertificate,
+		Round:   view.Round.Uint64(),
+		Signers: signers,
+	})
+}
+
+// recordNewRound records the start of a new round along with its proposer.
+func (c *core) recordNewRound(view *istanbul.View, proposer common.Address) {
+	c.timelines.add(view.Sequence, TimelineEntry{
+		Time:  time.Now(),
+		Event: TimelineNewRound,
+		Round: view.Round.Uint64(),
+		From:  &proposer,
+	})
+}
+
+// recordCommitted records that the proposal for the given view was committed.
+func (c *core) recordCommitted(view *istanbul.View, digest common.Hash) {
+	committedRoundHistogram.Update(view.Round.Int64())
+	c.timelines.add(view.Sequence, TimelineEntry{
+		Time:   time.Now(),
+		Event:  TimelineCommitted,
+		Round:  view.Round.Uint64(),
+		Digest: &digest,
+	})
+}
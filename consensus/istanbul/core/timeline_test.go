@@ -0,0 +1,113 @@
+// Copyright 2017 The Celo Authors
+// This file is part of the celo library.
+//
+// The celo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The celo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the celo library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/istanbul"
+)
+
+func TestTimelineStoreEviction(t *testing.T) {
+	ts := newTimelineStore()
+
+	for i := 1; i <= maxTimelineSequences+10; i++ {
+		ts.add(big.NewInt(int64(i)), TimelineEntry{Event: TimelineNewRound})
+	}
+
+	if len(ts.timelines) != maxTimelineSequences {
+		t.Errorf("number of timelines mismatch: have %v, want %v", len(ts.timelines), maxTimelineSequences)
+	}
+	for i := 1; i <= 10; i++ {
+		if ts.get(big.NewInt(int64(i))) != nil {
+			t.Errorf("timeline for sequence %v should have been evicted", i)
+		}
+	}
+	if ts.get(big.NewInt(maxTimelineSequences+10)) == nil {
+		t.Errorf("timeline for the latest sequence should be kept")
+	}
+
+	// An older sequence must not evict newer ones
+	ts.add(big.NewInt(1), TimelineEntry{Event: TimelineNewRound})
+	if ts.get(big.NewInt(1)) != nil {
+		t.Errorf("timeline for an older sequence should not be added when the store is full")
+	}
+}
+
+func TestTimelineStoreTruncation(t *testing.T) {
+	ts := newTimelineStore()
+	seq := big.NewInt(5)
+
+	for i := 0; i < maxTimelineEntries+1; i++ {
+		ts.add(seq, TimelineEntry{Event: TimelinePrepare, Round: uint64(i)})
+	}
+
+	timeline := ts.get(seq)
+	if timeline == nil {
+		t.Fatalf("timeline for sequence %v is missing", seq)
+	}
+	if len(timeline.Entries) != maxTimelineEntries {
+		t.Errorf("number of entries mismatch: have %v, want %v", len(timeline.Entries), maxTimelineEntries)
+	}
+	if !timeline.Truncated {
+		t.Errorf("timeline should be marked as truncated")
+	}
+
+	// The returned timeline must be a copy
+	timeline.Entries[0].Round = 1000
+	if ts.get(seq).Entries[0].Round != 0 {
+		t.Errorf("modifying the returned timeline should not modify the store")
+	}
+}
+
+func TestTimelineRecordsAcceptedViewsOnly(t *testing.T) {
+	sys := NewTestSystemWithBackend(4, 1)
+	r0 := sys.backends[0].engine.(*core)
+	r0.current = newTestRoundState(&istanbul.View{Round: big.NewInt(0), Sequence: big.NewInt(1)}, r0.valSet)
+	r0.state = StatePreprepared
+	v1 := sys.backends[1]
+
+	// A prepare of the current view is recorded
+	current := *r0.current.View()
+	msg, err := v1.getPrepareMessage(current, r0.current.Proposal().Hash())
+	if err != nil {
+		t.Fatalf("failed to create prepare message: %v", err)
+	}
+	payload, _ := msg.Payload()
+	r0.handleMsg(payload)
+	timeline := r0.RoundTimeline(current.Sequence)
+	if timeline == nil || len(timeline.Entries) != 1 || timeline.Entries[0].Event != TimelinePrepare {
+		t.Errorf("prepare of the current view not recorded: %v", timeline)
+	}
+
+	// Far future sequences are backlogged, and must not take the place of the
+	// timelines of the sequences worked on
+	for i := 0; i < maxTimelineSequences; i++ {
+		future := istanbul.View{Sequence: big.NewInt(int64(1000 + i)), Round: big.NewInt(0)}
+		msg, err := v1.getPrepareMessage(future, common.Hash{})
+		if err != nil {
+			t.Fatalf("failed to create prepare message: %v", err)
+		}
+		payload, _ := msg.Payload()
+		r0.handleMsg(payload)
+		if r0.RoundTimeline(future.Sequence) != nil {
+			t.Fatalf("timeline recorded for the future sequence %v", future.Sequence)
+		}
+	}
+}
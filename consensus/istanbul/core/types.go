@@ -17,6 +17,8 @@
 package core
 
 import (
+	"math/big"
+
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/consensus/istanbul"
 	"github.com/ethereum/go-ethereum/rlp"
@@ -29,6 +31,8 @@ type Engine interface {
 	SetAddress(common.Address)
 	// Validator -> CommittedSeal from Parent Block
 	ParentCommits() MessageSet
+	// RoundTimeline returns the recorded consensus events for the given sequence, or nil if none are known
+	RoundTimeline(sequence *big.Int) *RoundTimeline
 }
 
 type State uint64
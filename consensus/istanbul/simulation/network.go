@@ -0,0 +1,143 @@
+// Copyright 2017 The celo Authors
+// This file is part of the celo library.
+//
+// The celo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The celo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the celo library. If not, see <http://www.gnu.org/licenses/>.
+
+package simulation
+
+import (
+	"math/rand"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/istanbul"
+)
+
+// NetworkConfig configures the delivery of the messages between the validators.
+type NetworkConfig struct {
+	Latency  time.Duration // Minimum delay of every message
+	Jitter   time.Duration // Maximum random delay added to the latency, reordering the messages
+	DropRate float64       // Probability of a message being lost, between 0 and 1
+}
+
+// Network delivers the consensus messages between the simulated validators.
+// Whether a message is delivered, and its delay, are decided when it's sent.
+type Network struct {
+	nodes map[common.Address]*Node
+
+	mu     sync.Mutex
+	config NetworkConfig
+	rand   *rand.Rand
+	groups map[common.Address][]int // Partitions of each validator, or nil if the network isn't partitioned
+
+	quit     chan struct{}
+	quitOnce sync.Once
+}
+
+func newNetwork(config NetworkConfig, seed int64) *Network {
+	return &Network{
+		nodes:  make(map[common.Address]*Node),
+		config: config,
+		rand:   rand.New(rand.NewSource(seed)),
+		quit:   make(chan struct{}),
+	}
+}
+
+// Configure changes the latency and loss of the messages sent from now on.
+func (nw *Network) Configure(config NetworkConfig) {
+	nw.mu.Lock()
+	defer nw.mu.Unlock()
+	nw.config = config
+}
+
+// Partition splits the network in the given groups of validators, which can only
+// exchange messages within their group. Validators left out of every group are
+// isolated, and the ones in several groups bridge them, as a Byzantine validator
+// reaching both sides of a partition would.
+func (nw *Network) Partition(groups ...[]*Node) {
+	nw.mu.Lock()
+	defer nw.mu.Unlock()
+
+	nw.groups = make(map[common.Address][]int)
+	for i, group := range groups {
+		for _, node := range group {
+			nw.groups[node.Address()] = append(nw.groups[node.Address()], i)
+		}
+	}
+}
+
+// Heal removes the partition of the network.
+func (nw *Network) Heal() {
+	nw.mu.Lock()
+	defer nw.mu.Unlock()
+	nw.groups = nil
+}
+
+// connected returns whether messages can go from one validator to the other.
+func (nw *Network) connected(from, to common.Address) bool {
+	if nw.groups == nil {
+		return true
+	}
+	for _, fromGroup := range nw.groups[from] {
+		for _, toGroup := range nw.groups[to] {
+			if fromGroup == toGroup {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// send schedules the delivery of the message to the given validator, unless the
+// network loses it. Conflicting messages of equivocating validators are counted
+// by the validators receiving them.
+func (nw *Network) send(from *Node, to common.Address, payload []byte, conflicting bool) {
+	nw.mu.Lock()
+	peer := nw.nodes[to]
+	if peer == nil || !nw.connected(from.Address(), to) || nw.rand.Float64() < nw.config.DropRate {
+		nw.mu.Unlock()
+		return
+	}
+	delay := nw.config.Latency
+	if nw.config.Jitter > 0 {
+		delay += time.Duration(nw.rand.Int63n(int64(nw.config.Jitter)))
+	}
+	nw.mu.Unlock()
+
+	time.AfterFunc(delay, func() { nw.deliver(from, peer, payload, conflicting) })
+}
+
+func (nw *Network) deliver(from, to *Node, payload []byte, conflicting bool) {
+	select {
+	case <-nw.quit:
+		return
+	default:
+	}
+	if conflicting {
+		atomic.AddUint64(&to.conflictingReceived, 1)
+	}
+	// Hearing from a peer further ahead lets the validator catch up, as block
+	// synchronisation would
+	to.syncFrom(from)
+	to.EventMux().Post(istanbul.MessageEvent{
+		Payload: payload,
+	})
+}
+
+// stop drops the messages still in flight.
+func (nw *Network) stop() {
+	nw.quitOnce.Do(func() { close(nw.quit) })
+}
@@ -0,0 +1,579 @@
+// Copyright 2017 The celo Authors
+// This file is part of the celo library.
+//
+// The celo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The celo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the celo library. If not, see <http://www.gnu.org/licenses/>.
+
+package simulation
+
+import (
+	"crypto/ecdsa"
+	"errors"
+	"math/big"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/consensustest"
+	"github.com/ethereum/go-ethereum/consensus/istanbul"
+	istanbulCore "github.com/ethereum/go-ethereum/consensus/istanbul/core"
+	"github.com/ethereum/go-ethereum/consensus/istanbul/validator"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	blscrypto "github.com/ethereum/go-ethereum/crypto/bls"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/event"
+)
+
+var (
+	// errInvalidProposal is returned if a proposal is not a block.
+	errInvalidProposal = errors.New("invalid proposal")
+	// errUnknownParent is returned if a proposal doesn't extend the head of the chain.
+	errUnknownParent = errors.New("proposal doesn't extend the head block")
+	// errInsufficientSeals is returned if an aggregated seal doesn't aggregate a quorum of seals.
+	errInsufficientSeals = errors.New("not enough committed seals")
+)
+
+// conflictingExtra is appended to the extra data of the blocks proposed by an
+// equivocating validator, to make them conflict with the original ones.
+var conflictingExtra = []byte("equivocation")
+
+// committedBlock is a block of a simulated chain.
+type committedBlock struct {
+	block *types.Block
+	seal  types.IstanbulAggregatedSeal // Seal of the quorum which committed the block, in its round
+}
+
+// Node is a simulated validator, running an istanbul core on top of an in-memory
+// chain. It implements istanbul.Backend, sending its messages through the
+// simulated network.
+type Node struct {
+	index     int
+	address   common.Address
+	key       *ecdsa.PrivateKey
+	blsKey    []byte
+	behaviour Behaviour
+
+	validators []istanbul.ValidatorData
+	config     *istanbul.Config
+	network    *Network
+	engine     istanbulCore.Engine
+	events     *event.TypeMux
+
+	mu        sync.RWMutex
+	chain     []*committedBlock
+	forks     []uint64                     // Heights at which a block conflicting with the chain was committed
+	conflicts map[common.Hash]*types.Block // Conflicting proposals of an equivocating validator, by original hash
+	view      istanbul.View                // View of the latest round change sent by the validator
+	changeCh  chan struct{}                // Closed when the chain or the view changes
+
+	conflictingSent     uint64 // Number of conflicting messages sent by an equivocating validator (atomic)
+	conflictingReceived uint64 // Number of conflicting messages received from equivocating validators (atomic)
+}
+
+func newNode(index int, key *ecdsa.PrivateKey, blsKey []byte, validators []istanbul.ValidatorData, config *istanbul.Config, behaviour Behaviour, network *Network) *Node {
+	n := &Node{
+		index:      index,
+		address:    crypto.PubkeyToAddress(key.PublicKey),
+		key:        key,
+		blsKey:     blsKey,
+		behaviour:  behaviour,
+		validators: validators,
+		config:     config,
+		network:    network,
+		events:     new(event.TypeMux),
+		chain:      []*committedBlock{{block: genesisBlock(), seal: types.IstanbulAggregatedSeal{Round: big.NewInt(0)}}},
+		conflicts:  make(map[common.Hash]*types.Block),
+		changeCh:   make(chan struct{}),
+	}
+	n.engine = istanbulCore.New(n, config, ethdb.NewMemDatabase())
+	return n
+}
+
+// genesisBlock returns the block all the simulated chains start from.
+func genesisBlock() *types.Block {
+	header := &types.Header{
+		Number:     big.NewInt(0),
+		Difficulty: big.NewInt(0),
+		Time:       big.NewInt(0),
+	}
+	return types.NewBlock(header, nil, nil, nil, nil)
+}
+
+// Index returns the index of the validator in the validator set.
+func (n *Node) Index() int {
+	return n.index
+}
+
+// Behaviour returns whether the validator is honest, or how it misbehaves.
+func (n *Node) Behaviour() Behaviour {
+	return n.behaviour
+}
+
+// Height returns the number of the last block committed or imported by the validator.
+func (n *Node) Height() uint64 {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+	return uint64(len(n.chain) - 1)
+}
+
+// BlockHash returns the hash of the validator's block at the given height, if
+// it has one.
+func (n *Node) BlockHash(number uint64) (common.Hash, bool) {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+	if number >= uint64(len(n.chain)) {
+		return common.Hash{}, false
+	}
+	return n.chain[number].block.Hash(), true
+}
+
+// Round returns the round the validator moved to at the height following its
+// head, after timing out in the previous ones.
+func (n *Node) Round() uint64 {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+	if n.view.Sequence == nil || n.view.Sequence.Uint64() != uint64(len(n.chain)) {
+		return 0
+	}
+	return n.view.Round.Uint64()
+}
+
+// Forks returns the heights at which the validator committed, or was given, a
+// block conflicting with its chain.
+func (n *Node) Forks() []uint64 {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+	return append([]uint64(nil), n.forks...)
+}
+
+// ConflictingSent returns the number of messages an equivocating validator sent
+// in conflict with the ones it sent to the rest of the validators.
+func (n *Node) ConflictingSent() uint64 {
+	return atomic.LoadUint64(&n.conflictingSent)
+}
+
+// ConflictingReceived returns the number of conflicting messages the validator
+// received from equivocating validators.
+func (n *Node) ConflictingReceived() uint64 {
+	return atomic.LoadUint64(&n.conflictingReceived)
+}
+
+// changed returns a channel closed once the chain or the view of the validator
+// changes.
+func (n *Node) changed() <-chan struct{} {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+	return n.changeCh
+}
+
+// notifyChange wakes up the ones waiting for a change of the validator. It must
+// be called with the lock held.
+func (n *Node) notifyChange() {
+	close(n.changeCh)
+	n.changeCh = make(chan struct{})
+}
+
+func (n *Node) start() error {
+	if err := n.engine.Start(); err != nil {
+		return err
+	}
+	n.requestProposal()
+	return nil
+}
+
+func (n *Node) stop() {
+	n.engine.Stop()
+	n.events.Stop()
+}
+
+func (n *Node) head() *committedBlock {
+	return n.chain[len(n.chain)-1]
+}
+
+// requestProposal asks the core to propose a block on top of the head, as the
+// miner does once a block is committed.
+func (n *Node) requestProposal() {
+	n.mu.RLock()
+	parent := n.head().block
+	n.mu.RUnlock()
+
+	header := &types.Header{
+		ParentHash: parent.Hash(),
+		Number:     new(big.Int).Add(parent.Number(), common.Big1),
+		Coinbase:   n.address,
+		Difficulty: big.NewInt(0),
+		Time:       big.NewInt(time.Now().Unix()),
+	}
+	go n.events.Post(istanbul.RequestEvent{
+		Proposal: types.NewBlock(header, nil, nil, nil, nil),
+	})
+}
+
+// blocksFrom returns the validator's blocks from the given height on.
+func (n *Node) blocksFrom(number uint64) []*committedBlock {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+	if number >= uint64(len(n.chain)) {
+		return nil
+	}
+	return append([]*committedBlock(nil), n.chain[number:]...)
+}
+
+// syncFrom imports the blocks the given peer has beyond the validator's head,
+// as long as they carry the committed seals of a quorum of validators.
+func (n *Node) syncFrom(peer *Node) {
+	if peer.Height() <= n.Height() {
+		return
+	}
+	blocks := peer.blocksFrom(n.Height() + 1)
+
+	n.mu.Lock()
+	imported := false
+	for _, b := range blocks {
+		if err := n.verifySeal(b); err != nil {
+			break
+		}
+		head, number := n.head(), b.block.NumberU64()
+		if number <= head.block.NumberU64() {
+			if n.chain[number].block.Hash() != b.block.Hash() {
+				n.forks = append(n.forks, number)
+			}
+			continue
+		}
+		if b.block.ParentHash() != head.block.Hash() {
+			n.forks = append(n.forks, head.block.NumberU64())
+			break
+		}
+		n.chain = append(n.chain, b)
+		imported = true
+	}
+	if imported {
+		n.notifyChange()
+	}
+	n.mu.Unlock()
+
+	if imported {
+		go n.events.Post(istanbul.FinalCommittedEvent{})
+		n.requestProposal()
+	}
+}
+
+// verifySeal checks that the block was committed by a quorum of validators, i.e.
+// that its aggregated seal aggregates the committed seals of a quorum.
+func (n *Node) verifySeal(b *committedBlock) error {
+	valSet := validator.NewSet(n.validators, n.config.ProposerPolicy)
+	publicKeys := [][]byte{}
+	for i := 0; i < valSet.Size(); i++ {
+		if b.seal.Bitmap != nil && b.seal.Bitmap.Bit(i) == 1 {
+			publicKeys = append(publicKeys, valSet.GetByIndex(uint64(i)).BLSPublicKey())
+		}
+	}
+	if len(publicKeys) < valSet.MinQuorumSize() {
+		return errInsufficientSeals
+	}
+	seal := istanbulCore.PrepareCommittedSeal(b.block.Hash(), b.seal.Round)
+	return blscrypto.VerifyAggregatedSignature(publicKeys, seal, []byte{}, b.seal.Signature, false)
+}
+
+// equivocate sends the given message to half of the validators, and a conflicting
+// one to the other half, if the message is about a proposal it can conflict with.
+func (n *Node) equivocate(validators []common.Address, payload []byte) error {
+	conflicting, err := n.conflictingPayload(payload)
+	if err != nil {
+		return err
+	}
+	for i, address := range validators {
+		if address == n.address {
+			continue
+		}
+		if conflicting != nil && i%2 == 1 {
+			atomic.AddUint64(&n.conflictingSent, 1)
+			n.network.send(n, address, conflicting, true)
+		} else {
+			n.network.send(n, address, payload, false)
+		}
+	}
+	return nil
+}
+
+// conflictingPayload returns a message conflicting with the given one, signed by
+// the validator, or nil if it can't make one.
+func (n *Node) conflictingPayload(payload []byte) ([]byte, error) {
+	msg := new(istanbul.Message)
+	if err := msg.FromPayload(payload, nil); err != nil {
+		return nil, err
+	}
+
+	var err error
+	switch msg.Code {
+	case istanbul.MsgPreprepare:
+		var preprepare *istanbul.Preprepare
+		if err := msg.Decode(&preprepare); err != nil {
+			return nil, err
+		}
+		preprepare.Proposal = n.conflictingProposal(preprepare.Proposal)
+		msg.Msg, err = istanbulCore.Encode(preprepare)
+	case istanbul.MsgPrepare:
+		var prepare *istanbul.Subject
+		if err := msg.Decode(&prepare); err != nil {
+			return nil, err
+		}
+		conflicting := n.conflictFor(prepare.Digest)
+		if conflicting == nil {
+			return nil, nil
+		}
+		prepare.Digest = conflicting.Hash()
+		msg.Msg, err = istanbulCore.Encode(prepare)
+	case istanbul.MsgCommit:
+		var commit *istanbul.CommittedSubject
+		if err := msg.Decode(&commit); err != nil {
+			return nil, err
+		}
+		conflicting := n.conflictFor(commit.Subject.Digest)
+		if conflicting == nil {
+			return nil, nil
+		}
+		commit.Subject.Digest = conflicting.Hash()
+		if commit.CommittedSeal, err = n.SignBlockHeader(istanbulCore.PrepareCommittedSeal(commit.Subject.Digest, commit.Subject.View.Round)); err != nil {
+			return nil, err
+		}
+		msg.Msg, err = istanbulCore.Encode(commit)
+	default:
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := msg.Sign(n.Sign); err != nil {
+		return nil, err
+	}
+	return msg.Payload()
+}
+
+// conflictingProposal returns a block conflicting with the given proposal.
+func (n *Node) conflictingProposal(proposal istanbul.Proposal) *types.Block {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	if conflicting, ok := n.conflicts[proposal.Hash()]; ok {
+		return conflicting
+	}
+	header := types.CopyHeader(proposal.Header())
+	header.Extra = append(header.Extra, conflictingExtra...)
+	conflicting := types.NewBlock(header, nil, nil, nil, nil)
+	n.conflicts[proposal.Hash()] = conflicting
+	return conflicting
+}
+
+// conflictFor returns the block proposed in conflict with the given one, if any.
+func (n *Node) conflictFor(hash common.Hash) *types.Block {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+	return n.conflicts[hash]
+}
+
+// Address implements istanbul.Backend.Address
+func (n *Node) Address() common.Address {
+	return n.address
+}
+
+// Validators implements istanbul.Backend.Validators
+func (n *Node) Validators(proposal istanbul.Proposal) istanbul.ValidatorSet {
+	return validator.NewSet(n.validators, n.config.ProposerPolicy)
+}
+
+// ParentBlockValidators implements istanbul.Backend.ParentBlockValidators
+func (n *Node) ParentBlockValidators(proposal istanbul.Proposal) istanbul.ValidatorSet {
+	return validator.NewSet(n.validators, n.config.ProposerPolicy)
+}
+
+// EventMux implements istanbul.Backend.EventMux
+func (n *Node) EventMux() *event.TypeMux {
+	return n.events
+}
+
+// BroadcastConsensusMsg implements istanbul.Backend.BroadcastConsensusMsg
+func (n *Node) BroadcastConsensusMsg(validators []common.Address, payload []byte) error {
+	n.recordRoundChange(payload)
+
+	// send to others
+	if err := n.Gossip(validators, payload, 0, false); err != nil {
+		return err
+	}
+
+	// send to self
+	go n.events.Post(istanbul.MessageEvent{
+		Payload: payload,
+	})
+	return nil
+}
+
+// Gossip implements istanbul.Backend.Gossip
+func (n *Node) Gossip(validators []common.Address, payload []byte, ethMsgCode uint64, ignoreCache bool) error {
+	switch n.behaviour {
+	case Silent:
+		return nil
+	case Equivocating:
+		return n.equivocate(validators, payload)
+	}
+	for _, address := range validators {
+		if address != n.address {
+			n.network.send(n, address, payload, false)
+		}
+	}
+	return nil
+}
+
+// recordRoundChange keeps track of the view the validator moves to, if the
+// message is a round change.
+func (n *Node) recordRoundChange(payload []byte) {
+	msg := new(istanbul.Message)
+	if err := msg.FromPayload(payload, nil); err != nil || msg.Code != istanbul.MsgRoundChange {
+		return
+	}
+	var rc *istanbul.RoundChange
+	if err := msg.Decode(&rc); err != nil {
+		return
+	}
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.view = *rc.View
+	n.notifyChange()
+}
+
+// Commit implements istanbul.Backend.Commit
+func (n *Node) Commit(proposal istanbul.Proposal, aggregatedSeal types.IstanbulAggregatedSeal) error {
+	block, ok := proposal.(*types.Block)
+	if !ok {
+		return errInvalidProposal
+	}
+
+	n.mu.Lock()
+	head, number := n.head(), block.NumberU64()
+	if number > head.block.NumberU64() {
+		if block.ParentHash() != head.block.Hash() {
+			n.mu.Unlock()
+			return errUnknownParent
+		}
+		n.chain = append(n.chain, &committedBlock{block: block, seal: aggregatedSeal})
+		n.notifyChange()
+	} else if n.chain[number].block.Hash() != block.Hash() {
+		// The block was imported from a peer which committed a different one
+		n.forks = append(n.forks, number)
+	}
+	n.mu.Unlock()
+
+	go n.events.Post(istanbul.FinalCommittedEvent{})
+	n.requestProposal()
+	return nil
+}
+
+// Verify implements istanbul.Backend.Verify
+func (n *Node) Verify(proposal istanbul.Proposal) (time.Duration, error) {
+	if _, ok := proposal.(*types.Block); !ok {
+		return 0, errInvalidProposal
+	}
+
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+	head := n.head().block
+	if proposal.Number().Cmp(new(big.Int).Add(head.Number(), common.Big1)) != 0 || proposal.ParentHash() != head.Hash() {
+		return 0, errUnknownParent
+	}
+	return 0, nil
+}
+
+// Sign implements istanbul.Backend.Sign
+func (n *Node) Sign(data []byte) ([]byte, error) {
+	return crypto.Sign(crypto.Keccak256(data), n.key)
+}
+
+// SignBlockHeader implements istanbul.Backend.SignBlockHeader
+func (n *Node) SignBlockHeader(data []byte) ([]byte, error) {
+	return consensustest.SignBLSMessage(n.blsKey, data)
+}
+
+// CheckSignature implements istanbul.Backend.CheckSignature
+func (n *Node) CheckSignature(data []byte, address common.Address, sig []byte) error {
+	signer, err := istanbul.GetSignatureAddress(data, sig)
+	if err != nil {
+		return err
+	}
+	if signer != address {
+		return istanbul.ErrInvalidSigner
+	}
+	return nil
+}
+
+// GetCurrentHeadBlock implements istanbul.Backend.GetCurrentHeadBlock
+func (n *Node) GetCurrentHeadBlock() istanbul.Proposal {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+	return n.head().block
+}
+
+// GetCurrentHeadBlockAndAuthor implements istanbul.Backend.GetCurrentHeadBlockAndAuthor
+func (n *Node) GetCurrentHeadBlockAndAuthor() (istanbul.Proposal, common.Address) {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+	head := n.head().block
+	return head, head.Coinbase()
+}
+
+// LastSubject implements istanbul.Backend.LastSubject
+func (n *Node) LastSubject() (istanbul.Subject, error) {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+	head := n.head()
+	lastView := &istanbul.View{Sequence: head.block.Number(), Round: new(big.Int).Set(head.seal.Round)}
+	return istanbul.Subject{View: lastView, Digest: head.block.Hash()}, nil
+}
+
+// HasBlock implements istanbul.Backend.HasBlock
+func (n *Node) HasBlock(hash common.Hash, number *big.Int) bool {
+	if !number.IsUint64() {
+		return false
+	}
+	have, ok := n.BlockHash(number.Uint64())
+	return ok && have == hash
+}
+
+// AuthorForBlock implements istanbul.Backend.AuthorForBlock
+func (n *Node) AuthorForBlock(number uint64) common.Address {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+	if number >= uint64(len(n.chain)) {
+		return common.ZeroAddress
+	}
+	return n.chain[number].block.Coinbase()
+}
+
+// RefreshValPeers implements istanbul.Backend.RefreshValPeers. All the simulated
+// validators are always connected.
+func (n *Node) RefreshValPeers(valset istanbul.ValidatorSet) {}
+
+// IsPrimaryForSeq implements istanbul.Backend.IsPrimaryForSeq
+func (n *Node) IsPrimaryForSeq(seq *big.Int) bool {
+	return true
+}
+
+// UpdateReplicaState implements istanbul.Backend.UpdateReplicaState
+func (n *Node) UpdateReplicaState(seq *big.Int) {}
+
+// Authorize implements istanbul.Backend.Authorize. Simulated validators always
+// sign with the key they were created with.
+func (n *Node) Authorize(address common.Address, signFn istanbul.SignerFn, signHashBLSFn istanbul.SignerFn, signMessageBLSFn istanbul.MessageSignerFn) {
+}
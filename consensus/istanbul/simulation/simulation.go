@@ -0,0 +1,229 @@
+// Copyright 2017 The celo Authors
+// This file is part of the celo library.
+//
+// The celo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The celo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the celo library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package simulation runs a set of istanbul validators in memory, over a
+// simulated network with configurable latency, message loss and partitions, and
+// with some of them misbehaving, to check that consensus stays safe and live
+// under a given configuration.
+package simulation
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/consensustest"
+	"github.com/ethereum/go-ethereum/consensus/istanbul"
+)
+
+// errNoValidators is returned if a simulation is configured without validators.
+var errNoValidators = errors.New("no validators to simulate")
+
+// Behaviour is the behaviour of a simulated validator.
+type Behaviour uint64
+
+const (
+	// Honest validators follow the protocol.
+	Honest Behaviour = iota
+	// Silent validators don't send any message, as if they were offline.
+	Silent
+	// Equivocating validators propose conflicting blocks to two halves of the
+	// validator set, and vote for each of them to the corresponding half.
+	Equivocating
+)
+
+func (b Behaviour) String() string {
+	switch b {
+	case Honest:
+		return "honest"
+	case Silent:
+		return "silent"
+	case Equivocating:
+		return "equivocating"
+	default:
+		return "unknown"
+	}
+}
+
+// Config configures a simulation.
+type Config struct {
+	Validators int               // Number of validators
+	Istanbul   *istanbul.Config  // Consensus configuration of all the validators, istanbul.DefaultConfig if nil
+	Network    NetworkConfig     // Delivery of the messages between the validators
+	Behaviours map[int]Behaviour // Behaviour of the validators by index, honest if not set
+	Seed       int64             // Seed of the network's randomness
+}
+
+// Simulation is a set of validators reaching consensus over a simulated network.
+type Simulation struct {
+	nodes   []*Node
+	network *Network
+}
+
+// New creates a simulation with the given configuration. The validators are
+// given new keys, and start from the same genesis block.
+func New(config Config) (*Simulation, error) {
+	if config.Validators <= 0 {
+		return nil, errNoValidators
+	}
+	istConfig := config.Istanbul
+	if istConfig == nil {
+		istConfig = istanbul.DefaultConfig
+	}
+
+	validators, blsKeys, keys, err := consensustest.GenerateValidators(config.Validators)
+	if err != nil {
+		return nil, err
+	}
+
+	sim := &Simulation{
+		network: newNetwork(config.Network, config.Seed),
+	}
+	for i := range validators {
+		node := newNode(i, keys[i], blsKeys[i], validators, istConfig, config.Behaviours[i], sim.network)
+		sim.nodes = append(sim.nodes, node)
+		sim.network.nodes[node.Address()] = node
+	}
+	return sim, nil
+}
+
+// Nodes returns the validators, in the order of the validator set.
+func (sim *Simulation) Nodes() []*Node {
+	return sim.nodes
+}
+
+// Network returns the network between the validators, to inject faults while
+// the simulation runs.
+func (sim *Simulation) Network() *Network {
+	return sim.network
+}
+
+// Start starts the consensus engines of all the validators.
+func (sim *Simulation) Start() error {
+	for _, node := range sim.nodes {
+		if err := node.start(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Stop stops the consensus engines, and drops the messages still in flight.
+func (sim *Simulation) Stop() {
+	sim.network.stop()
+	for _, node := range sim.nodes {
+		node.stop()
+	}
+}
+
+// honest returns the validators following the protocol.
+func (sim *Simulation) honest() []*Node {
+	var nodes []*Node
+	for _, node := range sim.nodes {
+		if node.Behaviour() == Honest {
+			nodes = append(nodes, node)
+		}
+	}
+	return nodes
+}
+
+// WaitForHeight checks liveness, waiting for the given validators, or all the
+// honest ones if none are given, to reach the given height. It returns an error
+// if they didn't within the timeout.
+func (sim *Simulation) WaitForHeight(height uint64, timeout time.Duration, nodes ...*Node) error {
+	lagging := sim.waitFor(timeout, nodes, func(node *Node) (uint64, bool) {
+		have := node.Height()
+		return have, have >= height
+	})
+	if len(lagging) > 0 {
+		return fmt.Errorf("validators didn't reach height %d within %v: %v", height, timeout, lagging)
+	}
+	return nil
+}
+
+// WaitForRound waits for the given validators, or all the honest ones if none
+// are given, to move to the given round at the height following their head, i.e.
+// to time out that many times without committing a block. It returns an error if
+// they didn't within the timeout.
+func (sim *Simulation) WaitForRound(round uint64, timeout time.Duration, nodes ...*Node) error {
+	lagging := sim.waitFor(timeout, nodes, func(node *Node) (uint64, bool) {
+		have := node.Round()
+		return have, have >= round
+	})
+	if len(lagging) > 0 {
+		return fmt.Errorf("validators didn't move to round %d within %v: %v", round, timeout, lagging)
+	}
+	return nil
+}
+
+// waitFor waits for the given validators, or all the honest ones if none are
+// given, to meet the condition, which reports the value it checks. Validators
+// are checked again whenever their chain or view changes. It returns the ones
+// still not meeting the condition after the timeout, with their values.
+func (sim *Simulation) waitFor(timeout time.Duration, nodes []*Node, cond func(*Node) (uint64, bool)) []string {
+	if len(nodes) == 0 {
+		nodes = sim.honest()
+	}
+	deadline := time.NewTimer(timeout)
+	defer deadline.Stop()
+
+	for {
+		var (
+			lagging []string
+			changed []<-chan struct{}
+		)
+		for _, node := range nodes {
+			// Get notified of the next change before checking the current state
+			ch := node.changed()
+			if have, ok := cond(node); !ok {
+				lagging = append(lagging, fmt.Sprintf("%d at %d", node.Index(), have))
+				changed = append(changed, ch)
+			}
+		}
+		if len(lagging) == 0 {
+			return nil
+		}
+		select {
+		case <-changed[0]:
+		case <-deadline.C:
+			return lagging
+		}
+	}
+}
+
+// CheckSafety checks that the honest validators never committed conflicting
+// blocks, and have the same block at every height they reached.
+func (sim *Simulation) CheckSafety() error {
+	hashes := make(map[uint64]common.Hash)
+	for _, node := range sim.honest() {
+		if forks := node.Forks(); len(forks) > 0 {
+			return fmt.Errorf("validator %d committed conflicting blocks at heights %v", node.Index(), forks)
+		}
+		for number := uint64(1); ; number++ {
+			hash, ok := node.BlockHash(number)
+			if !ok {
+				break
+			}
+			if want, ok := hashes[number]; !ok {
+				hashes[number] = hash
+			} else if hash != want {
+				return fmt.Errorf("validator %d has block %x at height %d, others have %x", node.Index(), hash, number, want)
+			}
+		}
+	}
+	return nil
+}
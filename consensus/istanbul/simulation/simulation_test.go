@@ -0,0 +1,247 @@
+// Copyright 2017 The celo Authors
+// This file is part of the celo library.
+//
+// The celo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The celo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the celo library. If not, see <http://www.gnu.org/licenses/>.
+
+package simulation
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/consensus/istanbul"
+)
+
+const testTimeout = time.Minute
+
+func newTestConfig(policy istanbul.ProposerPolicy, requestTimeout uint64) *istanbul.Config {
+	config := *istanbul.DefaultConfig
+	config.ProposerPolicy = policy
+	config.RequestTimeout = requestTimeout
+	config.BlockPeriod = 0
+	return &config
+}
+
+func startSimulation(t *testing.T, config Config) *Simulation {
+	sim, err := New(config)
+	if err != nil {
+		t.Fatalf("failed to create simulation: %v", err)
+	}
+	if err := sim.Start(); err != nil {
+		t.Fatalf("failed to start simulation: %v", err)
+	}
+	return sim
+}
+
+func TestSimulation(t *testing.T) {
+	tests := []struct {
+		name       string
+		istanbul   *istanbul.Config
+		network    NetworkConfig
+		behaviours map[int]Behaviour
+	}{
+		{
+			name:     "round robin",
+			istanbul: newTestConfig(istanbul.RoundRobin, 200),
+		},
+		{
+			name:     "sticky",
+			istanbul: newTestConfig(istanbul.Sticky, 200),
+		},
+		{
+			name:     "shuffled round robin",
+			istanbul: newTestConfig(istanbul.ShuffledRoundRobin, 200),
+		},
+		{
+			name:       "silent validator",
+			istanbul:   newTestConfig(istanbul.RoundRobin, 200),
+			behaviours: map[int]Behaviour{0: Silent},
+		},
+		{
+			name:       "silent validator with long timeout",
+			istanbul:   newTestConfig(istanbul.RoundRobin, 1000),
+			behaviours: map[int]Behaviour{0: Silent},
+		},
+		{
+			name:       "silent sticky proposer",
+			istanbul:   newTestConfig(istanbul.Sticky, 200),
+			behaviours: map[int]Behaviour{0: Silent},
+		},
+		{
+			name:     "latency and reordering",
+			istanbul: newTestConfig(istanbul.ShuffledRoundRobin, 500),
+			network:  NetworkConfig{Latency: 10 * time.Millisecond, Jitter: 40 * time.Millisecond},
+		},
+		{
+			name:     "lossy network",
+			istanbul: newTestConfig(istanbul.ShuffledRoundRobin, 500),
+			network:  NetworkConfig{Latency: 5 * time.Millisecond, Jitter: 20 * time.Millisecond, DropRate: 0.05},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sim := startSimulation(t, Config{
+				Validators: 4,
+				Istanbul:   tt.istanbul,
+				Network:    tt.network,
+				Behaviours: tt.behaviours,
+			})
+			defer sim.Stop()
+
+			if err := sim.WaitForHeight(5, testTimeout); err != nil {
+				t.Errorf("liveness: %v", err)
+			}
+			if err := sim.CheckSafety(); err != nil {
+				t.Errorf("safety: %v", err)
+			}
+		})
+	}
+}
+
+func TestEquivocation(t *testing.T) {
+	tests := []struct {
+		name      string
+		partition func(nodes []*Node) [][]*Node
+	}{
+		{
+			name: "connected",
+		},
+		{
+			// The equivocating validator sends its conflicting messages to the
+			// odd validators, which it can reach a quorum with
+			name: "bridging a partition",
+			partition: func(nodes []*Node) [][]*Node {
+				return [][]*Node{{nodes[0], nodes[1], nodes[3]}, {nodes[0], nodes[2]}}
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sim, err := New(Config{
+				Validators: 4,
+				Istanbul:   newTestConfig(istanbul.RoundRobin, 200),
+				Behaviours: map[int]Behaviour{0: Equivocating},
+			})
+			if err != nil {
+				t.Fatalf("failed to create simulation: %v", err)
+			}
+			nodes := sim.Nodes()
+			if tt.partition != nil {
+				sim.Network().Partition(tt.partition(nodes)...)
+			}
+			if err := sim.Start(); err != nil {
+				t.Fatalf("failed to start simulation: %v", err)
+			}
+			defer sim.Stop()
+
+			// Every validator gets a turn to propose
+			if err := sim.WaitForHeight(5, testTimeout, nodes[1], nodes[3]); err != nil {
+				t.Fatalf("liveness: %v", err)
+			}
+			if err := sim.CheckSafety(); err != nil {
+				t.Fatalf("safety: %v", err)
+			}
+			sent, received := nodes[0].ConflictingSent(), uint64(0)
+			for _, node := range nodes[1:] {
+				received += node.ConflictingReceived()
+			}
+			if sent == 0 {
+				t.Fatalf("no conflicting messages sent")
+			}
+			if received == 0 || received > sent {
+				t.Fatalf("conflicting messages received mismatch: have %d, want between 1 and %d", received, sent)
+			}
+
+			// All the honest validators end up on the same chain
+			sim.Network().Heal()
+			if err := sim.WaitForHeight(nodes[1].Height()+2, testTimeout); err != nil {
+				t.Errorf("liveness after healing: %v", err)
+			}
+			if err := sim.CheckSafety(); err != nil {
+				t.Errorf("safety: %v", err)
+			}
+		})
+	}
+}
+
+func TestPartitionedValidator(t *testing.T) {
+	sim, err := New(Config{
+		Validators: 4,
+		Istanbul:   newTestConfig(istanbul.RoundRobin, 200),
+	})
+	if err != nil {
+		t.Fatalf("failed to create simulation: %v", err)
+	}
+	nodes := sim.Nodes()
+
+	// A quorum keeps committing blocks without the isolated validator
+	sim.Network().Partition(nodes[:3])
+	if err := sim.Start(); err != nil {
+		t.Fatalf("failed to start simulation: %v", err)
+	}
+	defer sim.Stop()
+
+	if err := sim.WaitForHeight(3, testTimeout, nodes[:3]...); err != nil {
+		t.Fatalf("liveness of the quorum: %v", err)
+	}
+	if height := nodes[3].Height(); height != 0 {
+		t.Errorf("isolated validator height mismatch: have %d, want 0", height)
+	}
+
+	// The isolated validator catches up once reconnected
+	sim.Network().Heal()
+	if err := sim.WaitForHeight(nodes[0].Height()+2, testTimeout); err != nil {
+		t.Errorf("liveness after healing: %v", err)
+	}
+	if err := sim.CheckSafety(); err != nil {
+		t.Errorf("safety: %v", err)
+	}
+}
+
+func TestPartitionWithoutQuorum(t *testing.T) {
+	sim, err := New(Config{
+		Validators: 4,
+		Istanbul:   newTestConfig(istanbul.RoundRobin, 200),
+	})
+	if err != nil {
+		t.Fatalf("failed to create simulation: %v", err)
+	}
+	nodes := sim.Nodes()
+
+	// Neither half can commit a block on its own
+	sim.Network().Partition(nodes[:2], nodes[2:])
+	if err := sim.Start(); err != nil {
+		t.Fatalf("failed to start simulation: %v", err)
+	}
+	defer sim.Stop()
+
+	// Both halves time out twice without committing a block
+	if err := sim.WaitForRound(2, testTimeout); err != nil {
+		t.Fatalf("round changes: %v", err)
+	}
+	for _, node := range nodes {
+		if height := node.Height(); height != 0 {
+			t.Errorf("validator %d committed without a quorum: height %d", node.Index(), height)
+		}
+	}
+
+	// Consensus resumes once the partition is healed
+	sim.Network().Heal()
+	if err := sim.WaitForHeight(3, testTimeout); err != nil {
+		t.Errorf("liveness after healing: %v", err)
+	}
+	if err := sim.CheckSafety(); err != nil {
+		t.Errorf("safety: %v", err)
+	}
+}
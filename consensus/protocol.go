@@ -77,4 +77,6 @@ type Peer interface {
 	Send(msgcode uint64, data interface{}) error
 	// Returns the peer's enode
 	Node() *enode.Node
+	// Returns the protocol version negotiated with the peer
+	Version() int
 }
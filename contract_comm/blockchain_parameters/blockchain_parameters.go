@@ -156,7 +156,11 @@ func SpawnCheck() {
 	go func() {
 		for {
 			time.Sleep(60 * time.Second)
-			CheckMinimumVersion(nil, nil)
+			if header, state, err := contract_comm.LatestContext(); err != nil {
+				log.Warn("Error retrieving the latest state to check the client version", "err", err)
+			} else {
+				CheckMinimumVersion(header, state)
+			}
 		}
 	}()
 }
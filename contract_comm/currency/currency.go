@@ -31,32 +31,6 @@ import (
 )
 
 const (
-	// This is taken from celo-monorepo/packages/protocol/build/<env>/contracts/SortedOracles.json
-	medianRateABI = `[
-    {
-      "constant": true,
-      "inputs": [
-        {
-          "name": "token",
-          "type": "address"
-        }
-      ],
-      "name": "medianRate",
-      "outputs": [
-        {
-          "name": "",
-          "type": "uint128"
-        },
-        {
-          "name": "",
-          "type": "uint128"
-        }
-      ],
-      "payable": false,
-      "stateMutability": "view",
-      "type": "function"
-    }]`
-
 	// This is taken from celo-monorepo/packages/protocol/build/<env>/contracts/ERC20.json
 	balanceOfABI = `[{"constant": true,
                           "inputs": [
@@ -97,18 +71,21 @@ var (
 	cgExchangeRateNum = big.NewInt(1)
 	cgExchangeRateDen = big.NewInt(1)
 
-	medianRateFuncABI, _   = abi.JSON(strings.NewReader(medianRateABI))
 	balanceOfFuncABI, _    = abi.JSON(strings.NewReader(balanceOfABI))
 	getWhitelistFuncABI, _ = abi.JSON(strings.NewReader(getWhitelistABI))
 )
 
-type exchangeRate struct {
+// ExchangeRate is the oracle rate of a currency, expressed as the number of
+// currency units (Numerator) worth Denominator units of Celo Gold.
+type ExchangeRate struct {
 	Numerator   *big.Int
 	Denominator *big.Int
 }
 
-func ConvertToGold(val *big.Int, currencyFrom *common.Address) (*big.Int, error) {
-	celoGoldAddress, err := contract_comm.GetRegisteredAddress(params.GoldTokenRegistryId, nil, nil)
+// ConvertToGold converts a value denominated in currencyFrom to Celo Gold at the
+// oracle rate in force at the given header and state.
+func ConvertToGold(val *big.Int, currencyFrom *common.Address, header *types.Header, state vm.StateDB) (*big.Int, error) {
+	celoGoldAddress, err := contract_comm.GetRegisteredAddress(params.GoldTokenRegistryId, header, state)
 	if err == errors.ErrSmartContractNotDeployed || err == errors.ErrRegistryContractNotDeployed {
 		log.Warn("Registry address lookup failed", "err", err)
 		return val, err
@@ -120,14 +97,16 @@ func ConvertToGold(val *big.Int, currencyFrom *common.Address) (*big.Int, error)
 		log.Error(err.Error())
 		return val, err
 	}
-	return Convert(val, currencyFrom, celoGoldAddress)
+	return Convert(val, currencyFrom, celoGoldAddress, header, state)
 }
 
+// Convert converts a value denominated in currencyFrom to currencyTo at the
+// oracle rates in force at the given header and state.
 // NOTE (jarmg 4/24/19): values are rounded down which can cause
 // an estimate to be off by 1 (at most)
-func Convert(val *big.Int, currencyFrom *common.Address, currencyTo *common.Address) (*big.Int, error) {
-	exchangeRateFrom, err1 := getExchangeRate(currencyFrom)
-	exchangeRateTo, err2 := getExchangeRate(currencyTo)
+func Convert(val *big.Int, currencyFrom *common.Address, currencyTo *common.Address, header *types.Header, state vm.StateDB) (*big.Int, error) {
+	exchangeRateFrom, err1 := GetExchangeRate(currencyFrom, header, state)
+	exchangeRateTo, err2 := GetExchangeRate(currencyTo, header, state)
 
 	if err1 != nil || err2 != nil {
 		log.Error("Convert - Error in retreiving currency exchange rates")
@@ -146,13 +125,15 @@ func Convert(val *big.Int, currencyFrom *common.Address, currencyTo *common.Addr
 	return new(big.Int).Div(numerator, denominator), nil
 }
 
-func Cmp(val1 *big.Int, currency1 *common.Address, val2 *big.Int, currency2 *common.Address) int {
+// Cmp compares two values denominated in possibly different currencies at the
+// oracle rates in force at the given header and state.
+func Cmp(val1 *big.Int, currency1 *common.Address, val2 *big.Int, currency2 *common.Address, header *types.Header, state vm.StateDB) int {
 	if currency1 == currency2 {
 		return val1.Cmp(val2)
 	}
 
-	exchangeRate1, err1 := getExchangeRate(currency1)
-	exchangeRate2, err2 := getExchangeRate(currency2)
+	exchangeRate1, err1 := GetExchangeRate(currency1, header, state)
+	exchangeRate2, err2 := GetExchangeRate(currency2, header, state)
 
 	if err1 != nil || err2 != nil {
 		currency1Output := "nil"
@@ -176,27 +157,29 @@ func Cmp(val1 *big.Int, currency1 *common.Address, val2 *big.Int, currency2 *com
 	return leftSide.Cmp(rightSide)
 }
 
-func getExchangeRate(currencyAddress *common.Address) (*exchangeRate, error) {
+// GetExchangeRate retrieves the median oracle rate of a currency at the given
+// header and state. A nil currency address denotes Celo Gold.
+func GetExchangeRate(currencyAddress *common.Address, header *types.Header, state vm.StateDB) (*ExchangeRate, error) {
 	var (
 		returnArray [2]*big.Int
 		leftoverGas uint64
 	)
 
 	if currencyAddress == nil {
-		return &exchangeRate{cgExchangeRateNum, cgExchangeRateDen}, nil
+		return &ExchangeRate{cgExchangeRateNum, cgExchangeRateDen}, nil
 	} else {
-		if leftoverGas, err := contract_comm.MakeStaticCall(params.SortedOraclesRegistryId, medianRateFuncABI, "medianRate", []interface{}{currencyAddress}, &returnArray, params.MaxGasForMedianRate, nil, nil); err != nil {
+		if leftoverGas, err := contract_comm.MakeStaticCall(params.SortedOraclesRegistryId, vm.MedianRateFuncABI, "medianRate", []interface{}{currencyAddress}, &returnArray, params.MaxGasForMedianRate, header, state); err != nil {
 			if err == errors.ErrSmartContractNotDeployed {
 				log.Warn("Registry address lookup failed", "err", err)
-				return &exchangeRate{big.NewInt(1), big.NewInt(1)}, err
+				return &ExchangeRate{big.NewInt(1), big.NewInt(1)}, err
 			} else {
 				log.Error("medianRate invocation error", "feeCurrencyAddress", currencyAddress.Hex(), "leftoverGas", leftoverGas, "err", err)
-				return &exchangeRate{big.NewInt(1), big.NewInt(1)}, err
+				return &ExchangeRate{big.NewInt(1), big.NewInt(1)}, err
 			}
 		}
 	}
 	log.Trace("medianRate invocation success", "feeCurrencyAddress", currencyAddress, "returnArray", returnArray, "leftoverGas", leftoverGas)
-	return &exchangeRate{returnArray[0], returnArray[1]}, nil
+	return &ExchangeRate{returnArray[0], returnArray[1]}, nil
 }
 
 // This function will retrieve the balance of an ERC20 token.
@@ -16,6 +16,7 @@
 package election
 
 import (
+	"fmt"
 	"math/big"
 	"sort"
 	"strings"
@@ -110,6 +111,60 @@ const electionABIString string = `[
       "payable": false,
       "stateMutability": "view",
       "type": "function"
+    },
+    {
+      "constant": false,
+      "inputs": [
+        {
+          "name": "group",
+          "type": "address"
+        },
+        {
+          "name": "value",
+          "type": "uint256"
+        },
+        {
+          "name": "lesser",
+          "type": "address"
+        },
+        {
+          "name": "greater",
+          "type": "address"
+        },
+        {
+          "name": "index",
+          "type": "uint256"
+        }
+      ],
+      "name": "revokeActive",
+      "outputs": [
+        {
+          "name": "",
+          "type": "bool"
+        }
+      ],
+      "payable": false,
+      "stateMutability": "nonpayable",
+      "type": "function"
+    },
+    {
+      "constant": true,
+      "inputs": [
+        {
+          "name": "account",
+          "type": "address"
+        }
+      ],
+      "name": "getGroupsVotedForByAccount",
+      "outputs": [
+        {
+          "name": "",
+          "type": "address[]"
+        }
+      ],
+      "payable": false,
+      "stateMutability": "view",
+      "type": "function"
     }
 ]`
 
@@ -125,12 +180,16 @@ func GetElectedValidators(header *types.Header, state vm.StateDB) ([]common.Addr
 	return newValSet, nil
 }
 
-type voteTotal struct {
+// VoteTotal is the total of the active and pending votes for an eligible
+// validator group.
+type VoteTotal struct {
 	Group common.Address
 	Value *big.Int
 }
 
-func getTotalVotesForEligibleValidatorGroups(header *types.Header, state vm.StateDB) ([]voteTotal, error) {
+// GetTotalVotesForEligibleValidatorGroups returns the vote totals of the eligible
+// validator groups.
+func GetTotalVotesForEligibleValidatorGroups(header *types.Header, state vm.StateDB) ([]VoteTotal, error) {
 	var groups []common.Address
 	var values []*big.Int
 	_, err := contract_comm.MakeStaticCall(params.ElectionRegistryId, electionABI, "getTotalVotesForEligibleValidatorGroups", []interface{}{}, &[]interface{}{&groups, &values}, params.MaxGasForGetEligibleValidatorGroupsVoteTotals, header, state)
@@ -138,7 +197,7 @@ func getTotalVotesForEligibleValidatorGroups(header *types.Header, state vm.Stat
 		log.Error("Error calling getTotalVotesForEligibleValidatorGroups", "err", err)
 	}
 
-	voteTotals := make([]voteTotal, len(groups))
+	voteTotals := make([]VoteTotal, len(groups))
 	for i, group := range groups {
 		log.Trace("Got group vote total", "group", group, "value", values[i])
 		voteTotals[i].Group = group
@@ -156,9 +215,52 @@ func getGroupEpochRewards(header *types.Header, state vm.StateDB, group common.A
 	return groupEpochRewards, nil
 }
 
+// updateGroupVotes adds value to, or if decrement is set subtracts it from, the
+// vote total of the group, and returns the neighbours of the group in the
+// contract's list of eligible groups sorted by votes after the change.
+func updateGroupVotes(voteTotals []VoteTotal, group common.Address, value *big.Int, decrement bool) (lesser, greater common.Address) {
+	for _, voteTotal := range voteTotals {
+		if voteTotal.Group == group {
+			if decrement {
+				voteTotal.Value.Sub(voteTotal.Value, value)
+			} else {
+				voteTotal.Value.Add(voteTotal.Value, value)
+			}
+			break
+		}
+	}
+
+	sort.SliceStable(voteTotals, func(j, k int) bool {
+		return voteTotals[j].Value.Cmp(voteTotals[k].Value) < 0
+	})
+
+	lesser = common.ZeroAddress
+	greater = common.ZeroAddress
+	for i, voteTotal := range voteTotals {
+		if voteTotal.Group == group {
+			if i > 0 {
+				lesser = voteTotals[i-1].Group
+			}
+			if i+1 < len(voteTotals) {
+				greater = voteTotals[i+1].Group
+			}
+			break
+		}
+	}
+	return lesser, greater
+}
+
+// incrementGroupVotes adds value to the active votes of the group, keeping the
+// given vote totals and the contract's sorted list of eligible groups in sync.
+func incrementGroupVotes(header *types.Header, state vm.StateDB, voteTotals []VoteTotal, group common.Address, value *big.Int) error {
+	lesser, greater := updateGroupVotes(voteTotals, group, value, false)
+	_, err := contract_comm.MakeCall(params.ElectionRegistryId, electionABI, "distributeEpochRewards", []interface{}{group, value, lesser, greater}, nil, params.MaxGasForDistributeEpochRewards, common.Big0, header, state, false)
+	return err
+}
+
 func DistributeEpochRewards(header *types.Header, state vm.StateDB, groups []common.Address, maxTotalRewards *big.Int) (*big.Int, error) {
 	totalRewards := big.NewInt(0)
-	voteTotals, err := getTotalVotesForEligibleValidatorGroups(header, state)
+	voteTotals, err := GetTotalVotesForEligibleValidatorGroups(header, state)
 	if err != nil {
 		return totalRewards, err
 	}
@@ -173,36 +275,82 @@ func DistributeEpochRewards(header *types.Header, state vm.StateDB, groups []com
 	}
 
 	for i, group := range groups {
-		reward := rewards[i]
-		for _, voteTotal := range voteTotals {
-			if voteTotal.Group == group {
-				voteTotal.Value.Add(voteTotal.Value, reward)
-				break
-			}
+		if err := incrementGroupVotes(header, state, voteTotals, group, rewards[i]); err != nil {
+			return totalRewards, err
 		}
+		totalRewards.Add(totalRewards, rewards[i])
+	}
+	return totalRewards, nil
+}
 
-		sort.SliceStable(voteTotals, func(j, k int) bool {
-			return voteTotals[j].Value.Cmp(voteTotals[k].Value) < 0
-		})
-
-		lesser := common.ZeroAddress
-		greater := common.ZeroAddress
-		for i, voteTotal := range voteTotals {
-			if voteTotal.Group == group {
-				if i > 0 {
-					lesser = voteTotals[i-1].Group
-				}
-				if i+1 < len(voteTotals) {
-					greater = voteTotals[i+1].Group
-				}
-				break
-			}
+// IncrementGroupVotes adds the given values to the active votes of the groups.
+// It's meant to be run against a copy of the state, to predict the effect of a
+// vote change on the election.
+func IncrementGroupVotes(header *types.Header, state vm.StateDB, groups []common.Address, values []*big.Int) error {
+	voteTotals, err := GetTotalVotesForEligibleValidatorGroups(header, state)
+	if err != nil {
+		return err
+	}
+	for i, group := range groups {
+		if findVoteTotal(voteTotals, group) == nil {
+			return fmt.Errorf("group %s is not eligible", group.Hex())
 		}
-		_, err := contract_comm.MakeCall(params.ElectionRegistryId, electionABI, "distributeEpochRewards", []interface{}{group, reward, lesser, greater}, nil, params.MaxGasForDistributeEpochRewards, common.Big0, header, state, false)
-		if err != nil {
-			return totalRewards, err
+		if err := incrementGroupVotes(header, state, voteTotals, group, values[i]); err != nil {
+			return err
 		}
-		totalRewards.Add(totalRewards, reward)
 	}
-	return totalRewards, nil
+	return nil
+}
+
+// DecrementGroupVotes revokes the given amount of the voter's active votes for
+// the group, as the voter would by calling revokeActive. Like IncrementGroupVotes,
+// it's meant to be run against a copy of the state.
+func DecrementGroupVotes(header *types.Header, state vm.StateDB, voter common.Address, group common.Address, value *big.Int) error {
+	voteTotals, err := GetTotalVotesForEligibleValidatorGroups(header, state)
+	if err != nil {
+		return err
+	}
+	voteTotal := findVoteTotal(voteTotals, group)
+	if voteTotal == nil {
+		return fmt.Errorf("group %s is not eligible", group.Hex())
+	}
+	if voteTotal.Value.Cmp(value) < 0 {
+		return fmt.Errorf("group %s has %v votes, less than the %v revoked", group.Hex(), voteTotal.Value, value)
+	}
+	// The contract expects the index of the group in the voter's list of groups
+	var votedFor []common.Address
+	if _, err := contract_comm.MakeStaticCall(params.ElectionRegistryId, electionABI, "getGroupsVotedForByAccount", []interface{}{voter}, &votedFor, params.MaxGasForGetGroupsVotedForByAccount, header, state); err != nil {
+		return err
+	}
+	index := -1
+	for i, votedGroup := range votedFor {
+		if votedGroup == group {
+			index = i
+			break
+		}
+	}
+	if index < 0 {
+		return fmt.Errorf("account %s has no votes for group %s", voter.Hex(), group.Hex())
+	}
+	lesser, greater := updateGroupVotes(voteTotals, group, value, true)
+
+	var success bool
+	_, err = contract_comm.MakeCallFromAccount(voter, params.ElectionRegistryId, electionABI, "revokeActive", []interface{}{group, value, lesser, greater, big.NewInt(int64(index))}, &success, params.MaxGasForRevokeActive, common.Big0, header, state)
+	if err != nil {
+		return err
+	}
+	if !success {
+		return fmt.Errorf("failed to revoke votes of account %s for group %s", voter.Hex(), group.Hex())
+	}
+	return nil
+}
+
+// findVoteTotal returns the vote total of the group, or nil if it's not eligible.
+func findVoteTotal(voteTotals []VoteTotal, group common.Address) *VoteTotal {
+	for i := range voteTotals {
+		if voteTotals[i].Group == group {
+			return &voteTotals[i]
+		}
+	}
+	return nil
 }
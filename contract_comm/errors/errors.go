@@ -9,4 +9,6 @@ var (
 	ErrSmartContractNotDeployed      = errors.New("registered contract not deployed")
 	ErrRegistryContractNotDeployed   = errors.New("contract registry not deployed")
 	ErrNoInternalEvmHandlerSingleton = errors.New("No internalEvmHandlerSingleton set for contract communication")
+	// ErrNoBlockContext is returned when a contract call is made without the header and state to evaluate it against
+	ErrNoBlockContext = errors.New("no header and state for contract communication")
 )
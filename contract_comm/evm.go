@@ -141,6 +141,24 @@ func MakeCall(registryId [32]byte, abi abi.ABI, funcName string, args []interfac
 	return gasLeft, err
 }
 
+// MakeCallFromAccount calls the registered contract on behalf of the given account
+// rather than the system, for calls only an account may make.
+func MakeCallFromAccount(caller common.Address, registryId [32]byte, abi abi.ABI, funcName string, args []interface{}, returnObj interface{}, gas uint64, value *big.Int, header *types.Header, state vm.StateDB) (uint64, error) {
+	scAddress, err := GetRegisteredAddress(registryId, header, state)
+	if err != nil {
+		return 0, err
+	}
+	vmevm, err := createEVM(header, state)
+	if err != nil {
+		return 0, err
+	}
+	gasLeft, err := vmevm.CallFromAccount(caller, *scAddress, abi, funcName, args, returnObj, gas, value)
+	if err != nil {
+		log.Error("Error when invoking evm function", "err", err, "funcName", funcName, "caller", caller, "address", scAddress)
+	}
+	return gasLeft, err
+}
+
 func MakeStaticCallWithAddress(scAddress common.Address, abi abi.ABI, funcName string, args []interface{}, returnObj interface{}, gas uint64, header *types.Header, state vm.StateDB) (uint64, error) {
 	return makeCallFromSystem(scAddress, abi, funcName, args, returnObj, gas, nil, header, state, true)
 }
@@ -154,25 +172,16 @@ func GetRegisteredAddress(registryId [32]byte, header *types.Header, state vm.St
 }
 
 func createEVM(header *types.Header, state vm.StateDB) (*vm.EVM, error) {
-	// Normally, when making an evm call, we should use the current block's state.  However,
-	// there are times (e.g. retrieving the set of validators when an epoch ends) that we need
-	// to call the evm using the currently mined block.  In that case, the header and state params
-	// will be non nil.
+	// Callers pass the header and state of the block the call is evaluated against, so that
+	// validation, estimation and replay of old blocks read the contract values in force at
+	// that block.  Callers after the latest values get them explicitly from LatestContext.
+	// The internal EVM handler only supplies the chain context (ancestor hashes, engine and config).
 	if internalEvmHandlerSingleton == nil {
 		return nil, errors.ErrNoInternalEvmHandlerSingleton
 	}
 
-	if header == nil {
-		header = internalEvmHandlerSingleton.chain.CurrentHeader()
-	}
-
-	if state == nil || reflect.ValueOf(state).IsNil() {
-		var err error
-		state, err = internalEvmHandlerSingleton.chain.State()
-		if err != nil {
-			log.Error("Error in retrieving the state from the blockchain", "err", err)
-			return nil, err
-		}
+	if header == nil || state == nil || reflect.ValueOf(state).IsNil() {
+		return nil, errors.ErrNoBlockContext
 	}
 
 	// The EVM Context requires a msg, but the actual field values don't really matter for this case.
@@ -204,6 +213,25 @@ func makeCallFromSystem(scAddress common.Address, abi abi.ABI, funcName string,
 	return gasLeft, nil
 }
 
+// LatestContext returns the header and state of the current head of the chain, for the
+// contract calls made from within geth that read the latest values rather than those
+// in force at a given block.
+func LatestContext() (*types.Header, *state.StateDB, error) {
+	if internalEvmHandlerSingleton == nil {
+		return nil, nil, errors.ErrNoInternalEvmHandlerSingleton
+	}
+
+	header := internalEvmHandlerSingleton.chain.CurrentHeader()
+	statedb, err := internalEvmHandlerSingleton.chain.State()
+	if err != nil {
+		log.Error("Error in retrieving the state from the blockchain", "err", err)
+		return nil, nil, err
+	}
+	return header, statedb, nil
+}
+
+// SetInternalEVMHandler registers the chain that provides the context of contract calls
+// made from within geth.
 func SetInternalEVMHandler(chain ChainContext) {
 	if internalEvmHandlerSingleton == nil {
 		log.Trace("Setting the InternalEVMHandler Singleton")
@@ -115,8 +115,8 @@ func commitmentDbLocation(commitment common.Hash) []byte {
 	return append(dbRandomnessPrefix, commitment.Bytes()...)
 }
 
-func address() *common.Address {
-	randomAddress, err := contract_comm.GetRegisteredAddress(params.RandomRegistryId, nil, nil)
+func address(header *types.Header, state vm.StateDB) *common.Address {
+	randomAddress, err := contract_comm.GetRegisteredAddress(params.RandomRegistryId, header, state)
 	if err == errors.ErrSmartContractNotDeployed || err == errors.ErrRegistryContractNotDeployed {
 		log.Debug("Registry address lookup failed", "err", err, "contract id", params.RandomRegistryId)
 	} else if err != nil {
@@ -125,8 +125,10 @@ func address() *common.Address {
 	return randomAddress
 }
 
-func IsRunning() bool {
-	randomAddress := address()
+// IsRunning returns whether the random beacon contract is deployed at the given
+// header and state.
+func IsRunning(header *types.Header, state vm.StateDB) bool {
+	randomAddress := address(header, state)
 	return randomAddress != nil && *randomAddress != common.ZeroAddress
 }
 
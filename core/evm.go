@@ -59,9 +59,15 @@ func NewEVMContext(msg Message, header *types.Header, chain ChainContext, author
 		beneficiary = *author
 	}
 
+	// Fee currency calls of the state transition see the block being processed, or
+	// the chain head before the fee currency context fork
 	var engine consensus.Engine
+	contextHeader := header
 	if chain != nil {
 		engine = chain.Engine()
+		if config := chain.Config(); config != nil && !config.IsFeeCurrencyContext(header.Number) {
+			contextHeader = chain.CurrentHeader()
+		}
 	}
 
 	return vm.Context{
@@ -75,6 +81,7 @@ func NewEVMContext(msg Message, header *types.Header, chain ChainContext, author
 		Difficulty:  new(big.Int).Set(header.Difficulty),
 		GasLimit:    header.GasLimit,
 		GasPrice:    new(big.Int).Set(msg.GasPrice()),
+		Header:      contextHeader,
 		Engine:      engine,
 	}
 }
@@ -0,0 +1,121 @@
+// Copyright 2019 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"sync/atomic"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// prefetchRegistryIds are the core contracts that are read by nearly every block,
+// through the fee currency, gas price minimum and fee distribution logic.
+var prefetchRegistryIds = [][32]byte{
+	params.BlockchainParametersRegistryId,
+	params.FeeCurrencyWhitelistRegistryId,
+	params.GasPriceMinimumRegistryId,
+	params.GoldTokenRegistryId,
+	params.GovernanceRegistryId,
+	params.RandomRegistryId,
+	params.SortedOraclesRegistryId,
+	params.StableTokenRegistryId,
+}
+
+// statePrefetcher is a basic Prefetcher, which blindly executes a block on top
+// of an arbitrary state with the goal of prefetching potentially useful state
+// data from disk before the main block processor start executing.
+type statePrefetcher struct {
+	config *params.ChainConfig // Chain configuration options
+	bc     *BlockChain         // Canonical block chain
+	engine consensus.Engine    // Consensus engine used for block rewards
+}
+
+// newStatePrefetcher initialises a new statePrefetcher.
+func newStatePrefetcher(config *params.ChainConfig, bc *BlockChain, engine consensus.Engine) *statePrefetcher {
+	return &statePrefetcher{
+		config: config,
+		bc:     bc,
+		engine: engine,
+	}
+}
+
+// Prefetch processes the state changes according to the Ethereum rules by running
+// the transaction messages using the statedb, but any changes are discarded. The
+// only goal is to pre-cache transaction signatures and state trie nodes.
+func (p *statePrefetcher) Prefetch(block *types.Block, statedb *state.StateDB, cfg vm.Config, interrupt *uint32) {
+	var (
+		header  = block.Header()
+		gaspool = new(GasPool).AddGas(block.GasLimit())
+	)
+	// Warm the registry entries and accounts of the core contracts first, as
+	// every transaction of the block will read them.
+	p.prefetchRegistry(header, statedb, cfg)
+
+	// Iterate over and process the individual transactions
+	for i, tx := range block.Transactions() {
+		// If block precaching was interrupted, abort
+		if interrupt != nil && atomic.LoadUint32(interrupt) == 1 {
+			return
+		}
+		// Block precaching permitted to continue, execute the transaction. Failures
+		// are expected, as the state may not match the block's parent.
+		statedb.Prepare(tx.Hash(), block.Hash(), i)
+		if err := precacheTransaction(p.config, p.bc, nil, gaspool, statedb, header, tx, cfg); err == ErrGasLimitReached {
+			return
+		}
+	}
+}
+
+// prefetchRegistry looks up the addresses of the well-known core contracts in
+// the registry and loads their accounts and code.
+func (p *statePrefetcher) prefetchRegistry(header *types.Header, statedb *state.StateDB, cfg vm.Config) {
+	msg := types.NewMessage(common.Address{}, nil, 0, common.Big0, 0, common.Big0, nil, nil, common.Big0, nil, false)
+	evm := vm.NewEVM(NewEVMContext(msg, header, p.bc, nil), statedb, p.config, cfg)
+
+	for _, id := range prefetchRegistryIds {
+		address, err := vm.GetRegisteredAddressWithEvm(id, evm)
+		if err != nil || address == nil {
+			continue
+		}
+		statedb.GetCode(*address)
+	}
+}
+
+// precacheTransaction attempts to apply a transaction to the given state database
+// and uses the input parameters for its environment. The goal is not to execute
+// the transaction successfully, rather to warm up touched data slots. The nonce
+// is not checked, since the state is usually that of an ancestor of the block.
+func precacheTransaction(config *params.ChainConfig, bc ChainContext, author *common.Address, gaspool *GasPool, statedb *state.StateDB, header *types.Header, tx *types.Transaction, cfg vm.Config) error {
+	// Convert the transaction into an executable message and pre-cache its sender
+	from, err := types.Sender(types.MakeSigner(config, header.Number), tx)
+	if err != nil {
+		return err
+	}
+	msg := types.NewMessage(from, tx.To(), tx.Nonce(), tx.Value(), tx.Gas(), tx.GasPrice(), tx.FeeCurrency(), tx.GatewayFeeRecipient(), tx.GatewayFee(), tx.Data(), false)
+
+	// Create the EVM and execute the transaction
+	context := NewEVMContext(msg, header, bc, author)
+	vm := vm.NewEVM(context, statedb, config, cfg)
+
+	_, _, _, err = ApplyMessage(vm, msg, gaspool)
+	return err
+}
@@ -68,6 +68,7 @@ type StateTransition struct {
 	state           vm.StateDB
 	evm             *vm.EVM
 	gasPriceMinimum *big.Int
+	fees            *types.ReceiptFees
 }
 
 // Message represents a message sent to a contract.
@@ -395,7 +396,9 @@ func (st *StateTransition) distributeTxFees() error {
 	tipTxFee := new(big.Int).Sub(totalTxFee, baseTxFee)
 
 	// Pay gateway fee to the specified recipient.
+	gatewayFee := new(big.Int)
 	if st.msg.GatewayFeeRecipient() != nil {
+		gatewayFee.Set(st.msg.GatewayFee())
 		log.Trace("Crediting gateway fee", "recipient", *st.msg.GatewayFeeRecipient(), "amount", st.msg.GatewayFee(), "feeCurrency", st.msg.FeeCurrency())
 		if err := st.creditFee(*st.msg.GatewayFeeRecipient(), st.msg.GatewayFee(), st.msg.FeeCurrency()); err != nil {
 			log.Error("Failed to credit gateway fee", "err", err)
@@ -416,6 +419,7 @@ func (st *StateTransition) distributeTxFees() error {
 		}
 		log.Trace("Cannot credit gas fee to infrastructure fund: refunding fee to sender", "error", err, "fee", baseTxFee)
 		refund.Add(refund, baseTxFee)
+		baseTxFee = new(big.Int)
 	} else {
 		log.Trace("Crediting gas fee tip", "recipient", *governanceAddress, "amount", baseTxFee, "feeCurrency", st.msg.FeeCurrency())
 		if err = st.creditFee(*governanceAddress, baseTxFee, st.msg.FeeCurrency()); err != nil {
@@ -429,9 +433,24 @@ func (st *StateTransition) distributeTxFees() error {
 		log.Error("Failed to refund gas", "err", err)
 		return err
 	}
+
+	st.fees = &types.ReceiptFees{
+		FeeCurrency:         st.msg.FeeCurrency(),
+		GasPriceMinimum:     st.gasPriceMinimum,
+		BaseTxFee:           baseTxFee,
+		TipTxFee:            tipTxFee,
+		GatewayFeeRecipient: st.msg.GatewayFeeRecipient(),
+		GatewayFee:          gatewayFee,
+	}
 	return nil
 }
 
+// Fees returns the fees charged for the message, or nil if the state transition
+// did not get to distribute them.
+func (st *StateTransition) Fees() *types.ReceiptFees {
+	return st.fees
+}
+
 // refundGas adds unused gas back the state transition and gas pool.
 func (st *StateTransition) refundGas() {
 	refund := st.state.GetRefund()
@@ -0,0 +1,101 @@
+// Copyright 2019 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/ethash"
+	"github.com/ethereum/go-ethereum/contract_comm"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// Tests that the fee currency calls of a state transition see the chain head before
+// the fee currency context fork, and the block being processed from the fork on.
+func TestFeeCurrencyContext(t *testing.T) {
+	var (
+		feeCurrency = common.HexToAddress("0xfee")
+		sender      = common.HexToAddress("0x5e4de4")
+		recipient   = common.HexToAddress("0x4ec1")
+		// A fee currency answering every call, such as balanceOf, with the block number
+		feeCurrencyCode = []byte{byte(vm.NUMBER), byte(vm.PUSH1), 0, byte(vm.MSTORE), byte(vm.PUSH1), 32, byte(vm.PUSH1), 0, byte(vm.RETURN)}
+	)
+	tests := []struct {
+		name      string
+		fork      *big.Int
+		processed bool // whether the calls see the processed block rather than the head
+	}{
+		{name: "no_fork", fork: nil},
+		{name: "before_fork", fork: big.NewInt(6)},
+		{name: "at_fork", fork: big.NewInt(5), processed: true},
+		{name: "after_fork", fork: big.NewInt(0), processed: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			config := *params.TestChainConfig
+			config.FeeCurrencyContextBlock = test.fork
+
+			db := ethdb.NewMemDatabase()
+			genesis := (&Genesis{
+				Config: &config,
+				Alloc: GenesisAlloc{
+					feeCurrency: {Code: feeCurrencyCode, Balance: new(big.Int)},
+				},
+			}).MustCommit(db)
+			chain, err := NewBlockChain(db, nil, &config, ethash.NewFaker(), vm.Config{}, nil)
+			if err != nil {
+				t.Fatalf("failed to create chain: %v", err)
+			}
+			defer chain.Stop()
+			contract_comm.SetInternalEVMHandler(chain)
+
+			// Process block 5 on top of the genesis block, which is the head
+			header := &types.Header{
+				ParentHash: genesis.Hash(),
+				Number:     big.NewInt(5),
+				Time:       big.NewInt(50),
+				Difficulty: big.NewInt(1),
+				GasLimit:   genesis.GasLimit(),
+			}
+			statedb, _ := chain.State()
+			msg := types.NewMessage(sender, &recipient, 0, new(big.Int), 1000000, new(big.Int), &feeCurrency, nil, nil, nil, true)
+			evm := vm.NewEVM(NewEVMContext(msg, header, chain, &common.Address{}), statedb, &config, vm.Config{})
+
+			want := genesis.Header()
+			if test.processed {
+				want = header
+			}
+			if evm.GetHeader().Hash() != want.Hash() {
+				t.Fatalf("context header mismatch: have #%d, want #%d", evm.GetHeader().Number, want.Number)
+			}
+			// The sender's balance is the block number the fee currency sees, which is
+			// zero at the genesis block and too little to pay for fees
+			_, _, failed, err := ApplyMessage(evm, msg, new(GasPool).AddGas(header.GasLimit))
+			if test.processed && (err != nil || failed) {
+				t.Errorf("message failed at the processed block: err %v, failed %v", err, failed)
+			}
+			if !test.processed && err != errInsufficientBalanceForFees {
+				t.Errorf("error mismatch at the head: have %v, want %v", err, errInsufficientBalanceForFees)
+			}
+		})
+	}
+}
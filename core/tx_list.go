@@ -25,6 +25,7 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/contract_comm/currency"
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
 	"github.com/ethereum/go-ethereum/log"
 )
 
@@ -412,6 +413,9 @@ type txPricedList struct {
 	nonNilCurrencyHeaps map[common.Address]*priceHeap // Heap of prices of all the stored non-nil currency transactions
 	nilCurrencyHeap     *priceHeap                    // Heap of prices of all the stored nil currency transactions
 	stales              int                           // Number of stale price points to (re-heap trigger)
+
+	header *types.Header // Header of the block the prices of different currencies are compared at
+	state  vm.StateDB    // State of the block the prices of different currencies are compared at
 }
 
 // newTxPricedList creates a new price-sorted transaction heap.
@@ -436,6 +440,12 @@ func (l *txPricedList) getPriceHeap(tx *types.Transaction) *priceHeap {
 	}
 }
 
+// SetHead sets the block whose exchange rates are used to compare the prices of
+// transactions paying fees in different currencies.
+func (l *txPricedList) SetHead(header *types.Header, state vm.StateDB) {
+	l.header, l.state = header, state
+}
+
 // Put inserts a new transaction into the heap.
 func (l *txPricedList) Put(tx *types.Transaction) {
 	pHeap := l.getPriceHeap(tx)
@@ -488,7 +498,7 @@ func (l *txPricedList) Cap(cgThreshold *big.Int, local *accountSet) types.Transa
 			continue
 		}
 
-		if currency.Cmp(tx.GasPrice(), tx.FeeCurrency(), cgThreshold, nil) >= 0 {
+		if currency.Cmp(tx.GasPrice(), tx.FeeCurrency(), cgThreshold, nil, l.header, l.state) >= 0 {
 			save = append(save, tx)
 			break
 		}
@@ -530,7 +540,7 @@ func (l *txPricedList) Underpriced(tx *types.Transaction, local *accountSet) boo
 	}
 
 	cheapest := l.getMinPricedTx()
-	return currency.Cmp(cheapest.GasPrice(), cheapest.FeeCurrency(), tx.GasPrice(), tx.FeeCurrency()) >= 0
+	return currency.Cmp(cheapest.GasPrice(), cheapest.FeeCurrency(), tx.GasPrice(), tx.FeeCurrency(), l.header, l.state) >= 0
 }
 
 // Discard finds a number of most underpriced transactions, removes them from the
@@ -578,7 +588,7 @@ func (l *txPricedList) getHeapWithMinHead() (*priceHeap, *types.Transaction) {
 				cheapestTxn = []*types.Transaction(*cheapestHeap)[0]
 			} else {
 				txn := []*types.Transaction(*priceHeap)[0]
-				if currency.Cmp(txn.GasPrice(), txn.FeeCurrency(), cheapestTxn.GasPrice(), cheapestTxn.FeeCurrency()) < 0 {
+				if currency.Cmp(txn.GasPrice(), txn.FeeCurrency(), cheapestTxn.GasPrice(), cheapestTxn.FeeCurrency(), l.header, l.state) < 0 {
 					cheapestHeap = priceHeap
 				}
 			}
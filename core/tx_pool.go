@@ -43,6 +43,10 @@ import (
 const (
 	// chainHeadChanSize is the size of channel listening to ChainHeadEvent.
 	chainHeadChanSize = 10
+
+	// validateRetries is the number of times Validate pre-validates a transaction
+	// again after a reset raced with the pre-validation.
+	validateRetries = 3
 )
 
 var (
@@ -84,6 +88,10 @@ var (
 
 	// ErrNonWhitelistedFeeCurrency is returned if the txn fee currency is not white listed
 	ErrNonWhitelistedFeeCurrency = errors.New("non-whitelisted fee currency")
+
+	// errNoHeadState is returned if a transaction is validated before the pool
+	// has loaded the state of a chain head.
+	errNoHeadState = errors.New("transaction pool has no head state")
 )
 
 var (
@@ -109,6 +117,10 @@ var (
 	underpricedTxCounter = metrics.NewRegisteredCounter("txpool/underpriced", nil)
 )
 
+// feeCurrencyMetricsPrefix is the prefix of the per fee currency gauges, which are
+// registered lazily as new currencies show up in the pool.
+const feeCurrencyMetricsPrefix = "txpool/feecurrency/"
+
 // TxStatus is the current status of a transaction as seen by the pool.
 type TxStatus uint
 
@@ -232,6 +244,7 @@ type TxPool struct {
 	signer       types.Signer
 	mu           sync.RWMutex
 
+	currentHeader *types.Header       // Current head of the blockchain
 	currentState  *state.StateDB      // Current state in the blockchain head
 	pendingState  *state.ManagedState // Pending state tracking virtual nonces
 	currentMaxGas uint64              // Current gas limit for transaction caps
@@ -307,6 +320,7 @@ func (pool *TxPool) loop() {
 
 	// Start the stats reporting and transaction eviction tickers
 	var prevPending, prevQueued, prevStales int
+	reportedCurrencies := make(map[string]struct{})
 
 	report := time.NewTicker(statsReportInterval)
 	defer report.Stop()
@@ -343,9 +357,12 @@ func (pool *TxPool) loop() {
 		case <-report.C:
 			pool.mu.RLock()
 			pending, queued := pool.stats()
+			currencyPending, currencyQueued := pool.feeCurrencyStats()
 			stales := pool.priced.stales
 			pool.mu.RUnlock()
 
+			pool.reportFeeCurrencyStats(reportedCurrencies, currencyPending, currencyQueued)
+
 			if pending != prevPending || queued != prevQueued || stales != prevStales {
 				log.Debug("Transaction pool status report", "executable", pending, "queued", queued, "stales", stales)
 				prevPending, prevQueued, prevStales = pending, queued, stales
@@ -449,14 +466,16 @@ func (pool *TxPool) reset(oldHead, newHead *types.Header) {
 		log.Error("Failed to reset txpool state", "err", err)
 		return
 	}
+	pool.currentHeader = newHead
 	pool.currentState = statedb
 	pool.pendingState = state.ManageState(statedb)
 	pool.currentMaxGas = newHead.GasLimit
+	pool.priced.SetHead(newHead, statedb)
 
 	// Inject any transactions discarded due to reorgs
 	log.Debug("Reinjecting stale transactions", "count", len(reinject))
 	senderCacher.recover(pool.signer, reinject)
-	pool.addTxsLocked(reinject, false)
+	pool.addTxsLocked(reinject, false, nil)
 
 	// validate the pool of pending transactions, this will remove
 	// any transactions that have been included in the block or
@@ -547,6 +566,69 @@ func (pool *TxPool) stats() (int, int) {
 	return pending, queued
 }
 
+// feeCurrencyStats retrieves the number of pending and queued transactions per
+// fee currency. Transactions paying fees in Celo Gold are keyed by the nil address.
+func (pool *TxPool) feeCurrencyStats() (map[common.Address]int, map[common.Address]int) {
+	count := func(lists map[common.Address]*txList) map[common.Address]int {
+		counts := make(map[common.Address]int)
+		for _, list := range lists {
+			for _, tx := range list.txs.items {
+				var currency common.Address
+				if tx.FeeCurrency() != nil {
+					currency = *tx.FeeCurrency()
+				}
+				counts[currency]++
+			}
+		}
+		return counts
+	}
+	return count(pool.pending), count(pool.queue)
+}
+
+// reportFeeCurrencyStats updates the per fee currency gauges. Gauges of currencies
+// that are no longer present in the pool are reset to zero.
+func (pool *TxPool) reportFeeCurrencyStats(reported map[string]struct{}, pending, queued map[common.Address]int) {
+	nilCount, counts := pool.all.CurrencyCounts()
+
+	current := make(map[string]struct{})
+	update := func(currency common.Address, all uint64) {
+		name := feeCurrencyMetricsName(currency)
+		metrics.GetOrRegisterGauge(name+"/all", nil).Update(int64(all))
+		metrics.GetOrRegisterGauge(name+"/pending", nil).Update(int64(pending[currency]))
+		metrics.GetOrRegisterGauge(name+"/queued", nil).Update(int64(queued[currency]))
+		current[name] = struct{}{}
+	}
+	update(common.ZeroAddress, nilCount)
+	for currency, all := range counts {
+		update(currency, all)
+	}
+	for name := range reported {
+		if _, ok := current[name]; !ok {
+			metrics.GetOrRegisterGauge(name+"/all", nil).Update(0)
+			metrics.GetOrRegisterGauge(name+"/pending", nil).Update(0)
+			metrics.GetOrRegisterGauge(name+"/queued", nil).Update(0)
+			delete(reported, name)
+		}
+	}
+	for name := range current {
+		reported[name] = struct{}{}
+	}
+}
+
+// feeCurrencyMetricsName returns the metrics name prefix of a fee currency.
+func feeCurrencyMetricsName(currency common.Address) string {
+	if currency == common.ZeroAddress {
+		return feeCurrencyMetricsPrefix + "gold"
+	}
+	return feeCurrencyMetricsPrefix + currency.Hex()
+}
+
+// FeeCurrencyCounts returns the number of transactions tracked by the pool that
+// pay fees in Celo Gold and in each of the other fee currencies.
+func (pool *TxPool) FeeCurrencyCounts() (uint64, map[common.Address]uint64) {
+	return pool.all.CurrencyCounts()
+}
+
 // Content retrieves the data content of the transaction pool, returning all the
 // pending as well as queued transactions, grouped by account and sorted by nonce.
 func (pool *TxPool) Content() (map[common.Address]types.Transactions, map[common.Address]types.Transactions) {
@@ -602,70 +684,155 @@ func (pool *TxPool) local() map[common.Address]types.Transactions {
 	return txs
 }
 
+// txValidation is the outcome of validating a transaction against a head. The
+// checks depending on the mutable contents of the pool are left to the caller.
+type txValidation struct {
+	head        *types.Header  // Head header the transaction was validated against
+	gasPrice    *big.Int       // Minimal accepted gas price the transaction was validated against
+	from        common.Address // Recovered sender of the transaction
+	nonce       uint64         // Nonce of the sender at the head
+	err         error          // Error of the checks preceding the pricing checks
+	underpriced bool           // Whether the gas price is below the pool's threshold
+	stateErr    error          // Error of the checks following the nonce check
+}
+
 // validateTx checks whether a transaction is valid according to the consensus
 // rules and adheres to some heuristic limits of the local node (price and size).
 func (pool *TxPool) validateTx(tx *types.Transaction, local bool) error {
+	return pool.checkTx(tx, local, nil)
+}
+
+// checkTx completes the validation of a transaction with the checks depending on
+// the contents of the pool. If the transaction was not pre-validated against the
+// current head and minimal accepted gas price, it is validated from scratch. The
+// pool lock must be held.
+func (pool *TxPool) checkTx(tx *types.Transaction, local bool, validation *txValidation) error {
+	if pool.staleValidation(validation) {
+		validation = pool.validateTxAt(tx, pool.currentHeader, pool.currentState, pool.homestead, pool.gasPrice)
+	}
+	if validation.err != nil {
+		return validation.err
+	}
+	// Drop non-local transactions under our own minimal accepted gas price
+	local = local || pool.locals.contains(validation.from) // account may be local even if the transaction arrived from the network
+	if !local && validation.underpriced {
+		return ErrUnderpriced
+	}
+	// Ensure the transaction adheres to nonce ordering
+	if validation.nonce > tx.Nonce() {
+		return ErrNonceTooLow
+	}
+	return validation.stateErr
+}
+
+// staleValidation reports whether a pre-validation is missing or was made against
+// another head or minimal accepted gas price than the pool's current ones. The
+// pool lock must be held, at least for reading.
+func (pool *TxPool) staleValidation(validation *txValidation) bool {
+	return validation == nil || validation.head != pool.currentHeader || validation.gasPrice.Cmp(pool.gasPrice) != 0
+}
+
+// prevalidateTxs validates a batch of transactions against a copy of the current
+// head state without holding the pool lock, so that the contract calls reading
+// fee currency whitelists and balances don't serialize the pool. Senders are
+// recovered concurrently by the sender cacher.
+func (pool *TxPool) prevalidateTxs(txs []*types.Transaction) []*txValidation {
+	validations := make([]*txValidation, len(txs))
+
+	// Snapshot the head under the read lock, the copied state is then used
+	// without holding any lock
+	pool.mu.RLock()
+	header, homestead, gasPrice := pool.currentHeader, pool.homestead, pool.gasPrice
+	var statedb *state.StateDB
+	if pool.currentState != nil {
+		statedb = pool.currentState.Copy()
+	}
+	pool.mu.RUnlock()
+
+	if header == nil || statedb == nil {
+		return validations
+	}
+	if len(txs) > 1 {
+		senderCacher.recover(pool.signer, txs)
+	}
+	for i, tx := range txs {
+		// Skip the expensive checks for transactions that are already known
+		if pool.all.Get(tx.Hash()) != nil {
+			continue
+		}
+		validations[i] = pool.validateTxAt(tx, header, statedb, homestead, gasPrice)
+	}
+	return validations
+}
+
+// validateTxAt runs the checks of validateTx that only depend on the given head
+// and the pool's minimal accepted gas price.
+func (pool *TxPool) validateTxAt(tx *types.Transaction, header *types.Header, statedb *state.StateDB, homestead bool, gasPrice *big.Int) *txValidation {
+	validation := &txValidation{head: header, gasPrice: gasPrice}
+
 	// Heuristic limit, reject transactions over MaxCodeSize to prevent DOS attacks
 	if tx.Size() > params.MaxCodeSize {
-		return ErrOversizedData
+		validation.err = ErrOversizedData
+		return validation
 	}
 	// Transactions can't be negative. This may never happen using RLP decoded
 	// transactions but may occur if you create a transaction using the RPC.
 	if tx.Value().Sign() < 0 {
-		return ErrNegativeValue
+		validation.err = ErrNegativeValue
+		return validation
 	}
 	// Ensure the transaction doesn't exceed the current block limit gas.
-	if pool.currentMaxGas < tx.Gas() {
-		log.Debug("max gas limit exceeded", "pool.currentMaxGas", pool.currentMaxGas, "tx.Gas()", tx.Gas())
-		return ErrGasLimit
+	if header.GasLimit < tx.Gas() {
+		log.Debug("max gas limit exceeded", "gasLimit", header.GasLimit, "tx.Gas()", tx.Gas())
+		validation.err = ErrGasLimit
+		return validation
 	}
 	// Make sure the transaction is signed properly
 	from, err := types.Sender(pool.signer, tx)
 	if err != nil {
-		return ErrInvalidSender
+		validation.err = ErrInvalidSender
+		return validation
 	}
+	validation.from = from
+	validation.nonce = statedb.GetNonce(from)
 
 	// Ensure the fee currency is native or whitelisted.
-	if tx.FeeCurrency() != nil && !currency.IsWhitelisted(*tx.FeeCurrency(), nil, nil) {
-		return ErrNonWhitelistedFeeCurrency
+	if tx.FeeCurrency() != nil && !currency.IsWhitelisted(*tx.FeeCurrency(), header, statedb) {
+		validation.err = ErrNonWhitelistedFeeCurrency
+		return validation
 	}
+	validation.underpriced = currency.Cmp(gasPrice, nil, tx.GasPrice(), tx.FeeCurrency(), header, statedb) > 0
 
-	// Drop non-local transactions under our own minimal accepted gas price
-	local = local || pool.locals.contains(from) // account may be local even if the transaction arrived from the network
-	if !local && currency.Cmp(pool.gasPrice, nil, tx.GasPrice(), tx.FeeCurrency()) > 0 {
-		return ErrUnderpriced
-	}
-	// Ensure the transaction adheres to nonce ordering
-	if pool.currentState.GetNonce(from) > tx.Nonce() {
-		return ErrNonceTooLow
-	}
 	// Transactor should have enough funds to cover the costs
-	err = ValidateTransactorBalanceCoversTx(tx, from, pool.currentState)
-	if err != nil {
-		return err
+	if err := ValidateTransactorBalanceCoversTx(tx, from, header, statedb); err != nil {
+		validation.stateErr = err
+		return validation
 	}
-	intrGas, err := IntrinsicGas(tx.Data(), tx.To() == nil, pool.homestead, pool.chain.CurrentBlock().Header(), pool.currentState, tx.FeeCurrency())
+	intrGas, err := IntrinsicGas(tx.Data(), tx.To() == nil, homestead, header, statedb, tx.FeeCurrency())
 	if err != nil {
 		log.Debug("validateTx gas less than intrinsic gas", "intrGas", intrGas, "err", err)
-		return err
+		validation.stateErr = err
+		return validation
 	}
 	if tx.Gas() < intrGas {
 		log.Debug("validateTx gas less than intrinsic gas", "tx.Gas", tx.Gas(), "intrinsic Gas", intrGas)
-		return ErrIntrinsicGas
+		validation.stateErr = ErrIntrinsicGas
+		return validation
 	}
 
-	gasPriceMinimum, err := gpm.GetGasPriceMinimum(tx.FeeCurrency(), nil, nil)
+	gasPriceMinimum, err := gpm.GetGasPriceMinimum(tx.FeeCurrency(), header, statedb)
 	if err != nil && err != ccerrors.ErrSmartContractNotDeployed && err != ccerrors.ErrRegistryContractNotDeployed {
 		log.Debug("unable to fetch gas price minimum", "err", err)
-		return err
+		validation.stateErr = err
+		return validation
 	}
 
 	if tx.GasPrice().Cmp(gasPriceMinimum) == -1 {
 		log.Debug("gas price less than current gas price minimum", "gasPrice", tx.GasPrice(), "gasPriceMinimum", gasPriceMinimum)
-		return ErrGasPriceDoesNotExceedMinimum
+		validation.stateErr = ErrGasPriceDoesNotExceedMinimum
+		return validation
 	}
-
-	return nil
+	return validation
 }
 
 // add validates a transaction and inserts it into the non-executable queue for
@@ -677,6 +844,12 @@ func (pool *TxPool) validateTx(tx *types.Transaction, local bool) error {
 // whitelisted, preventing any associated transaction from being dropped out of
 // the pool due to pricing constraints.
 func (pool *TxPool) add(tx *types.Transaction, local bool) (bool, error) {
+	return pool.addValidated(tx, local, nil)
+}
+
+// addValidated is add for a transaction that may have been pre-validated outside
+// of the pool lock, in which case only the remaining checks are run.
+func (pool *TxPool) addValidated(tx *types.Transaction, local bool, validation *txValidation) (bool, error) {
 	// If the transaction is already known, discard it
 	hash := tx.Hash()
 	if pool.all.Get(hash) != nil {
@@ -684,7 +857,7 @@ func (pool *TxPool) add(tx *types.Transaction, local bool) (bool, error) {
 		return false, fmt.Errorf("known transaction: %x", hash)
 	}
 	// If the transaction fails basic validation, discard it
-	if err := pool.validateTx(tx, local); err != nil {
+	if err := pool.checkTx(tx, local, validation); err != nil {
 		log.Debug("Discarding invalid transaction", "hash", hash, "err", err)
 		invalidTxCounter.Inc(1)
 		return false, err
@@ -858,11 +1031,14 @@ func (pool *TxPool) AddRemotes(txs []*types.Transaction) []error {
 
 // addTx enqueues a single transaction into the pool if it is valid.
 func (pool *TxPool) addTx(tx *types.Transaction, local bool) error {
+	// Run the expensive checks before taking the lock
+	validation := pool.prevalidateTxs([]*types.Transaction{tx})[0]
+
 	pool.mu.Lock()
 	defer pool.mu.Unlock()
 
 	// Try to inject the transaction and update any state
-	replace, err := pool.add(tx, local)
+	replace, err := pool.addValidated(tx, local, validation)
 	if err != nil {
 		return err
 	}
@@ -876,22 +1052,30 @@ func (pool *TxPool) addTx(tx *types.Transaction, local bool) error {
 
 // addTxs attempts to queue a batch of transactions if they are valid.
 func (pool *TxPool) addTxs(txs []*types.Transaction, local bool) []error {
+	// Run the expensive checks before taking the lock
+	validations := pool.prevalidateTxs(txs)
+
 	pool.mu.Lock()
 	defer pool.mu.Unlock()
 
-	return pool.addTxsLocked(txs, local)
+	return pool.addTxsLocked(txs, local, validations)
 }
 
 // addTxsLocked attempts to queue a batch of transactions if they are valid,
-// whilst assuming the transaction pool lock is already held.
-func (pool *TxPool) addTxsLocked(txs []*types.Transaction, local bool) []error {
+// whilst assuming the transaction pool lock is already held. The validations,
+// if not nil, are the outcomes of pre-validating the transactions.
+func (pool *TxPool) addTxsLocked(txs []*types.Transaction, local bool, validations []*txValidation) []error {
 	// Add the batch of transactions, tracking the accepted ones
 	dirty := make(map[common.Address]struct{})
 	errs := make([]error, len(txs))
 
 	for i, tx := range txs {
+		var validation *txValidation
+		if validations != nil {
+			validation = validations[i]
+		}
 		var replace bool
-		if replace, errs[i] = pool.add(tx, local); errs[i] == nil && !replace {
+		if replace, errs[i] = pool.addValidated(tx, local, validation); errs[i] == nil && !replace {
 			from, _ := types.Sender(pool.signer, tx) // already validated
 			dirty[from] = struct{}{}
 		}
@@ -907,6 +1091,62 @@ func (pool *TxPool) addTxsLocked(txs []*types.Transaction, local bool) []error {
 	return errs
 }
 
+// Validate checks whether a remote transaction would be accepted by the pool
+// without adding it, so that light clients can learn why a transaction would be
+// dropped before relaying it.
+func (pool *TxPool) Validate(tx *types.Transaction) error {
+	hash := tx.Hash()
+	validated := false
+	for i := 0; i < validateRetries && !validated; i++ {
+		validation := pool.prevalidateTxs([]*types.Transaction{tx})[0]
+
+		pool.mu.RLock()
+		if pool.all.Get(hash) != nil {
+			pool.mu.RUnlock()
+			return fmt.Errorf("known transaction: %x", hash)
+		}
+		if validation == nil {
+			pool.mu.RUnlock()
+			return errNoHeadState
+		}
+		// Validating against the pool's own head state would need the write lock,
+		// so pre-validate again if a reset raced with the pre-validation
+		if pool.staleValidation(validation) {
+			pool.mu.RUnlock()
+			continue
+		}
+		err := pool.checkTx(tx, false, validation)
+		full := uint64(pool.all.Count()) >= pool.config.GlobalSlots+pool.config.GlobalQueue
+		pool.mu.RUnlock()
+
+		if err != nil || !full {
+			return err
+		}
+		validated = true
+	}
+	// A full pool only accepts transactions paying more than the cheapest ones,
+	// and looking these up prunes the priced list
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+
+	if !validated {
+		// Resets kept racing with the pre-validation, validate under the write lock
+		if pool.all.Get(hash) != nil {
+			return fmt.Errorf("known transaction: %x", hash)
+		}
+		if err := pool.checkTx(tx, false, nil); err != nil {
+			return err
+		}
+		if uint64(pool.all.Count()) < pool.config.GlobalSlots+pool.config.GlobalQueue {
+			return nil
+		}
+	}
+	if pool.priced.Underpriced(tx, pool.locals) {
+		return ErrUnderpriced
+	}
+	return nil
+}
+
 // Status returns the status (unknown/pending/queued) of a batch of transactions
 // identified by their hashes.
 func (pool *TxPool) Status(hashes []common.Hash) []TxStatus {
@@ -1198,7 +1438,8 @@ func (pool *TxPool) demoteUnexecutables() {
 }
 
 // ValidateTransactorBalanceCoversTx validates transactor has enough funds to cover transaction cost: V + GP * GL.
-func ValidateTransactorBalanceCoversTx(tx *types.Transaction, from common.Address, currentState *state.StateDB) error {
+// Fee currency balances are read at the given header and state.
+func ValidateTransactorBalanceCoversTx(tx *types.Transaction, from common.Address, header *types.Header, currentState *state.StateDB) error {
 	if tx.FeeCurrency() == nil && currentState.GetBalance(from).Cmp(tx.Cost()) < 0 {
 		log.Debug("Insufficient funds",
 			"from", from, "Transaction cost", tx.Cost(), "to", tx.To(),
@@ -1206,7 +1447,7 @@ func ValidateTransactorBalanceCoversTx(tx *types.Transaction, from common.Addres
 			"value", tx.Value(), "fee currency", tx.FeeCurrency(), "balance", currentState.GetBalance(from))
 		return ErrInsufficientFunds
 	} else if tx.FeeCurrency() != nil {
-		feeCurrencyBalance, _, err := currency.GetBalanceOf(from, *tx.FeeCurrency(), params.MaxGasToReadErc20Balance, nil, nil)
+		feeCurrencyBalance, _, err := currency.GetBalanceOf(from, *tx.FeeCurrency(), params.MaxGasToReadErc20Balance, header, currentState)
 
 		if err != nil {
 			log.Debug("validateTx error in getting fee currency balance", "feeCurrency", tx.FeeCurrency(), "error", err)
@@ -1355,6 +1596,19 @@ func (t *txLookup) Add(tx *types.Transaction) {
 	t.all[tx.Hash()] = tx
 }
 
+// CurrencyCounts returns the number of transactions paying fees in Celo Gold and
+// a copy of the per currency counts of the transactions paying in other currencies.
+func (t *txLookup) CurrencyCounts() (uint64, map[common.Address]uint64) {
+	t.lock.RLock()
+	defer t.lock.RUnlock()
+
+	counts := make(map[common.Address]uint64, len(t.nonNilCurrencyTxCurrCount))
+	for currency, count := range t.nonNilCurrencyTxCurrCount {
+		counts[currency] = count
+	}
+	return t.nilCurrencyTxCurrCount, counts
+}
+
 // Remove removes a transaction from the lookup.
 func (t *txLookup) Remove(hash common.Hash) {
 	t.lock.Lock()
@@ -1363,7 +1617,11 @@ func (t *txLookup) Remove(hash common.Hash) {
 	if t.all[hash].FeeCurrency() == nil {
 		t.nilCurrencyTxCurrCount--
 	} else {
-		t.nonNilCurrencyTxCurrCount[*t.all[hash].FeeCurrency()]--
+		currency := *t.all[hash].FeeCurrency()
+		t.nonNilCurrencyTxCurrCount[currency]--
+		if t.nonNilCurrencyTxCurrCount[currency] == 0 {
+			delete(t.nonNilCurrencyTxCurrCount, currency)
+		}
 	}
 
 	delete(t.all, hash)
@@ -292,6 +292,99 @@ func TestInvalidTransactions(t *testing.T) {
 	}
 }
 
+// Tests that transactions pre-validated against a head that has since been
+// replaced are validated again against the new head before insertion.
+func TestTransactionPrevalidationStaleHead(t *testing.T) {
+	t.Parallel()
+
+	pool, key := setupTxPool()
+	defer pool.Stop()
+
+	tx := transaction(0, 100000, key)
+	from, _ := deriveSender(tx)
+
+	// Pre-validate without funds, the outcome should carry the balance error
+	validation := pool.prevalidateTxs([]*types.Transaction{tx})[0]
+	if validation.from != from {
+		t.Fatalf("sender mismatch: have %x, want %x", validation.from, from)
+	}
+	if validation.stateErr != ErrInsufficientFunds {
+		t.Fatalf("pre-validation error mismatch: have %v, want %v", validation.stateErr, ErrInsufficientFunds)
+	}
+	// Fund the account and move to a new head, the stale outcome must be discarded
+	pool.mu.Lock()
+	pool.currentState.AddBalance(from, big.NewInt(0xffffffffffffff))
+	pool.currentHeader = types.CopyHeader(pool.currentHeader)
+	_, err := pool.addValidated(tx, false, validation)
+	pool.mu.Unlock()
+
+	if err != nil {
+		t.Fatalf("failed to add transaction pre-validated against stale head: %v", err)
+	}
+	if pool.all.Get(tx.Hash()) == nil {
+		t.Fatalf("transaction missing from the pool")
+	}
+}
+
+// Tests that transactions pre-validated against a minimal accepted gas price that
+// has since been raised are priced again before insertion.
+func TestTransactionPrevalidationStaleGasPrice(t *testing.T) {
+	t.Parallel()
+
+	pool, key := setupTxPool()
+	defer pool.Stop()
+
+	tx := pricedTransaction(0, 100000, big.NewInt(2), key)
+	from, _ := deriveSender(tx)
+	pool.currentState.AddBalance(from, big.NewInt(0xffffffffffffff))
+
+	validation := pool.prevalidateTxs([]*types.Transaction{tx})[0]
+	if validation.underpriced {
+		t.Fatalf("transaction underpriced at the initial gas price")
+	}
+	// Raise the gas price before insertion, the stale pricing must be discarded
+	pool.SetGasPrice(big.NewInt(3))
+
+	pool.mu.Lock()
+	_, err := pool.addValidated(tx, false, validation)
+	pool.mu.Unlock()
+
+	if err != ErrUnderpriced {
+		t.Fatalf("error mismatch: have %v, want %v", err, ErrUnderpriced)
+	}
+}
+
+// Tests that validating a transaction fails instead of retrying forever while the
+// pool has no head state to pre-validate against.
+func TestTransactionValidateWithoutHeadState(t *testing.T) {
+	t.Parallel()
+
+	pool, key := setupTxPool()
+	defer pool.Stop()
+
+	tx := transaction(0, 100000, key)
+	from, _ := deriveSender(tx)
+	pool.currentState.AddBalance(from, big.NewInt(0xffffffffffffff))
+
+	if err := pool.Validate(tx); err != nil {
+		t.Fatalf("failed to validate funded transaction: %v", err)
+	}
+	pool.mu.Lock()
+	pool.currentState = nil
+	pool.mu.Unlock()
+
+	errc := make(chan error, 1)
+	go func() { errc <- pool.Validate(tx) }()
+	select {
+	case err := <-errc:
+		if err != errNoHeadState {
+			t.Fatalf("error mismatch: have %v, want %v", err, errNoHeadState)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("validation without head state did not return")
+	}
+}
+
 func TestTransactionQueue(t *testing.T) {
 	t.Parallel()
 
@@ -1865,3 +1958,42 @@ func benchmarkPoolBatchInsert(b *testing.B, size int) {
 		pool.AddRemotes(batch)
 	}
 }
+
+// Tests that the lookup keeps track of the number of transactions per fee
+// currency and forgets currencies without any transactions left.
+func TestTxLookupCurrencyCounts(t *testing.T) {
+	t.Parallel()
+
+	key, _ := crypto.GenerateKey()
+	cUSD := common.HexToAddress("0x000000000000000000000000000000000000ce10")
+	currencyTx := func(nonce uint64) *types.Transaction {
+		tx, _ := types.SignTx(types.NewTransaction(nonce, common.Address{}, big.NewInt(100), 100000, big.NewInt(1), &cUSD, nil, nil, nil), types.HomesteadSigner{}, key)
+		return tx
+	}
+
+	lookup := newTxLookup()
+	gold, cusd1, cusd2 := transaction(0, 100000, key), currencyTx(1), currencyTx(2)
+	lookup.Add(gold)
+	lookup.Add(cusd1)
+	lookup.Add(cusd2)
+
+	nilCount, counts := lookup.CurrencyCounts()
+	if nilCount != 1 {
+		t.Errorf("gold transaction count mismatch: have %d, want %d", nilCount, 1)
+	}
+	if counts[cUSD] != 2 {
+		t.Errorf("currency transaction count mismatch: have %d, want %d", counts[cUSD], 2)
+	}
+	// The returned counts must be a copy
+	counts[cUSD] = 10
+	if _, counts = lookup.CurrencyCounts(); counts[cUSD] != 2 {
+		t.Errorf("modifying the returned counts should not modify the lookup")
+	}
+
+	lookup.Remove(cusd1.Hash())
+	lookup.Remove(cusd2.Hash())
+	lookup.Remove(gold.Hash())
+	if nilCount, counts = lookup.CurrencyCounts(); nilCount != 0 || len(counts) != 0 {
+		t.Errorf("lookup should be empty: have %d gold transactions, %d currencies", nilCount, len(counts))
+	}
+}
@@ -20,6 +20,7 @@ import (
 	"bytes"
 	"fmt"
 	"io"
+	"math/big"
 	"unsafe"
 
 	"github.com/ethereum/go-ethereum/common"
@@ -28,6 +29,7 @@ import (
 )
 
 //go:generate gencodec -type Receipt -field-override receiptMarshaling -out gen_receipt_json.go
+//go:generate gencodec -type ReceiptFees -field-override receiptFeesMarshaling -out gen_receipt_fees_json.go
 
 var (
 	receiptStatusFailedRLP     = []byte{}
@@ -55,6 +57,31 @@ type Receipt struct {
 	TxHash          common.Hash    `json:"transactionHash" gencodec:"required"`
 	ContractAddress common.Address `json:"contractAddress"`
 	GasUsed         uint64         `json:"gasUsed" gencodec:"required"`
+	Fees            *ReceiptFees   `json:"fees,omitempty"`
+}
+
+// ReceiptFees records the fees charged for a transaction as they were distributed
+// during its execution. All amounts are denominated in the fee currency.
+type ReceiptFees struct {
+	FeeCurrency         *common.Address `json:"feeCurrency"         rlp:"nil"`
+	GasPriceMinimum     *big.Int        `json:"gasPriceMinimum"     gencodec:"required"`
+	BaseTxFee           *big.Int        `json:"baseTxFee"           gencodec:"required"` // Credited to the infrastructure fund
+	TipTxFee            *big.Int        `json:"tipTxFee"            gencodec:"required"` // Credited to the block's coinbase
+	GatewayFeeRecipient *common.Address `json:"gatewayFeeRecipient" rlp:"nil"`
+	GatewayFee          *big.Int        `json:"gatewayFee"          gencodec:"required"`
+}
+
+type receiptFeesMarshaling struct {
+	GasPriceMinimum *hexutil.Big
+	BaseTxFee       *hexutil.Big
+	TipTxFee        *hexutil.Big
+	GatewayFee      *hexutil.Big
+}
+
+// TotalFee returns the total amount debited from the sender for the transaction.
+func (f *ReceiptFees) TotalFee() *big.Int {
+	total := new(big.Int).Add(f.BaseTxFee, f.TipTxFee)
+	return total.Add(total, f.GatewayFee)
 }
 
 type receiptMarshaling struct {
@@ -80,6 +107,7 @@ type receiptStorageRLP struct {
 	ContractAddress   common.Address
 	Logs              []*LogForStorage
 	GasUsed           uint64
+	Fees              []*ReceiptFees `rlp:"tail"` // Empty for receipts stored before fees were recorded
 }
 
 // NewReceipt creates a barebone transaction receipt, copying the init fields.
@@ -168,6 +196,9 @@ func (r *ReceiptForStorage) EncodeRLP(w io.Writer) error {
 	for i, log := range r.Logs {
 		enc.Logs[i] = (*LogForStorage)(log)
 	}
+	if r.Fees != nil {
+		enc.Fees = []*ReceiptFees{r.Fees}
+	}
 	return rlp.Encode(w, enc)
 }
 
@@ -190,6 +221,9 @@ func (r *ReceiptForStorage) DecodeRLP(s *rlp.Stream) error {
 
 	// Assign the implementation fields
 	r.TxHash, r.ContractAddress, r.GasUsed = dec.TxHash, dec.ContractAddress, dec.GasUsed
+	if len(dec.Fees) > 0 {
+		r.Fees = dec.Fees[0]
+	}
 
 	return nil
 }
@@ -0,0 +1,170 @@
+// Copyright 2019 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package types
+
+import (
+	"encoding/json"
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+func newFeesTestReceipt(fees *ReceiptFees) *Receipt {
+	return &Receipt{
+		Status:            ReceiptStatusSuccessful,
+		CumulativeGasUsed: 1,
+		Logs: []*Log{
+			{Address: common.BytesToAddress([]byte{0x11})},
+		},
+		TxHash:          common.BytesToHash([]byte{0x11, 0x11}),
+		ContractAddress: common.BytesToAddress([]byte{0x01, 0x11, 0x11}),
+		GasUsed:         111111,
+		Fees:            fees,
+	}
+}
+
+// feesEqual reports whether two fee records hold the same values.
+func feesEqual(a, b *ReceiptFees) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return reflect.DeepEqual(a.FeeCurrency, b.FeeCurrency) &&
+		reflect.DeepEqual(a.GatewayFeeRecipient, b.GatewayFeeRecipient) &&
+		a.GasPriceMinimum.Cmp(b.GasPriceMinimum) == 0 &&
+		a.BaseTxFee.Cmp(b.BaseTxFee) == 0 &&
+		a.TipTxFee.Cmp(b.TipTxFee) == 0 &&
+		a.GatewayFee.Cmp(b.GatewayFee) == 0
+}
+
+// Tests that the fees of a receipt survive a storage encoding round trip.
+func TestReceiptFeesStorage(t *testing.T) {
+	feeCurrency := common.HexToAddress("0x000000000000000000000000000000000000ce10")
+	gatewayFeeRecipient := common.HexToAddress("0x00000000000000000000000000000000000000fe")
+
+	tests := []*ReceiptFees{
+		nil,
+		{
+			GasPriceMinimum: big.NewInt(1),
+			BaseTxFee:       big.NewInt(111111),
+			TipTxFee:        big.NewInt(0),
+			GatewayFee:      big.NewInt(0),
+		},
+		{
+			FeeCurrency:         &feeCurrency,
+			GasPriceMinimum:     big.NewInt(5),
+			BaseTxFee:           big.NewInt(555555),
+			TipTxFee:            big.NewInt(222222),
+			GatewayFeeRecipient: &gatewayFeeRecipient,
+			GatewayFee:          big.NewInt(10000),
+		},
+	}
+	for i, fees := range tests {
+		enc, err := rlp.EncodeToBytes((*ReceiptForStorage)(newFeesTestReceipt(fees)))
+		if err != nil {
+			t.Fatalf("test %d: failed to encode receipt: %v", i, err)
+		}
+		var dec ReceiptForStorage
+		if err := rlp.DecodeBytes(enc, &dec); err != nil {
+			t.Fatalf("test %d: failed to decode receipt: %v", i, err)
+		}
+		if !feesEqual(dec.Fees, fees) {
+			t.Errorf("test %d: fees mismatch: have %+v, want %+v", i, dec.Fees, fees)
+		}
+	}
+}
+
+// Tests that receipts stored before the fees were recorded can still be decoded.
+func TestReceiptFeesLegacyStorage(t *testing.T) {
+	receipt := newFeesTestReceipt(nil)
+
+	legacy := struct {
+		PostStateOrStatus []byte
+		CumulativeGasUsed uint64
+		Bloom             Bloom
+		TxHash            common.Hash
+		ContractAddress   common.Address
+		Logs              []*LogForStorage
+		GasUsed           uint64
+	}{
+		PostStateOrStatus: receipt.statusEncoding(),
+		CumulativeGasUsed: receipt.CumulativeGasUsed,
+		TxHash:            receipt.TxHash,
+		ContractAddress:   receipt.ContractAddress,
+		Logs:              []*LogForStorage{(*LogForStorage)(receipt.Logs[0])},
+		GasUsed:           receipt.GasUsed,
+	}
+	enc, err := rlp.EncodeToBytes(legacy)
+	if err != nil {
+		t.Fatalf("failed to encode legacy receipt: %v", err)
+	}
+	var dec ReceiptForStorage
+	if err := rlp.DecodeBytes(enc, &dec); err != nil {
+		t.Fatalf("failed to decode legacy receipt: %v", err)
+	}
+	if dec.Fees != nil {
+		t.Errorf("legacy receipt should have no fees, have %+v", dec.Fees)
+	}
+	if dec.GasUsed != receipt.GasUsed || dec.TxHash != receipt.TxHash {
+		t.Errorf("legacy receipt mismatch: have %+v, want %+v", dec, receipt)
+	}
+}
+
+// Tests that the fees are not part of the consensus encoding of a receipt.
+func TestReceiptFeesConsensusEncoding(t *testing.T) {
+	without, _ := rlp.EncodeToBytes(newFeesTestReceipt(nil))
+	with, _ := rlp.EncodeToBytes(newFeesTestReceipt(&ReceiptFees{
+		GasPriceMinimum: big.NewInt(1),
+		BaseTxFee:       big.NewInt(2),
+		TipTxFee:        big.NewInt(3),
+		GatewayFee:      big.NewInt(4),
+	}))
+	if string(with) != string(without) {
+		t.Errorf("fees changed the consensus encoding of the receipt")
+	}
+}
+
+func TestReceiptFeesJSON(t *testing.T) {
+	feeCurrency := common.HexToAddress("0x000000000000000000000000000000000000ce10")
+	fees := &ReceiptFees{
+		FeeCurrency:     &feeCurrency,
+		GasPriceMinimum: big.NewInt(5),
+		BaseTxFee:       big.NewInt(500),
+		TipTxFee:        big.NewInt(20),
+		GatewayFee:      big.NewInt(0),
+	}
+	enc, err := json.Marshal(fees)
+	if err != nil {
+		t.Fatalf("failed to marshal fees: %v", err)
+	}
+	want := `{"feeCurrency":"0x000000000000000000000000000000000000ce10","gasPriceMinimum":"0x5","baseTxFee":"0x1f4","tipTxFee":"0x14","gatewayFeeRecipient":null,"gatewayFee":"0x0"}`
+	if string(enc) != want {
+		t.Errorf("JSON mismatch:\nhave %s\nwant %s", enc, want)
+	}
+	var dec ReceiptFees
+	if err := json.Unmarshal(enc, &dec); err != nil {
+		t.Fatalf("failed to unmarshal fees: %v", err)
+	}
+	if !feesEqual(&dec, fees) {
+		t.Errorf("fees mismatch: have %+v, want %+v", dec, fees)
+	}
+	if total := dec.TotalFee(); total.Cmp(big.NewInt(520)) != 0 {
+		t.Errorf("total fee mismatch: have %v, want %v", total, 520)
+	}
+}
@@ -58,6 +58,9 @@ var proofOfPossessionAddress = common.BytesToAddress(append([]byte{0}, (CeloPrec
 var getValidatorAddress = common.BytesToAddress(append([]byte{0}, (CeloPrecompiledContractsAddressOffset - 5)))
 var numberValidatorsAddress = common.BytesToAddress(append([]byte{0}, (CeloPrecompiledContractsAddressOffset - 6)))
 var epochSizeAddress = common.BytesToAddress(append([]byte{0}, (CeloPrecompiledContractsAddressOffset - 7)))
+var getExchangeRateAddress = common.BytesToAddress(append([]byte{0}, (CeloPrecompiledContractsAddressOffset - 8)))
+var blsVerifySignatureAddress = common.BytesToAddress(append([]byte{0}, (CeloPrecompiledContractsAddressOffset - 9)))
+var blsVerifyAggregatedSignatureAddress = common.BytesToAddress(append([]byte{0}, (CeloPrecompiledContractsAddressOffset - 10)))
 
 // PrecompiledContractsByzantium contains the default set of pre-compiled Ethereum
 // contracts used in the Byzantium release.
@@ -78,6 +81,10 @@ var PrecompiledContractsByzantium = map[common.Address]PrecompiledContract{
 	getValidatorAddress:      &getValidator{},
 	numberValidatorsAddress:  &numberValidators{},
 	epochSizeAddress:         &epochSize{},
+	getExchangeRateAddress:   &getExchangeRate{},
+
+	blsVerifySignatureAddress:           &blsVerifySignature{},
+	blsVerifyAggregatedSignatureAddress: &blsVerifyAggregatedSignature{},
 }
 
 // RunPrecompiledContract runs and evaluates the output of a precompiled contract.
@@ -669,3 +676,166 @@ func (c *epochSize) Run(input []byte, caller common.Address, evm *EVM, gas uint6
 
 	return epochSizeBytes, gas, nil
 }
+
+type getExchangeRate struct{}
+
+func (c *getExchangeRate) RequiredGas(input []byte) uint64 {
+	return params.GetExchangeRateGas
+}
+
+// Return the exchange rate between Celo Gold and the given fee currency, as reported by the
+// SortedOracles medianRate. The zero address denotes Celo Gold itself, whose rate is 1/1.
+func (c *getExchangeRate) Run(input []byte, caller common.Address, evm *EVM, gas uint64) ([]byte, uint64, error) {
+	gas, err := debitRequiredGas(c, input, gas)
+	if err != nil {
+		return nil, gas, err
+	}
+
+	// input is comprised of a single argument:
+	//   token: 32 bytes representing the address of the fee currency
+	if len(input) != 32 {
+		return nil, gas, ErrInputLength
+	}
+	token := common.BytesToAddress(input[0:32])
+
+	numerator, denominator := big.NewInt(1), big.NewInt(1)
+	if token != common.ZeroAddress {
+		numerator, denominator, err = GetExchangeRateWithEvm(token, evm)
+		if err != nil {
+			return nil, gas, err
+		}
+		// SortedOracles reports a zero denominator for tokens without any rates.
+		if denominator.Sign() == 0 {
+			return nil, gas, ErrUnknownFeeCurrency
+		}
+	}
+
+	numeratorPadded := common.LeftPadBytes(numerator.Bytes(), 32)
+	denominatorPadded := common.LeftPadBytes(denominator.Bytes(), 32)
+
+	return append(numeratorPadded, denominatorPadded...), gas, nil
+}
+
+// parseBLSMessage splits the trailing part of a BLS verification input into the hasher flag and the message.
+//   useCompositeHasher: 32 bytes, 256 bit integer, 1 to hash the message with the composite hasher, 0 otherwise
+//   message:            the remaining bytes, the message that was signed
+func parseBLSMessage(input []byte) (bool, []byte, error) {
+	if len(input) < 32 {
+		return false, nil, ErrInputLength
+	}
+	flag := new(big.Int).SetBytes(input[0:32])
+	if flag.Cmp(common.Big1) > 0 {
+		return false, nil, fmt.Errorf("Error parsing input: invalid useCompositeHasher value %s", hexutil.Encode(input[0:32]))
+	}
+	return flag.Sign() != 0, input[32:], nil
+}
+
+// blsMessageGas returns the cost of hashing the message that follows the given offset of a BLS
+// verification input. Only the composite hasher is charged per word: the direct hasher is a single
+// Blake2 pass, which the base cost covers.
+func blsMessageGas(input []byte, offset int) uint64 {
+	if len(input) < offset {
+		return 0
+	}
+	useCompositeHasher, message, err := parseBLSMessage(input[offset:])
+	if err != nil || !useCompositeHasher {
+		return 0
+	}
+	return uint64(len(message)+31) / 32 * params.BlsVerifyPerWordGas
+}
+
+// Verifies a BLS signature over an arbitrary message, using the same primitives as consensus.
+type blsVerifySignature struct{}
+
+func (c *blsVerifySignature) RequiredGas(input []byte) uint64 {
+	return params.BlsVerifySignatureGas + blsMessageGas(input, blscrypto.PUBLICKEYBYTES+blscrypto.SIGNATUREBYTES)
+}
+
+func (c *blsVerifySignature) Run(input []byte, caller common.Address, evm *EVM, gas uint64) ([]byte, uint64, error) {
+	gas, err := debitRequiredGas(c, input, gas)
+	if err != nil {
+		return nil, gas, err
+	}
+
+	// input is comprised of 4 arguments:
+	//   publicKey:          48 bytes, representing the public key (defined as a const in bls package)
+	//   signature:          96 bytes, representing the signature on `message` (defined as a const in bls package)
+	//   useCompositeHasher: 32 bytes, see parseBLSMessage
+	//   message:            the remaining bytes, see parseBLSMessage
+	prefixLength := blscrypto.PUBLICKEYBYTES + blscrypto.SIGNATUREBYTES
+	if len(input) < prefixLength {
+		return nil, gas, ErrInputLength
+	}
+	publicKey := input[:blscrypto.PUBLICKEYBYTES]
+	signature := input[blscrypto.PUBLICKEYBYTES:prefixLength]
+
+	shouldUseCompositeHasher, message, err := parseBLSMessage(input[prefixLength:])
+	if err != nil {
+		return nil, gas, err
+	}
+
+	if err := blscrypto.VerifySignature(publicKey, message, []byte{}, signature, shouldUseCompositeHasher); err != nil {
+		return false32Byte, gas, nil
+	}
+	return true32Byte, gas, nil
+}
+
+// Verifies a BLS signature aggregated over an arbitrary message by a set of signers, using the same
+// primitives as consensus uses to verify aggregated seals.
+type blsVerifyAggregatedSignature struct{}
+
+func (c *blsVerifyAggregatedSignature) RequiredGas(input []byte) uint64 {
+	gas := params.BlsVerifySignatureGas
+	if len(input) >= 32 {
+		numPublicKeys := new(big.Int).SetBytes(input[0:32])
+		if !numPublicKeys.IsUint64() || numPublicKeys.Uint64() > uint64(len(input)/blscrypto.PUBLICKEYBYTES) {
+			return gas
+		}
+		gas += numPublicKeys.Uint64() * params.BlsAggregatePublicKeyGas
+		gas += blsMessageGas(input, 32+int(numPublicKeys.Uint64())*blscrypto.PUBLICKEYBYTES+blscrypto.SIGNATUREBYTES)
+	}
+	return gas
+}
+
+func (c *blsVerifyAggregatedSignature) Run(input []byte, caller common.Address, evm *EVM, gas uint64) ([]byte, uint64, error) {
+	gas, err := debitRequiredGas(c, input, gas)
+	if err != nil {
+		return nil, gas, err
+	}
+
+	// input is comprised of 5 arguments:
+	//   numPublicKeys:      32 bytes, 256 bit integer, the number of public keys that follow
+	//   publicKeys:         numPublicKeys * 48 bytes, the public keys of the signers
+	//   signature:          96 bytes, representing the aggregated signature on `message`
+	//   useCompositeHasher: 32 bytes, see parseBLSMessage
+	//   message:            the remaining bytes, see parseBLSMessage
+	if len(input) < 32 {
+		return nil, gas, ErrInputLength
+	}
+	numPublicKeysBig := new(big.Int).SetBytes(input[0:32])
+	if !numPublicKeysBig.IsUint64() || numPublicKeysBig.Sign() == 0 || numPublicKeysBig.Uint64() > uint64(len(input)/blscrypto.PUBLICKEYBYTES) {
+		return nil, gas, ErrInputLength
+	}
+	numPublicKeys := int(numPublicKeysBig.Uint64())
+
+	prefixLength := 32 + numPublicKeys*blscrypto.PUBLICKEYBYTES + blscrypto.SIGNATUREBYTES
+	if len(input) < prefixLength {
+		return nil, gas, ErrInputLength
+	}
+	publicKeys := make([][]byte, numPublicKeys)
+	for i := 0; i < numPublicKeys; i++ {
+		start := 32 + i*blscrypto.PUBLICKEYBYTES
+		publicKeys[i] = input[start : start+blscrypto.PUBLICKEYBYTES]
+	}
+	signature := input[prefixLength-blscrypto.SIGNATUREBYTES : prefixLength]
+
+	shouldUseCompositeHasher, message, err := parseBLSMessage(input[prefixLength:])
+	if err != nil {
+		return nil, gas, err
+	}
+
+	if err := blscrypto.VerifyAggregatedSignature(publicKeys, message, []byte{}, signature, shouldUseCompositeHasher); err != nil {
+		return false32Byte, gas, nil
+	}
+	return true32Byte, gas, nil
+}
@@ -20,8 +20,13 @@ import (
 	"fmt"
 	"math/big"
 	"testing"
+	"time"
 
+	"github.com/celo-org/bls-zexe/go"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/params"
 )
 
 // precompiledTest defines the input/output pairs for precompiled contract tests.
@@ -365,6 +370,32 @@ var proofOfPossessionTests = []precompiledTest{
 	},
 }
 
+var getExchangeRateTests = []precompiledTest{
+	{ // the zero address denotes Celo Gold, which always has a rate of 1/1
+		input:    "0000000000000000000000000000000000000000000000000000000000000000",
+		expected: "00000000000000000000000000000000000000000000000000000000000000010000000000000000000000000000000000000000000000000000000000000001",
+		name:     "celo_gold",
+	},
+	{
+		input:         "",
+		expected:      "invalid input length",
+		errorExpected: true,
+		name:          "empty_input",
+	},
+	{
+		input:         "00000000000000000000000000000000000000000000000000000000000000",
+		expected:      "invalid input length",
+		errorExpected: true,
+		name:          "input_too_short",
+	},
+	{
+		input:         "000000000000000000000000000000000000000000000000000000000000000000",
+		expected:      "invalid input length",
+		errorExpected: true,
+		name:          "input_too_long",
+	},
+}
+
 func testPrecompiled(addr string, test precompiledTest, t *testing.T) {
 	p := PrecompiledContractsByzantium[common.HexToAddress(addr)]
 	in := common.Hex2Bytes(test.input)
@@ -535,3 +566,252 @@ func TestPrecompiledProofOfPossession(t *testing.T) {
 		testPrecompiled("fb", test, t)
 	}
 }
+
+var blsVerifySignatureTests = []precompiledTest{
+	{
+		input:         "",
+		expected:      "invalid input length",
+		errorExpected: true,
+		name:          "empty_input",
+	},
+}
+
+var blsVerifyAggregatedSignatureTests = []precompiledTest{
+	{
+		input:         "",
+		expected:      "invalid input length",
+		errorExpected: true,
+		name:          "empty_input",
+	},
+	{
+		input:         "0000000000000000000000000000000000000000000000000000000000000000",
+		expected:      "invalid input length",
+		errorExpected: true,
+		name:          "no_public_keys",
+	},
+	{
+		input:         "0000000000000000000000000000000000000000000000000000000000000002",
+		expected:      "invalid input length",
+		errorExpected: true,
+		name:          "missing_public_keys",
+	},
+}
+
+// makeBlsVerifyTest signs a message with the given number of freshly generated keys and
+// returns a test that verifies the (aggregated) signature.
+func makeBlsVerifyTest(numKeys int, messageLength int, aggregated bool, useCompositeHasher bool) precompiledTest {
+	message := make([]byte, messageLength)
+	for i := range message {
+		message[i] = byte(i)
+	}
+
+	var signatures []*bls.Signature
+	var publicKeysBytes []byte
+	for i := 0; i < numKeys; i++ {
+		privateKey, _ := bls.GeneratePrivateKey()
+		publicKey, _ := privateKey.ToPublic()
+		publicKeyBytes, _ := publicKey.Serialize()
+		publicKeysBytes = append(publicKeysBytes, publicKeyBytes...)
+		signature, _ := privateKey.SignMessage(message, []byte{}, useCompositeHasher)
+		signatures = append(signatures, signature)
+		publicKey.Destroy()
+		privateKey.Destroy()
+	}
+	aggregatedSignature, _ := bls.AggregateSignatures(signatures)
+	signatureBytes, _ := aggregatedSignature.Serialize()
+	aggregatedSignature.Destroy()
+	for _, signature := range signatures {
+		signature.Destroy()
+	}
+
+	var input []byte
+	if aggregated {
+		input = append(input, common.LeftPadBytes(big.NewInt(int64(numKeys)).Bytes(), 32)...)
+	}
+	input = append(input, publicKeysBytes...)
+	input = append(input, signatureBytes...)
+	if useCompositeHasher {
+		input = append(input, common.LeftPadBytes(common.Big1.Bytes(), 32)...)
+	} else {
+		input = append(input, make([]byte, 32)...)
+	}
+	input = append(input, message...)
+
+	return precompiledTest{
+		input:    common.Bytes2Hex(input),
+		expected: common.Bytes2Hex(true32Byte),
+		name:     fmt.Sprintf("keys=%d,message=%d,composite=%v", numKeys, messageLength, useCompositeHasher),
+	}
+}
+
+// makeBlsWrongSignatureTest returns a test verifying a signature against another
+// message than the signed one.
+func makeBlsWrongSignatureTest(numKeys int, aggregated bool) precompiledTest {
+	test := makeBlsVerifyTest(numKeys, 64, aggregated, false)
+	// Change the last byte of the message
+	test.input = test.input[:len(test.input)-2] + "ff"
+	test.expected = common.Bytes2Hex(false32Byte)
+	test.name = fmt.Sprintf("keys=%d,wrong_signature", numKeys)
+	return test
+}
+
+// Tests sample inputs for blsVerifySignature
+func TestPrecompiledBlsVerifySignature(t *testing.T) {
+	for _, test := range blsVerifySignatureTests {
+		testPrecompiled("f6", test, t)
+	}
+	testPrecompiled("f6", makeBlsVerifyTest(1, 64, false, false), t)
+	testPrecompiled("f6", makeBlsVerifyTest(1, 64, false, true), t)
+	testPrecompiled("f6", makeBlsWrongSignatureTest(1, false), t)
+}
+
+// Tests sample inputs for blsVerifyAggregatedSignature
+func TestPrecompiledBlsVerifyAggregatedSignature(t *testing.T) {
+	for _, test := range blsVerifyAggregatedSignatureTests {
+		testPrecompiled("f5", test, t)
+	}
+	testPrecompiled("f5", makeBlsVerifyTest(4, 64, true, false), t)
+	testPrecompiled("f5", makeBlsVerifyTest(4, 64, true, true), t)
+	testPrecompiled("f5", makeBlsWrongSignatureTest(4, true), t)
+}
+
+// Tests that only messages hashed with the composite hasher are charged per word
+func TestBlsVerifyRequiredGas(t *testing.T) {
+	tests := []struct {
+		addr     string
+		test     precompiledTest
+		expected uint64
+	}{
+		{"f6", makeBlsVerifyTest(1, 64, false, false), params.BlsVerifySignatureGas},
+		{"f6", makeBlsVerifyTest(1, 64, false, true), params.BlsVerifySignatureGas + 2*params.BlsVerifyPerWordGas},
+		{"f5", makeBlsVerifyTest(4, 64, true, false), params.BlsVerifySignatureGas + 4*params.BlsAggregatePublicKeyGas},
+		{"f5", makeBlsVerifyTest(4, 65, true, true), params.BlsVerifySignatureGas + 4*params.BlsAggregatePublicKeyGas + 3*params.BlsVerifyPerWordGas},
+	}
+	for _, test := range tests {
+		p := PrecompiledContractsByzantium[common.HexToAddress(test.addr)]
+		if gas := p.RequiredGas(common.Hex2Bytes(test.test.input)); gas != test.expected {
+			t.Errorf("%s %s: gas mismatch: have %d, want %d", test.addr, test.test.name, gas, test.expected)
+		}
+	}
+}
+
+// Benchmarks blsVerifySignature with messages of increasing length
+func BenchmarkPrecompiledBlsVerifySignature(bench *testing.B) {
+	for _, messageLength := range []int{32, 256, 1024} {
+		benchmarkPrecompiled("f6", makeBlsVerifyTest(1, messageLength, false, false), bench)
+		benchmarkPrecompiled("f6", makeBlsVerifyTest(1, messageLength, false, true), bench)
+	}
+}
+
+// Benchmarks blsVerifyAggregatedSignature with an increasing number of signers
+func BenchmarkPrecompiledBlsVerifyAggregatedSignature(bench *testing.B) {
+	for _, numKeys := range []int{1, 10, 100} {
+		benchmarkPrecompiled("f5", makeBlsVerifyTest(numKeys, 64, true, false), bench)
+	}
+}
+
+// timePrecompiled returns the time in nanoseconds that the precompile at addr takes on the input of test.
+func timePrecompiled(addr string, test precompiledTest) float64 {
+	p := PrecompiledContractsByzantium[common.HexToAddress(addr)]
+	in := common.Hex2Bytes(test.input)
+	reqGas := p.RequiredGas(in)
+	contract := NewContract(AccountRef(common.HexToAddress("1337")),
+		nil, new(big.Int), reqGas)
+
+	var runs int
+	start := time.Now()
+	for ; runs < 10 || time.Since(start) < 200*time.Millisecond; runs++ {
+		contract.Gas = reqGas
+		RunPrecompiledContract(p, in, contract, nil)
+	}
+	return float64(time.Since(start).Nanoseconds()) / float64(runs)
+}
+
+// Benchmarks the terms of the BLS gas schedule against ecrecover and reports them in gas,
+// at the rate of params.EcrecoverGas:
+//   verify-gas: blsVerifySignature of a 32-byte message without the composite hasher, BlsVerifySignatureGas
+//   key-gas:    each additional signer of blsVerifyAggregatedSignature, BlsAggregatePublicKeyGas
+//   word-gas:   each additional message word hashed with the composite hasher, BlsVerifyPerWordGas
+func BenchmarkPrecompiledBlsGas(bench *testing.B) {
+	ecrecover := timePrecompiled("01", precompiledTest{
+		input: "38d18acb67d25c8bb9942764b62f18e17054f66a817bd4295423adf9ed98873e000000000000000000000000000000000000000000000000000000000000001b38d18acb67d25c8bb9942764b62f18e17054f66a817bd4295423adf9ed98873e789d1dd423d25f0772d2748d60f7e4b81bb14d086eba8e8e8efb6dcff8a4ae02",
+	})
+	gasPerNs := float64(params.EcrecoverGas) / ecrecover
+
+	verify := timePrecompiled("f6", makeBlsVerifyTest(1, 32, false, false))
+	oneKey := timePrecompiled("f5", makeBlsVerifyTest(1, 32, true, false))
+	manyKeys := timePrecompiled("f5", makeBlsVerifyTest(101, 32, true, false))
+	oneWord := timePrecompiled("f6", makeBlsVerifyTest(1, 32, false, true))
+	manyWords := timePrecompiled("f6", makeBlsVerifyTest(1, 32*33, false, true))
+
+	bench.ReportMetric(verify*gasPerNs, "verify-gas")
+	bench.ReportMetric((manyKeys-oneKey)/100*gasPerNs, "key-gas")
+	bench.ReportMetric((manyWords-oneWord)/32*gasPerNs, "word-gas")
+}
+
+// Tests sample inputs for getExchangeRate that do not need an oracle
+func TestPrecompiledGetExchangeRate(t *testing.T) {
+	for _, test := range getExchangeRateTests {
+		testPrecompiled("f7", test, t)
+	}
+}
+
+// Tests getExchangeRate against stub Registry and SortedOracles contracts
+func TestPrecompiledGetExchangeRateOracle(t *testing.T) {
+	var (
+		oracles = common.HexToAddress("0x0ac1e5")
+		token   = common.Hex2Bytes("000000000000000000000000000000000000000000000000000000000000cafe")
+		// getAddressFor: return the oracles address
+		registryCode = append(append([]byte{byte(PUSH20)}, oracles.Bytes()...),
+			byte(PUSH1), 0, byte(MSTORE), byte(PUSH1), 32, byte(PUSH1), 0, byte(RETURN))
+	)
+	tests := []struct {
+		name        string
+		oraclesCode []byte
+		expected    string
+		err         error
+	}{
+		{
+			// medianRate: return (3, 2)
+			name: "known_currency",
+			oraclesCode: []byte{byte(PUSH1), 3, byte(PUSH1), 0, byte(MSTORE),
+				byte(PUSH1), 2, byte(PUSH1), 32, byte(MSTORE), byte(PUSH1), 64, byte(PUSH1), 0, byte(RETURN)},
+			expected: "0000000000000000000000000000000000000000000000000000000000000003" +
+				"0000000000000000000000000000000000000000000000000000000000000002",
+		},
+		{
+			// medianRate: return (0, 0), as SortedOracles does for tokens without rates
+			name:        "unknown_currency",
+			oraclesCode: []byte{byte(PUSH1), 64, byte(PUSH1), 0, byte(RETURN)},
+			err:         ErrUnknownFeeCurrency,
+		},
+		{
+			// medianRate: loop forever, which runs out of its own allowance
+			name:        "bounded",
+			oraclesCode: []byte{byte(JUMPDEST), byte(PUSH1), 0, byte(JUMP)},
+			err:         ErrOutOfGas,
+		},
+	}
+
+	p := PrecompiledContractsByzantium[common.HexToAddress("f7")]
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			statedb, _ := state.New(common.Hash{}, state.NewDatabase(ethdb.NewMemDatabase()))
+			statedb.SetCode(params.RegistrySmartContractAddress, registryCode)
+			statedb.SetCode(oracles, test.oraclesCode)
+			evm := NewEVM(Context{BlockNumber: big.NewInt(1)}, statedb, params.TestChainConfig, Config{})
+
+			gas := p.RequiredGas(token)
+			res, leftover, err := p.Run(token, common.Address{}, evm, gas)
+			if err != test.err {
+				t.Fatalf("error mismatch: have %v, want %v", err, test.err)
+			}
+			if err == nil && common.Bytes2Hex(res) != test.expected {
+				t.Errorf("result mismatch: have %x, want %s", res, test.expected)
+			}
+			if leftover != 0 {
+				t.Errorf("gas mismatch: have %d left over, want a fixed cost of %d", leftover, gas)
+			}
+		})
+	}
+}
@@ -1,6 +1,7 @@
 package vm
 
 import (
+	"math/big"
 	"strings"
 
 	"github.com/ethereum/go-ethereum/accounts/abi"
@@ -30,10 +31,37 @@ const (
                               "stateMutability": "view",
                               "type": "function"
                              }]`
+
+	// This is taken from celo-monorepo/packages/protocol/build/<env>/contracts/SortedOracles.json
+	medianRateABI = `[{"constant": true,
+                           "inputs": [
+                                {
+                                    "name": "token",
+                                    "type": "address"
+                                }
+                           ],
+                           "name": "medianRate",
+                           "outputs": [
+                                {
+                                    "name": "",
+                                    "type": "uint128"
+                                },
+                                {
+                                    "name": "",
+                                    "type": "uint128"
+                                }
+                           ],
+                           "payable": false,
+                           "stateMutability": "view",
+                           "type": "function"
+                          }]`
 )
 
 var getAddressForFuncABI, _ = abi.JSON(strings.NewReader(getAddressForABI))
 
+// MedianRateFuncABI is the ABI of SortedOracles.medianRate, shared with contract_comm/currency.
+var MedianRateFuncABI, _ = abi.JSON(strings.NewReader(medianRateABI))
+
 // TODO(kevjue) - Re-Enable caching of the retrieved registered address
 // See this commit for the removed code for caching:  https://github.com/celo-org/geth/commit/43a275273c480d307a3d2b3c55ca3b3ee31ec7dd.
 func GetRegisteredAddressWithEvm(registryId [32]byte, evm *EVM) (*common.Address, error) {
@@ -61,3 +89,20 @@ func GetRegisteredAddressWithEvm(registryId [32]byte, evm *EVM) (*common.Address
 
 	return &contractAddress, nil
 }
+
+// GetExchangeRateWithEvm returns the SortedOracles median rate for the given token as a
+// numerator and denominator pair, i.e. the amount of the token per unit of Celo Gold.
+// The medianRate call runs with a fixed allowance of MaxGasForMedianRate, so that what
+// the caller pays does not depend on the state of the oracle.
+func GetExchangeRateWithEvm(token common.Address, evm *EVM) (*big.Int, *big.Int, error) {
+	sortedOraclesAddress, err := GetRegisteredAddressWithEvm(params.SortedOraclesRegistryId, evm)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var returnArray [2]*big.Int
+	if _, err := evm.StaticCallFromSystem(*sortedOraclesAddress, MedianRateFuncABI, "medianRate", []interface{}{token}, &returnArray, params.MaxGasForMedianRate); err != nil {
+		return nil, nil, err
+	}
+	return returnArray[0], returnArray[1], nil
+}
@@ -29,4 +29,5 @@ var (
 	ErrNoCompatibleInterpreter  = errors.New("no compatible interpreter")
 	ErrValidatorsOutOfBounds    = errors.New("getValidators out of bounds")
 	ErrInputLength              = errors.New("invalid input length")
+	ErrUnknownFeeCurrency       = errors.New("unknown fee currency")
 )
@@ -553,6 +553,15 @@ func (evm *EVM) CallFromSystem(contractAddress common.Address, abi abipkg.ABI, f
 	return evm.handleABICall(abi, funcName, args, returnObj, call)
 }
 
+// CallFromAccount calls the contract on behalf of the given account, as a
+// transaction sent by the account would.
+func (evm *EVM) CallFromAccount(caller common.Address, contractAddress common.Address, abi abipkg.ABI, funcName string, args []interface{}, returnObj interface{}, gas uint64, value *big.Int) (uint64, error) {
+	call := func(transactionData []byte) ([]byte, uint64, error) {
+		return evm.Call(AccountRef(caller), contractAddress, transactionData, gas, value)
+	}
+	return evm.handleABICall(abi, funcName, args, returnObj, call)
+}
+
 func (evm *EVM) handleABICall(abi abipkg.ABI, funcName string, args []interface{}, returnObj interface{}, call func([]byte) ([]byte, uint64, error)) (uint64, error) {
 	transactionData, err := abi.Pack(funcName, args...)
 	if err != nil {
@@ -149,6 +149,15 @@ func (api *PrivateMinerAPI) SetEtherbase(etherbase common.Address) bool {
 	return true
 }
 
+// RotateSigner loads the key of the given unlocked account as the next validator
+// signing key, to be used from the epoch it's elected with on.
+func (api *PrivateMinerAPI) RotateSigner(signer common.Address) (bool, error) {
+	if err := api.e.RotateSigner(signer); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
 // SetRecommitInterval updates the interval for miner sealing work recommitting.
 func (api *PrivateMinerAPI) SetRecommitInterval(interval int) {
 	api.e.Miner().SetRecommitInterval(time.Duration(interval) * time.Millisecond)
@@ -18,6 +18,7 @@ package eth
 
 import (
 	"context"
+	"fmt"
 	"math/big"
 
 	"github.com/ethereum/go-ethereum/accounts"
@@ -100,6 +101,17 @@ func (b *EthAPIBackend) StateAndHeaderByNumber(ctx context.Context, blockNr rpc.
 	return stateDb, header, err
 }
 
+func (b *EthAPIBackend) RunWithState(ctx context.Context, blockNr rpc.BlockNumber, fn func(*types.Header, *state.StateDB) error) error {
+	statedb, header, err := b.StateAndHeaderByNumber(ctx, blockNr)
+	if err != nil {
+		return err
+	}
+	if statedb == nil || header == nil {
+		return fmt.Errorf("block #%d not found", blockNr)
+	}
+	return fn(header, statedb)
+}
+
 func (b *EthAPIBackend) GetBlock(ctx context.Context, hash common.Hash) (*types.Block, error) {
 	return b.eth.blockchain.GetBlockByHash(hash), nil
 }
@@ -197,11 +209,16 @@ func (b *EthAPIBackend) ProtocolVersion() int {
 }
 
 func (b *EthAPIBackend) SuggestPrice(ctx context.Context) (*big.Int, error) {
-	return gpm.GetGasPriceSuggestion(nil, nil, nil)
+	return b.SuggestPriceInCurrency(ctx, nil)
 }
 
 func (b *EthAPIBackend) SuggestPriceInCurrency(ctx context.Context, currencyAddress *common.Address) (*big.Int, error) {
-	return gpm.GetGasPriceSuggestion(currencyAddress, nil, nil)
+	block := b.eth.blockchain.CurrentBlock()
+	state, err := b.eth.blockchain.StateAt(block.Root())
+	if err != nil {
+		return nil, err
+	}
+	return gpm.GetGasPriceSuggestion(currencyAddress, block.Header(), state)
 }
 
 func (b *EthAPIBackend) ChainDb() ethdb.Database {
@@ -231,6 +248,6 @@ func (b *EthAPIBackend) GatewayFeeRecipient() common.Address {
 	return b.eth.GatewayFeeRecipient()
 }
 
-func (b *EthAPIBackend) GatewayFee() *big.Int {
+func (b *EthAPIBackend) GatewayFee(recipient common.Address) *big.Int {
 	return b.eth.GatewayFee()
 }
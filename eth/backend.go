@@ -57,6 +57,7 @@ type LesServer interface {
 	Start(srvr *p2p.Server)
 	Stop()
 	Protocols() []p2p.Protocol
+	APIs() []rpc.API
 	SetBloomBitsIndexer(bbIndexer *core.ChainIndexer)
 }
 
@@ -167,7 +168,13 @@ func New(ctx *node.ServiceContext, config *Config) (*Ethereum, error) {
 			EWASMInterpreter:        config.EWASMInterpreter,
 			EVMInterpreter:          config.EVMInterpreter,
 		}
-		cacheConfig = &core.CacheConfig{Disabled: config.NoPruning, TrieCleanLimit: config.TrieCleanCache, TrieDirtyLimit: config.TrieDirtyCache, TrieTimeLimit: config.TrieTimeout}
+		cacheConfig = &core.CacheConfig{
+			Disabled:            config.NoPruning,
+			TrieCleanLimit:      config.TrieCleanCache,
+			TrieCleanNoPrefetch: config.NoPrefetch,
+			TrieDirtyLimit:      config.TrieDirtyCache,
+			TrieTimeLimit:       config.TrieTimeout,
+		}
 	)
 	eth.blockchain, err = core.NewBlockChain(chainDb, cacheConfig, eth.chainConfig, eth.engine, vmConfig, eth.shouldPreserve)
 	if err != nil {
@@ -199,6 +206,13 @@ func New(ctx *node.ServiceContext, config *Config) (*Ethereum, error) {
 	if istanbul, isIstanbul := eth.engine.(*istanbulBackend.Backend); isIstanbul {
 		istanbul.SetChain(eth.blockchain, eth.blockchain.CurrentBlock)
 
+		// Keep signing with the key the validator rotated to, along with its BLS key
+		if signer, _ := istanbul.SignerRotation(); signer != (common.Address{}) && signer != eth.etherbase {
+			log.Info("Using rotated validator signing key as etherbase", "configured", eth.etherbase, "signer", signer)
+			eth.etherbase, eth.blsbase = signer, signer
+		}
+		istanbul.SubscribeSignerRotation(eth.rotatedSigner)
+
 		chainHeadCh := make(chan core.ChainHeadEvent)
 		chainHeadSub := eth.blockchain.SubscribeChainHeadEvent(chainHeadCh)
 
@@ -309,6 +323,11 @@ func (s *Ethereum) APIs() []rpc.API {
 	// Append any APIs exposed explicitly by the consensus engine
 	apis = append(apis, s.engine.APIs(s.BlockChain())...)
 
+	// Append any APIs exposed by the light server
+	if s.lesServer != nil {
+		apis = append(apis, s.lesServer.APIs()...)
+	}
+
 	// Append all the local APIs and return
 	return append(apis, []rpc.API{
 		{
@@ -460,6 +479,36 @@ func (s *Ethereum) SetEtherbase(etherbase common.Address) {
 	s.miner.SetEtherbase(etherbase)
 }
 
+// RotateSigner loads the key of the given account as the next validator signing
+// key. The validator keeps signing with the etherbase key until it's elected
+// with the new signer, and switches to it at that epoch boundary. The BLS key is
+// the one of the new signer's account, not the BLSbase, since that's the BLS
+// key the validator registers with the signer.
+func (s *Ethereum) RotateSigner(signer common.Address) error {
+	istanbul, isIstanbul := s.engine.(*istanbulBackend.Backend)
+	if !isIstanbul {
+		return errors.New("signer rotation is only supported by istanbul")
+	}
+	wallet, err := s.accountManager.Find(accounts.Account{Address: signer})
+	if wallet == nil || err != nil {
+		log.Error("Next signer account unavailable locally", "err", err)
+		return fmt.Errorf("signer missing: %v", err)
+	}
+	istanbul.AuthorizeNext(signer, wallet.SignHash, wallet.SignHashBLS, wallet.SignMessageBLS)
+	return nil
+}
+
+// rotatedSigner makes the signing key the validator rotated to both the
+// etherbase and the BLSbase, so mining continues with it.
+func (s *Ethereum) rotatedSigner(signer common.Address) {
+	s.lock.Lock()
+	s.etherbase = signer
+	s.blsbase = signer
+	s.lock.Unlock()
+
+	s.miner.SetEtherbase(signer)
+}
+
 // StartMining starts the miner with the given number of CPU threads. If mining
 // is already running, this method adjust the number of threads allowed to use
 // and updates the minimum price required by the transaction pool.
@@ -512,6 +561,13 @@ func (s *Ethereum) StartMining(threads int) error {
 			}
 			if isIstanbul {
 				istanbul.Authorize(eb, wallet.SignHash, blswallet.SignHashBLS, blswallet.SignMessageBLS)
+
+				// Resume a pending signer rotation
+				if _, next := istanbul.SignerRotation(); next != (common.Address{}) {
+					if err := s.RotateSigner(next); err != nil {
+						log.Warn("Pending signer rotation not resumed", "next", next, "err", err)
+					}
+				}
 			}
 		}
 		// If mining is started, we can disable the transaction rejection mechanism
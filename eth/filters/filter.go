@@ -58,6 +58,9 @@ type Filter struct {
 	block      common.Hash // Block hash if filtering a single block
 	begin, end int64       // Range interval if filtering multiple blocks
 
+	paged       bool // Whether only a page of the matching logs is requested
+	skip, limit int  // Matching logs still to skip and to return for the requested page
+
 	matcher *bloombits.Matcher
 }
 
@@ -114,6 +117,38 @@ func newFilter(backend Backend, addresses []common.Address, topics [][]common.Ha
 	}
 }
 
+// SetPage restricts the logs returned by Logs to at most limit matching logs, after
+// skipping the first skip ones. The search stops as soon as the page is filled, so
+// that large ranges are not fully gathered to return a few logs.
+func (f *Filter) SetPage(skip, limit int) {
+	f.paged = true
+	f.skip, f.limit = skip, limit
+}
+
+// appendPage appends to logs those of the found logs that fall within the requested page.
+func (f *Filter) appendPage(logs, found []*types.Log) []*types.Log {
+	if !f.paged {
+		return append(logs, found...)
+	}
+	if f.skip > 0 {
+		skipped := f.skip
+		if skipped > len(found) {
+			skipped = len(found)
+		}
+		found, f.skip = found[skipped:], f.skip-skipped
+	}
+	if len(found) > f.limit {
+		found = found[:f.limit]
+	}
+	f.limit -= len(found)
+	return append(logs, found...)
+}
+
+// pageFilled returns whether the requested page of logs is complete.
+func (f *Filter) pageFilled() bool {
+	return f.paged && f.limit == 0
+}
+
 // Logs searches the blockchain for matching log entries, returning all from the
 // first block that contains matches, updating the start of the filter accordingly.
 func (f *Filter) Logs(ctx context.Context) ([]*types.Log, error) {
@@ -126,7 +161,8 @@ func (f *Filter) Logs(ctx context.Context) ([]*types.Log, error) {
 		if header == nil {
 			return nil, errors.New("unknown block")
 		}
-		return f.blockLogs(ctx, header)
+		logs, err := f.blockLogs(ctx, header)
+		return f.appendPage(nil, logs), err
 	}
 	// Figure out the limits of the filter range
 	header, _ := f.backend.HeaderByNumber(ctx, rpc.LatestBlockNumber)
@@ -154,7 +190,7 @@ func (f *Filter) Logs(ctx context.Context) ([]*types.Log, error) {
 		} else {
 			logs, err = f.indexedLogs(ctx, indexed-1)
 		}
-		if err != nil {
+		if err != nil || f.pageFilled() {
 			return logs, err
 		}
 	}
@@ -202,7 +238,9 @@ func (f *Filter) indexedLogs(ctx context.Context, end uint64) ([]*types.Log, err
 			if err != nil {
 				return logs, err
 			}
-			logs = append(logs, found...)
+			if logs = f.appendPage(logs, found); f.pageFilled() {
+				return logs, nil
+			}
 
 		case <-ctx.Done():
 			return logs, ctx.Err()
@@ -224,7 +262,10 @@ func (f *Filter) unindexedLogs(ctx context.Context, end uint64) ([]*types.Log, e
 		if err != nil {
 			return logs, err
 		}
-		logs = append(logs, found...)
+		if logs = f.appendPage(logs, found); f.pageFilled() {
+			f.begin++
+			return logs, nil
+		}
 	}
 	return logs, nil
 }
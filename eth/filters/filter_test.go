@@ -229,4 +229,18 @@ func TestFilters(t *testing.T) {
 	if len(logs) != 0 {
 		t.Error("expected 0 log, got", len(logs))
 	}
+
+	filter = NewRangeFilter(backend, 0, -1, []common.Address{addr}, [][]common.Hash{{hash1, hash2, hash3, hash4}})
+	filter.SetPage(1, 1)
+
+	logs, _ = filter.Logs(context.Background())
+	if len(logs) != 1 {
+		t.Error("expected 1 log, got", len(logs))
+	}
+	if len(logs) > 0 && logs[0].Topics[0] != hash2 {
+		t.Errorf("expected log[0].Topics[0] to be %x, got %x", hash2, logs[0].Topics[0])
+	}
+	if filter.begin > 4 {
+		t.Error("expected the search to stop once the page was filled, it reached block", filter.begin)
+	}
 }
@@ -193,6 +193,11 @@ func (p *peer) MarkTransaction(hash common.Hash) {
 	p.knownTxs.Add(hash)
 }
 
+// Version returns the protocol version negotiated with the peer.
+func (p *peer) Version() int {
+	return p.version
+}
+
 // Send writes an RLP-encoded message with the given code.
 // data should encode as an RLP list.
 func (p *peer) Send(msgcode uint64, data interface{}) error {
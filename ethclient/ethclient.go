@@ -524,5 +524,14 @@ func toCallArg(msg ethereum.CallMsg) interface{} {
 	if msg.GasPrice != nil {
 		arg["gasPrice"] = (*hexutil.Big)(msg.GasPrice)
 	}
+	if msg.FeeCurrency != nil {
+		arg["feeCurrency"] = msg.FeeCurrency
+	}
+	if msg.GatewayFeeRecipient != nil {
+		arg["gatewayFeeRecipient"] = msg.GatewayFeeRecipient
+	}
+	if msg.GatewayFee != nil {
+		arg["gatewayFee"] = (*hexutil.Big)(msg.GatewayFee)
+	}
 	return arg
 }
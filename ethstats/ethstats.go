@@ -627,22 +627,26 @@ func (s *Service) reportLatency(conn *websocket.Conn, sendCh chan *StatsPayload)
 
 // blockStats is the information to report about individual blocks.
 type blockStats struct {
-	Number      *big.Int       `json:"number"`
-	Hash        common.Hash    `json:"hash"`
-	ParentHash  common.Hash    `json:"parentHash"`
-	Timestamp   *big.Int       `json:"timestamp"`
-	Miner       common.Address `json:"miner"`
-	GasUsed     uint64         `json:"gasUsed"`
-	GasLimit    uint64         `json:"gasLimit"`
-	Diff        string         `json:"difficulty"`
-	TotalDiff   string         `json:"totalDifficulty"`
-	Txs         []txStats      `json:"transactions"`
-	TxHash      common.Hash    `json:"transactionsRoot"`
-	Root        common.Hash    `json:"stateRoot"`
-	Uncles      uncleStats     `json:"uncles"`
-	EpochSize   uint64         `json:"epochSize"`
-	BlockRemain uint64         `json:"blockRemain"`
-	Validators  validatorSet   `json:"validators"`
+	Number      *big.Int         `json:"number"`
+	Hash        common.Hash      `json:"hash"`
+	ParentHash  common.Hash      `json:"parentHash"`
+	Timestamp   *big.Int         `json:"timestamp"`
+	Miner       common.Address   `json:"miner"`
+	GasUsed     uint64           `json:"gasUsed"`
+	GasLimit    uint64           `json:"gasLimit"`
+	Diff        string           `json:"difficulty"`
+	TotalDiff   string           `json:"totalDifficulty"`
+	Txs         []txStats        `json:"transactions"`
+	TxHash      common.Hash      `json:"transactionsRoot"`
+	Root        common.Hash      `json:"stateRoot"`
+	Uncles      uncleStats       `json:"uncles"`
+	EpochSize   uint64           `json:"epochSize"`
+	BlockRemain uint64           `json:"blockRemain"`
+	Validators  validatorSet     `json:"validators"`
+	Round       uint64           `json:"round"`
+	Signers     []common.Address `json:"signers"`
+	NumSigners  int              `json:"numSigners"`
+	NumElected  int              `json:"numElected"`
 }
 
 // txStats is the information to report about individual transactions.
@@ -803,12 +807,15 @@ func (s *Service) assembleBlockStats(block *types.Block) *blockStats {
 	author, _ := s.backend.Author(header)
 
 	// Add epoch info
-	epochSize := s.eth.Config().Istanbul.Epoch
+	epochSize := s.backend.EpochSize()
 	blockRemain := epochSize - istanbul.GetNumberWithinEpoch(header.Number.Uint64(), epochSize)
 
+	// Add the round and signers of the block's aggregated seal
+	round, signers, elected := s.assembleSigners(header)
+
 	// only assemble every valSetInterval blocks
-	if block.Number().Uint64()%valSetInterval == 0 {
-		valSet = s.assembleValidatorSet(block, state)
+	if header.Number.Uint64()%valSetInterval == 0 {
+		valSet = s.assembleValidatorSet(header, state)
 	}
 
 	return &blockStats{
@@ -828,12 +835,45 @@ func (s *Service) assembleBlockStats(block *types.Block) *blockStats {
 		EpochSize:   epochSize,
 		BlockRemain: blockRemain,
 		Validators:  valSet,
+		Round:       round,
+		Signers:     signers,
+		NumSigners:  len(signers),
+		NumElected:  elected,
 	}
 }
 
+// assembleSigners decodes the aggregated seal of the given header, returning the round the block
+// was committed in, the validators whose bit is set in the signer bitmap and the number of
+// validators that were elected to sign the block.
+func (s *Service) assembleSigners(header *types.Header) (uint64, []common.Address, int) {
+	signers := []common.Address{}
+	if header.Number.Sign() == 0 {
+		return 0, signers, 0
+	}
+	extra, err := types.ExtractIstanbulExtra(header)
+	if err != nil {
+		return 0, signers, 0
+	}
+	var round uint64
+	if extra.AggregatedSeal.Round != nil {
+		round = extra.AggregatedSeal.Round.Uint64()
+	}
+	if extra.AggregatedSeal.Bitmap == nil {
+		return round, signers, 0
+	}
+	validators := s.backend.GetValidators(new(big.Int).Sub(header.Number, common.Big1), header.ParentHash)
+	for i, validator := range validators {
+		if extra.AggregatedSeal.Bitmap.Bit(i) == 1 {
+			signers = append(signers, validator.Address())
+		}
+	}
+	return round, signers, len(validators)
+}
+
 type validatorSet struct {
-	Registered []validatorInfo  `json:"registered"`
-	Elected    []common.Address `json:"elected"`
+	Registered []validatorInfo   `json:"registered"`
+	Elected    []common.Address  `json:"elected"`
+	Uptime     []validatorUptime `json:"uptime"`
 }
 
 type validatorInfo struct {
@@ -845,7 +885,7 @@ type validatorInfo struct {
 	Affiliation    common.Address `json:"affiliation"`
 }
 
-func (s *Service) assembleValidatorSet(block *types.Block, state vm.StateDB) validatorSet {
+func (s *Service) assembleValidatorSet(header *types.Header, state vm.StateDB) validatorSet {
 	var (
 		valSet         validatorSet
 		valsRegistered []validatorInfo
@@ -853,13 +893,13 @@ func (s *Service) assembleValidatorSet(block *types.Block, state vm.StateDB) val
 	)
 
 	// Add set of registered validators
-	valsRegisteredMap, _ := validators.RetrieveRegisteredValidators(nil, nil)
+	valsRegisteredMap, _ := validators.RetrieveRegisteredValidators(header, state)
 	valsRegistered = make([]validatorInfo, 0, len(valsRegisteredMap))
 
 	for _, address := range valsRegisteredMap {
 		var valData validators.ValidatorContractData
 		valData, _ = validators.GetValidator(
-			s.eth.BlockChain().CurrentHeader(),
+			header,
 			state,
 			address)
 
@@ -874,16 +914,28 @@ func (s *Service) assembleValidatorSet(block *types.Block, state vm.StateDB) val
 	}
 
 	// Add addresses of elected validators
-	valsElectedList := s.backend.GetValidators(block.Number(), block.Hash())
+	valsElectedList := s.backend.GetValidators(header.Number, header.Hash())
 
 	valsElected = make([]common.Address, 0, len(valsElectedList))
 	for i := range valsElectedList {
 		valsElected = append(valsElected, valsElectedList[i].Address())
 	}
 
+	// Add the uptime of the validators of the block's epoch, which light nodes don't tally
+	var uptimes []validatorUptime
+	if s.eth != nil && header.Number.Sign() > 0 {
+		epochVals := s.backend.GetValidators(new(big.Int).Sub(header.Number, common.Big1), header.ParentHash)
+		addresses := make([]common.Address, 0, len(epochVals))
+		for _, val := range epochVals {
+			addresses = append(addresses, val.Address())
+		}
+		uptimes = readUptimes(s.eth.ChainDb(), header.Number.Uint64(), s.backend.EpochSize(), addresses)
+	}
+
 	valSet = validatorSet{
 		Elected:    valsElected,
 		Registered: valsRegistered,
+		Uptime:     uptimes,
 	}
 
 	return valSet
@@ -950,18 +1002,64 @@ func (s *Service) reportHistory(conn *websocket.Conn, list []uint64) error {
 
 // pendStats is the information to report about pending transactions.
 type pendStats struct {
-	Pending int `json:"pending"`
+	Pending       int                `json:"pending"`
+	FeeCurrencies []feeCurrencyStats `json:"feeCurrencies"`
+}
+
+// feeCurrencyStats is the number of pool transactions paying fees in a currency.
+// A nil currency denotes Celo Gold.
+type feeCurrencyStats struct {
+	Currency *common.Address `json:"currency"`
+	Pending  int             `json:"pending"`
+	Queued   int             `json:"queued"`
+}
+
+// assembleFeeCurrencyStats groups the pending and queued transactions by fee currency.
+func assembleFeeCurrencyStats(pending, queued map[common.Address]types.Transactions) []feeCurrencyStats {
+	var (
+		stats   []feeCurrencyStats
+		indexes = make(map[common.Address]int)
+	)
+	tally := func(txs map[common.Address]types.Transactions, isPending bool) {
+		for _, list := range txs {
+			for _, tx := range list {
+				var key common.Address
+				if tx.FeeCurrency() != nil {
+					key = *tx.FeeCurrency()
+				}
+				index, ok := indexes[key]
+				if !ok {
+					index = len(stats)
+					indexes[key] = index
+					stats = append(stats, feeCurrencyStats{Currency: tx.FeeCurrency()})
+				}
+				if isPending {
+					stats[index].Pending++
+				} else {
+					stats[index].Queued++
+				}
+			}
+		}
+	}
+	tally(pending, true)
+	tally(queued, false)
+	return stats
 }
 
 // reportPending retrieves the current number of pending transactions and reports
 // it to the stats server.
 func (s *Service) reportPending(conn *websocket.Conn) error {
 	// Retrieve the pending count from the local blockchain
-	var pending int
+	var (
+		pending       int
+		feeCurrencies []feeCurrencyStats
+	)
 	if s.eth != nil {
 		pending, _ = s.eth.TxPool().Stats()
+		feeCurrencies = assembleFeeCurrencyStats(s.eth.TxPool().Content())
 	} else {
 		pending = s.les.TxPool().Stats()
+		feeCurrencies = assembleFeeCurrencyStats(s.les.TxPool().Content())
 	}
 	// Assemble the transaction stats and send it to the server
 	log.Trace("Sending pending transactions to ethstats", "count", pending)
@@ -969,7 +1067,8 @@ func (s *Service) reportPending(conn *websocket.Conn) error {
 	stats := map[string]interface{}{
 		"id": s.node,
 		"stats": &pendStats{
-			Pending: pending,
+			Pending:       pending,
+			FeeCurrencies: feeCurrencies,
 		},
 	}
 
@@ -978,14 +1077,23 @@ func (s *Service) reportPending(conn *websocket.Conn) error {
 
 // nodeStats is the information to report about the local node.
 type nodeStats struct {
-	Active   bool `json:"active"`
-	Syncing  bool `json:"syncing"`
-	Mining   bool `json:"mining"`
-	Elected  bool `json:"elected"`
-	Hashrate int  `json:"hashrate"`
-	Peers    int  `json:"peers"`
-	GasPrice int  `json:"gasPrice"`
-	Uptime   int  `json:"uptime"`
+	Active   bool       `json:"active"`
+	Syncing  bool       `json:"syncing"`
+	Mining   bool       `json:"mining"`
+	Elected  bool       `json:"elected"`
+	Hashrate int        `json:"hashrate"`
+	Peers    int        `json:"peers"`
+	GasPrice int        `json:"gasPrice"`
+	Uptime   int        `json:"uptime"`
+	Proxy    proxyStats `json:"proxy"`
+}
+
+// proxyStats is the proxy connection state of the local node. A proxy reports whether its
+// proxied validator is connected, a proxied validator whether it is connected to its proxy.
+type proxyStats struct {
+	Proxied          bool `json:"proxied"`
+	ProxyConnected   bool `json:"proxyConnected"`
+	ProxiedConnected bool `json:"proxiedConnected"`
 }
 
 // reportPending retrieves various stats about the node at the networking and
@@ -1039,6 +1147,11 @@ func (s *Service) reportStats(conn *websocket.Conn) error {
 			GasPrice: gasprice,
 			Syncing:  syncing,
 			Uptime:   100,
+			Proxy: proxyStats{
+				Proxied:          s.backend.IsProxiedValidator(),
+				ProxyConnected:   s.backend.IsProxyConnected(),
+				ProxiedConnected: s.backend.IsProxy(),
+			},
 		},
 	}
 
@@ -0,0 +1,53 @@
+// Copyright 2017 The Celo Authors
+// This file is part of the celo library.
+//
+// The celo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The celo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the celo library. If not, see <http://www.gnu.org/licenses/>.
+
+package ethstats
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/istanbul"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+)
+
+// validatorUptime is the uptime a validator accumulated in the current epoch, as tallied by
+// the chain for the validator score update.
+type validatorUptime struct {
+	Address         common.Address `json:"address"`
+	ScoreTally      uint64         `json:"scoreTally"`
+	LastSignedBlock uint64         `json:"lastSignedBlock"`
+}
+
+// readUptimes returns the uptime tallies stored for the epoch of the given block, for the
+// validators of that epoch in the order of the validator set. The tallies are those of the
+// canonical chain written so far, so they follow reorgs like the rewards paid on them.
+func readUptimes(db rawdb.DatabaseReader, number uint64, epochSize uint64, validators []common.Address) []validatorUptime {
+	if number == 0 {
+		return nil
+	}
+	uptimes := rawdb.ReadAccumulatedEpochUptime(db, istanbul.GetEpochNumber(number, epochSize))
+	ret := make([]validatorUptime, 0, len(validators))
+	for i, address := range validators {
+		if i >= len(uptimes) {
+			break
+		}
+		ret = append(ret, validatorUptime{
+			Address:         address,
+			ScoreTally:      uptimes[i].ScoreTally,
+			LastSignedBlock: uptimes[i].LastSignedBlock,
+		})
+	}
+	return ret
+}
@@ -0,0 +1,64 @@
+// Copyright 2017 The Celo Authors
+// This file is part of the celo library.
+//
+// The celo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The celo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the celo library. If not, see <http://www.gnu.org/licenses/>.
+
+package ethstats
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/istanbul"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/ethdb"
+)
+
+func TestReadUptimes(t *testing.T) {
+	const epochSize = 10
+	var (
+		db         = ethdb.NewMemDatabase()
+		validators = []common.Address{common.HexToAddress("0x01"), common.HexToAddress("0x02")}
+	)
+	// The chain allocates tallies for more validators than the set has
+	rawdb.WriteAccumulatedEpochUptime(db, 2, []istanbul.Uptime{{ScoreTally: 4, LastSignedBlock: 15}, {ScoreTally: 2, LastSignedBlock: 13}, {}})
+
+	want := []validatorUptime{
+		{Address: validators[0], ScoreTally: 4, LastSignedBlock: 15},
+		{Address: validators[1], ScoreTally: 2, LastSignedBlock: 13},
+	}
+	if have := readUptimes(db, 16, epochSize, validators); !reflect.DeepEqual(have, want) {
+		t.Errorf("uptime mismatch: have %v, want %v", have, want)
+	}
+
+	// A reorg to a shorter branch rewrites the tallies of the epoch, which must be reported
+	// as stored rather than kept from the abandoned branch
+	rawdb.WriteAccumulatedEpochUptime(db, 2, []istanbul.Uptime{{ScoreTally: 1, LastSignedBlock: 12}, {ScoreTally: 3, LastSignedBlock: 14}, {}})
+	want = []validatorUptime{
+		{Address: validators[0], ScoreTally: 1, LastSignedBlock: 12},
+		{Address: validators[1], ScoreTally: 3, LastSignedBlock: 14},
+	}
+	if have := readUptimes(db, 15, epochSize, validators); !reflect.DeepEqual(have, want) {
+		t.Errorf("uptime mismatch after reorg: have %v, want %v", have, want)
+	}
+
+	// Blocks of an epoch without tallies, and the genesis block, report none
+	if have := readUptimes(db, 21, epochSize, validators); len(have) != 0 {
+		t.Errorf("uptime reported for an epoch without tallies: %v", have)
+	}
+	if have := readUptimes(db, 0, epochSize, validators); len(have) != 0 {
+		t.Errorf("uptime reported for the genesis block: %v", have)
+	}
+}
@@ -0,0 +1,1143 @@
+// Copyright 2019 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package graphql provides a GraphQL interface to Ethereum node data.
+package graphql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/consensus/istanbul"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/bloombits"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/eth/filters"
+	"github.com/ethereum/go-ethereum/event"
+	"github.com/ethereum/go-ethereum/internal/ethapi"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// maxPageSize is the maximum number of items returned by a single paginated list.
+const maxPageSize = 1000
+
+var (
+	errBlockInvariant = errors.New("block objects must be instantiated with at least one of num or hash")
+	errNegativeSkip   = errors.New("skip must not be negative")
+	errNegativeFirst  = errors.New("first must not be negative")
+	errPageSize       = fmt.Errorf("page size exceeds the limit of %d items, use first and skip to paginate", maxPageSize)
+)
+
+// Backend is the set of node methods the resolvers rely on. It is implemented by
+// the API backends of both full and light clients.
+type Backend interface {
+	ethapi.Backend
+
+	HeaderByHash(ctx context.Context, blockHash common.Hash) (*types.Header, error)
+	GetLogs(ctx context.Context, blockHash common.Hash) ([][]*types.Log, error)
+	SubscribeRemovedLogsEvent(ch chan<- core.RemovedLogsEvent) event.Subscription
+	SubscribeLogsEvent(ch chan<- []*types.Log) event.Subscription
+	BloomStatus() (uint64, uint64)
+	ServiceFilter(ctx context.Context, session *bloombits.MatcherSession)
+}
+
+// istanbulEngine is the subset of the Istanbul consensus engine used to resolve
+// the consensus information of blocks.
+type istanbulEngine interface {
+	Author(header *types.Header) (common.Address, error)
+	GetValidators(blockNumber *big.Int, headerHash common.Hash) []istanbul.Validator
+	EpochSize() uint64
+}
+
+// paginate returns the bounds of the requested page of a list with the given number
+// of items. Unless a page size is given, the whole list is returned as long as it
+// does not exceed maxPageSize items.
+func paginate(total int, first, skip *int32) (int, int, error) {
+	start := 0
+	if skip != nil {
+		if *skip < 0 {
+			return 0, 0, errNegativeSkip
+		}
+		start = int(*skip)
+	}
+	if start > total {
+		start = total
+	}
+	end := total
+	if first != nil {
+		if *first < 0 {
+			return 0, 0, errNegativeFirst
+		}
+		if *first > maxPageSize {
+			return 0, 0, errPageSize
+		}
+		if start+int(*first) < end {
+			end = start + int(*first)
+		}
+	}
+	if end-start > maxPageSize {
+		return 0, 0, errPageSize
+	}
+	return start, end, nil
+}
+
+// pageLimits returns the number of items to skip and the maximum number of items to
+// return for the requested page of a list whose length is not known upfront. Unless a
+// page size is given, one item more than maxPageSize is requested, so that a list
+// exceeding it can be detected.
+func pageLimits(first, skip *int32) (int, int, error) {
+	start := 0
+	if skip != nil {
+		if *skip < 0 {
+			return 0, 0, errNegativeSkip
+		}
+		start = int(*skip)
+	}
+	limit := maxPageSize + 1
+	if first != nil {
+		if *first < 0 {
+			return 0, 0, errNegativeFirst
+		}
+		if *first > maxPageSize {
+			return 0, 0, errPageSize
+		}
+		limit = int(*first)
+	}
+	return start, limit, nil
+}
+
+// Account represents an account at a specific block.
+type Account struct {
+	r           *Resolver
+	address     common.Address
+	blockNumber rpc.BlockNumber
+}
+
+// getState fetches the StateDB object for an account.
+func (a *Account) getState(ctx context.Context) (*state.StateDB, error) {
+	state, _, err := a.r.backend.StateAndHeaderByNumber(ctx, a.blockNumber)
+	return state, err
+}
+
+func (a *Account) Address(ctx context.Context) (common.Address, error) {
+	return a.address, nil
+}
+
+func (a *Account) Balance(ctx context.Context) (hexutil.Big, error) {
+	state, err := a.getState(ctx)
+	if err != nil {
+		return hexutil.Big{}, err
+	}
+	return hexutil.Big(*state.GetBalance(a.address)), nil
+}
+
+func (a *Account) TransactionCount(ctx context.Context) (hexutil.Uint64, error) {
+	state, err := a.getState(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return hexutil.Uint64(state.GetNonce(a.address)), nil
+}
+
+func (a *Account) Code(ctx context.Context) (hexutil.Bytes, error) {
+	state, err := a.getState(ctx)
+	if err != nil {
+		return hexutil.Bytes{}, err
+	}
+	return hexutil.Bytes(state.GetCode(a.address)), nil
+}
+
+func (a *Account) Storage(ctx context.Context, args struct{ Slot common.Hash }) (common.Hash, error) {
+	state, err := a.getState(ctx)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return state.GetState(a.address, args.Slot), nil
+}
+
+// Log represents an individual log message. All arguments are mandatory.
+type Log struct {
+	r           *Resolver
+	transaction *Transaction
+	log         *types.Log
+}
+
+func (l *Log) Transaction(ctx context.Context) *Transaction {
+	return l.transaction
+}
+
+func (l *Log) Account(ctx context.Context, args BlockNumberArgs) *Account {
+	return &Account{
+		r:           l.r,
+		address:     l.log.Address,
+		blockNumber: args.Number(),
+	}
+}
+
+func (l *Log) Index(ctx context.Context) int32 {
+	return int32(l.log.Index)
+}
+
+func (l *Log) Topics(ctx context.Context) []common.Hash {
+	return l.log.Topics
+}
+
+func (l *Log) Data(ctx context.Context) hexutil.Bytes {
+	return hexutil.Bytes(l.log.Data)
+}
+
+// Transaction represents a transaction.
+type Transaction struct {
+	r     *Resolver
+	hash  common.Hash
+	tx    *types.Transaction
+	block *Block
+	index uint64
+}
+
+// resolve returns the internal transaction object, fetching it if needed.
+func (t *Transaction) resolve(ctx context.Context) (*types.Transaction, error) {
+	if t.tx == nil {
+		tx, blockHash, _, index := rawdb.ReadTransaction(t.r.backend.ChainDb(), t.hash)
+		if tx != nil {
+			t.tx = tx
+			t.block = &Block{
+				r:    t.r,
+				hash: blockHash,
+			}
+			t.index = index
+		} else {
+			t.tx = t.r.backend.GetPoolTransaction(t.hash)
+		}
+	}
+	return t.tx, nil
+}
+
+func (t *Transaction) Hash(ctx context.Context) common.Hash {
+	return t.hash
+}
+
+func (t *Transaction) InputData(ctx context.Context) (hexutil.Bytes, error) {
+	tx, err := t.resolve(ctx)
+	if err != nil || tx == nil {
+		return hexutil.Bytes{}, err
+	}
+	return hexutil.Bytes(tx.Data()), nil
+}
+
+func (t *Transaction) Gas(ctx context.Context) (hexutil.Uint64, error) {
+	tx, err := t.resolve(ctx)
+	if err != nil || tx == nil {
+		return 0, err
+	}
+	return hexutil.Uint64(tx.Gas()), nil
+}
+
+func (t *Transaction) GasPrice(ctx context.Context) (hexutil.Big, error) {
+	tx, err := t.resolve(ctx)
+	if err != nil || tx == nil {
+		return hexutil.Big{}, err
+	}
+	return hexutil.Big(*tx.GasPrice()), nil
+}
+
+func (t *Transaction) Value(ctx context.Context) (hexutil.Big, error) {
+	tx, err := t.resolve(ctx)
+	if err != nil || tx == nil {
+		return hexutil.Big{}, err
+	}
+	return hexutil.Big(*tx.Value()), nil
+}
+
+func (t *Transaction) Nonce(ctx context.Context) (hexutil.Uint64, error) {
+	tx, err := t.resolve(ctx)
+	if err != nil || tx == nil {
+		return 0, err
+	}
+	return hexutil.Uint64(tx.Nonce()), nil
+}
+
+func (t *Transaction) To(ctx context.Context, args BlockNumberArgs) (*Account, error) {
+	tx, err := t.resolve(ctx)
+	if err != nil || tx == nil {
+		return nil, err
+	}
+	return t.account(tx.To(), args), nil
+}
+
+func (t *Transaction) From(ctx context.Context, args BlockNumberArgs) (*Account, error) {
+	tx, err := t.resolve(ctx)
+	if err != nil || tx == nil {
+		return nil, err
+	}
+	var signer types.Signer = types.HomesteadSigner{}
+	if tx.Protected() {
+		signer = types.NewEIP155Signer(tx.ChainId())
+	}
+	from, err := types.Sender(signer, tx)
+	if err != nil {
+		return nil, err
+	}
+	return t.account(&from, args), nil
+}
+
+func (t *Transaction) FeeCurrency(ctx context.Context, args BlockNumberArgs) (*Account, error) {
+	tx, err := t.resolve(ctx)
+	if err != nil || tx == nil {
+		return nil, err
+	}
+	return t.account(tx.FeeCurrency(), args), nil
+}
+
+func (t *Transaction) GatewayFeeRecipient(ctx context.Context, args BlockNumberArgs) (*Account, error) {
+	tx, err := t.resolve(ctx)
+	if err != nil || tx == nil {
+		return nil, err
+	}
+	return t.account(tx.GatewayFeeRecipient(), args), nil
+}
+
+func (t *Transaction) GatewayFee(ctx context.Context) (hexutil.Big, error) {
+	tx, err := t.resolve(ctx)
+	if err != nil || tx == nil || tx.GatewayFee() == nil {
+		return hexutil.Big{}, err
+	}
+	return hexutil.Big(*tx.GatewayFee()), nil
+}
+
+// account returns the account at the given address, or nil if there is no address.
+func (t *Transaction) account(address *common.Address, args BlockNumberArgs) *Account {
+	if address == nil {
+		return nil
+	}
+	return &Account{
+		r:           t.r,
+		address:     *address,
+		blockNumber: args.Number(),
+	}
+}
+
+func (t *Transaction) Block(ctx context.Context) (*Block, error) {
+	if _, err := t.resolve(ctx); err != nil {
+		return nil, err
+	}
+	return t.block, nil
+}
+
+func (t *Transaction) Index(ctx context.Context) (*int32, error) {
+	if _, err := t.resolve(ctx); err != nil {
+		return nil, err
+	}
+	if t.block == nil {
+		return nil, nil
+	}
+	index := int32(t.index)
+	return &index, nil
+}
+
+// getReceipt returns the receipt associated with this transaction, if any.
+func (t *Transaction) getReceipt(ctx context.Context) (*types.Receipt, error) {
+	if _, err := t.resolve(ctx); err != nil {
+		return nil, err
+	}
+	if t.block == nil {
+		return nil, nil
+	}
+	receipts, err := t.block.resolveReceipts(ctx)
+	if err != nil || uint64(len(receipts)) <= t.index {
+		return nil, err
+	}
+	return receipts[t.index], nil
+}
+
+func (t *Transaction) Status(ctx context.Context) (*hexutil.Uint64, error) {
+	receipt, err := t.getReceipt(ctx)
+	if err != nil || receipt == nil {
+		return nil, err
+	}
+	ret := hexutil.Uint64(receipt.Status)
+	return &ret, nil
+}
+
+func (t *Transaction) GasUsed(ctx context.Context) (*hexutil.Uint64, error) {
+	receipt, err := t.getReceipt(ctx)
+	if err != nil || receipt == nil {
+		return nil, err
+	}
+	ret := hexutil.Uint64(receipt.GasUsed)
+	return &ret, nil
+}
+
+func (t *Transaction) CumulativeGasUsed(ctx context.Context) (*hexutil.Uint64, error) {
+	receipt, err := t.getReceipt(ctx)
+	if err != nil || receipt == nil {
+		return nil, err
+	}
+	ret := hexutil.Uint64(receipt.CumulativeGasUsed)
+	return &ret, nil
+}
+
+func (t *Transaction) CreatedContract(ctx context.Context, args BlockNumberArgs) (*Account, error) {
+	receipt, err := t.getReceipt(ctx)
+	if err != nil || receipt == nil || receipt.ContractAddress == (common.Address{}) {
+		return nil, err
+	}
+	return t.account(&receipt.ContractAddress, args), nil
+}
+
+func (t *Transaction) Logs(ctx context.Context) (*[]*Log, error) {
+	receipt, err := t.getReceipt(ctx)
+	if err != nil || receipt == nil {
+		return nil, err
+	}
+	ret := make([]*Log, 0, len(receipt.Logs))
+	for _, log := range receipt.Logs {
+		ret = append(ret, &Log{
+			r:           t.r,
+			transaction: t,
+			log:         log,
+		})
+	}
+	return &ret, nil
+}
+
+// Block represents a block. At least one of num or hash must be set. If the
+// block is looked up by number, it is expected to be canonical.
+type Block struct {
+	r        *Resolver
+	num      *rpc.BlockNumber
+	hash     common.Hash
+	header   *types.Header
+	block    *types.Block
+	receipts []*types.Receipt
+}
+
+// resolve returns the internal Block object representing this block, fetching
+// it if necessary.
+func (b *Block) resolve(ctx context.Context) (*types.Block, error) {
+	if b.block != nil {
+		return b.block, nil
+	}
+	var err error
+	if b.hash != (common.Hash{}) {
+		b.block, err = b.r.backend.GetBlock(ctx, b.hash)
+	} else if b.num != nil {
+		b.block, err = b.r.backend.BlockByNumber(ctx, *b.num)
+	} else {
+		return nil, errBlockInvariant
+	}
+	if b.block != nil {
+		b.header = b.block.Header()
+	}
+	return b.block, err
+}
+
+// resolveHeader returns the internal Header object for this block, fetching it
+// if necessary. Call this function instead of `resolve` unless you need the
+// additional data (transactions).
+func (b *Block) resolveHeader(ctx context.Context) (*types.Header, error) {
+	if b.header != nil {
+		return b.header, nil
+	}
+	var err error
+	if b.hash != (common.Hash{}) {
+		b.header, err = b.r.backend.HeaderByHash(ctx, b.hash)
+	} else if b.num != nil {
+		b.header, err = b.r.backend.HeaderByNumber(ctx, *b.num)
+	} else {
+		return nil, errBlockInvariant
+	}
+	if err == nil && b.header == nil {
+		err = errors.New("block not found")
+	}
+	return b.header, err
+}
+
+// resolveReceipts returns the list of receipts for this block, fetching them
+// if necessary.
+func (b *Block) resolveReceipts(ctx context.Context) ([]*types.Receipt, error) {
+	if b.receipts == nil {
+		hash := b.hash
+		if hash == (common.Hash{}) {
+			header, err := b.resolveHeader(ctx)
+			if err != nil {
+				return nil, err
+			}
+			hash = header.Hash()
+		}
+		receipts, err := b.r.backend.GetReceipts(ctx, hash)
+		if err != nil {
+			return nil, err
+		}
+		b.receipts = []*types.Receipt(receipts)
+	}
+	return b.receipts, nil
+}
+
+func (b *Block) Number(ctx context.Context) (hexutil.Uint64, error) {
+	header, err := b.resolveHeader(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return hexutil.Uint64(header.Number.Uint64()), nil
+}
+
+func (b *Block) Hash(ctx context.Context) (common.Hash, error) {
+	if b.hash == (common.Hash{}) {
+		header, err := b.resolveHeader(ctx)
+		if err != nil {
+			return common.Hash{}, err
+		}
+		b.hash = header.Hash()
+	}
+	return b.hash, nil
+}
+
+func (b *Block) GasLimit(ctx context.Context) (hexutil.Uint64, error) {
+	header, err := b.resolveHeader(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return hexutil.Uint64(header.GasLimit), nil
+}
+
+func (b *Block) GasUsed(ctx context.Context) (hexutil.Uint64, error) {
+	header, err := b.resolveHeader(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return hexutil.Uint64(header.GasUsed), nil
+}
+
+func (b *Block) Parent(ctx context.Context) (*Block, error) {
+	header, err := b.resolveHeader(ctx)
+	if err != nil || header.Number.Uint64() < 1 {
+		return nil, err
+	}
+	num := rpc.BlockNumber(header.Number.Uint64() - 1)
+	return &Block{
+		r:    b.r,
+		num:  &num,
+		hash: header.ParentHash,
+	}, nil
+}
+
+func (b *Block) Timestamp(ctx context.Context) (hexutil.Big, error) {
+	header, err := b.resolveHeader(ctx)
+	if err != nil {
+		return hexutil.Big{}, err
+	}
+	return hexutil.Big(*header.Time), nil
+}
+
+func (b *Block) ExtraData(ctx context.Context) (hexutil.Bytes, error) {
+	header, err := b.resolveHeader(ctx)
+	if err != nil {
+		return hexutil.Bytes{}, err
+	}
+	return hexutil.Bytes(header.Extra), nil
+}
+
+func (b *Block) LogsBloom(ctx context.Context) (hexutil.Bytes, error) {
+	header, err := b.resolveHeader(ctx)
+	if err != nil {
+		return hexutil.Bytes{}, err
+	}
+	return hexutil.Bytes(header.Bloom.Bytes()), nil
+}
+
+func (b *Block) StateRoot(ctx context.Context) (common.Hash, error) {
+	header, err := b.resolveHeader(ctx)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return header.Root, nil
+}
+
+func (b *Block) TransactionsRoot(ctx context.Context) (common.Hash, error) {
+	header, err := b.resolveHeader(ctx)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return header.TxHash, nil
+}
+
+func (b *Block) ReceiptsRoot(ctx context.Context) (common.Hash, error) {
+	header, err := b.resolveHeader(ctx)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return header.ReceiptHash, nil
+}
+
+// BlockNumberArgs encapsulates arguments to accessors that specify a block number.
+type BlockNumberArgs struct {
+	Block *hexutil.Uint64
+}
+
+// Number returns the provided block number, or rpc.LatestBlockNumber if none
+// was provided.
+func (a BlockNumberArgs) Number() rpc.BlockNumber {
+	if a.Block != nil {
+		return rpc.BlockNumber(*a.Block)
+	}
+	return rpc.LatestBlockNumber
+}
+
+func (b *Block) Miner(ctx context.Context, args BlockNumberArgs) (*Account, error) {
+	header, err := b.resolveHeader(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &Account{
+		r:           b.r,
+		address:     header.Coinbase,
+		blockNumber: args.Number(),
+	}, nil
+}
+
+func (b *Block) TransactionCount(ctx context.Context) (*int32, error) {
+	block, err := b.resolve(ctx)
+	if err != nil || block == nil {
+		return nil, err
+	}
+	count := int32(len(block.Transactions()))
+	return &count, err
+}
+
+func (b *Block) Transactions(ctx context.Context, args struct {
+	First *int32
+	Skip  *int32
+}) (*[]*Transaction, error) {
+	block, err := b.resolve(ctx)
+	if err != nil || block == nil {
+		return nil, err
+	}
+	txs := block.Transactions()
+	start, end, err := paginate(len(txs), args.First, args.Skip)
+	if err != nil {
+		return nil, err
+	}
+	ret := make([]*Transaction, 0, end-start)
+	for i := start; i < end; i++ {
+		ret = append(ret, &Transaction{
+			r:     b.r,
+			hash:  txs[i].Hash(),
+			tx:    txs[i],
+			block: b,
+			index: uint64(i),
+		})
+	}
+	return &ret, nil
+}
+
+func (b *Block) TransactionAt(ctx context.Context, args struct{ Index int32 }) (*Transaction, error) {
+	block, err := b.resolve(ctx)
+	if err != nil || block == nil {
+		return nil, err
+	}
+	txs := block.Transactions()
+	if args.Index < 0 || int(args.Index) >= len(txs) {
+		return nil, nil
+	}
+	tx := txs[args.Index]
+	return &Transaction{
+		r:     b.r,
+		hash:  tx.Hash(),
+		tx:    tx,
+		block: b,
+		index: uint64(args.Index),
+	}, nil
+}
+
+// BlockFilterCriteria encapsulates criteria passed to a `logs` accessor inside
+// a block.
+type BlockFilterCriteria struct {
+	Addresses *[]common.Address // restricts matches to events created by specific contracts
+
+	// The Topic list restricts matches to particular event topics. Each event has a list
+	// of topics. Topics matches a prefix of that list. An empty element slice matches any
+	// topic. Non-empty elements represent an alternative that matches any of the
+	// contained topics.
+	//
+	// Examples:
+	// {} or nil          matches any topic list
+	// {{A}}              matches topic A in first position
+	// {{}, {B}}          matches any topic in first position, B in second position
+	// {{A}, {B}}         matches topic A in first position, B in second position
+	// {{A, B}, {C, D}}   matches topic (A OR B) in first position, (C OR D) in second position
+	Topics *[][]common.Hash
+}
+
+// runFilter accepts a filter and executes it, returning all its results as
+// `Log` objects.
+func runFilter(ctx context.Context, r *Resolver, filter *filters.Filter) ([]*Log, error) {
+	logs, err := filter.Logs(ctx)
+	if err != nil || logs == nil {
+		return nil, err
+	}
+	ret := make([]*Log, 0, len(logs))
+	for _, log := range logs {
+		ret = append(ret, &Log{
+			r:           r,
+			transaction: &Transaction{r: r, hash: log.TxHash},
+			log:         log,
+		})
+	}
+	return ret, nil
+}
+
+func (b *Block) Logs(ctx context.Context, args struct{ Filter BlockFilterCriteria }) ([]*Log, error) {
+	var addresses []common.Address
+	if args.Filter.Addresses != nil {
+		addresses = *args.Filter.Addresses
+	}
+	var topics [][]common.Hash
+	if args.Filter.Topics != nil {
+		topics = *args.Filter.Topics
+	}
+	hash, err := b.Hash(ctx)
+	if err != nil {
+		return nil, err
+	}
+	filter := filters.NewBlockFilter(b.r.backend, hash, addresses, topics)
+
+	// Run the filter and return all the logs
+	return runFilter(ctx, b.r, filter)
+}
+
+func (b *Block) Account(ctx context.Context, args struct {
+	Address common.Address
+}) (*Account, error) {
+	header, err := b.resolveHeader(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &Account{
+		r:           b.r,
+		address:     args.Address,
+		blockNumber: rpc.BlockNumber(header.Number.Uint64()),
+	}, nil
+}
+
+func (b *Block) Istanbul(ctx context.Context) (*Istanbul, error) {
+	if b.r.engine == nil {
+		return nil, nil
+	}
+	header, err := b.resolveHeader(ctx)
+	if err != nil {
+		return nil, err
+	}
+	extra, err := types.ExtractIstanbulExtra(header)
+	if err != nil {
+		return nil, err
+	}
+	return &Istanbul{
+		r:      b.r,
+		header: header,
+		extra:  extra,
+	}, nil
+}
+
+// Istanbul represents the consensus information of a block.
+type Istanbul struct {
+	r      *Resolver
+	header *types.Header
+	extra  *types.IstanbulExtra
+}
+
+// validators returns the validator set the block was sealed by, which is the set
+// elected for the epoch of the block.
+func (i *Istanbul) validators(header *types.Header) []istanbul.Validator {
+	if header.Number.Sign() == 0 {
+		return i.r.engine.GetValidators(header.Number, header.Hash())
+	}
+	return i.r.engine.GetValidators(new(big.Int).Sub(header.Number, common.Big1), header.ParentHash)
+}
+
+// account returns the account at the given address, at the state of the block.
+func (i *Istanbul) account(address common.Address) *Account {
+	return &Account{
+		r:           i.r,
+		address:     address,
+		blockNumber: rpc.BlockNumber(i.header.Number.Uint64()),
+	}
+}
+
+func (i *Istanbul) Round(ctx context.Context) hexutil.Uint64 {
+	if i.extra.AggregatedSeal.Round == nil {
+		return 0
+	}
+	return hexutil.Uint64(i.extra.AggregatedSeal.Round.Uint64())
+}
+
+func (i *Istanbul) Epoch(ctx context.Context) hexutil.Uint64 {
+	return hexutil.Uint64(istanbul.GetEpochNumber(i.header.Number.Uint64(), i.r.engine.EpochSize()))
+}
+
+func (i *Istanbul) Proposer(ctx context.Context) (*Account, error) {
+	proposer, err := i.r.engine.Author(i.header)
+	if err != nil {
+		return nil, err
+	}
+	return i.account(proposer), nil
+}
+
+func (i *Istanbul) Validators(ctx context.Context) []*Account {
+	validators := i.validators(i.header)
+	ret := make([]*Account, 0, len(validators))
+	for _, validator := range validators {
+		ret = append(ret, i.account(validator.Address()))
+	}
+	return ret
+}
+
+func (i *Istanbul) SignerBitmap(ctx context.Context) hexutil.Big {
+	if i.extra.AggregatedSeal.Bitmap == nil {
+		return hexutil.Big{}
+	}
+	return hexutil.Big(*i.extra.AggregatedSeal.Bitmap)
+}
+
+func (i *Istanbul) Signers(ctx context.Context) []*Account {
+	var ret []*Account
+	bitmap := i.extra.AggregatedSeal.Bitmap
+	if bitmap == nil {
+		return ret
+	}
+	for index, validator := range i.validators(i.header) {
+		if bitmap.Bit(index) == 1 {
+			ret = append(ret, i.account(validator.Address()))
+		}
+	}
+	return ret
+}
+
+// ValidatorUptime is the uptime of a validator tallied in an epoch.
+type ValidatorUptime struct {
+	validator *Account
+	uptime    istanbul.Uptime
+}
+
+func (u *ValidatorUptime) Validator(ctx context.Context) *Account {
+	return u.validator
+}
+
+func (u *ValidatorUptime) ScoreTally(ctx context.Context) hexutil.Uint64 {
+	return hexutil.Uint64(u.uptime.ScoreTally)
+}
+
+func (u *ValidatorUptime) LastSignedBlock(ctx context.Context) hexutil.Uint64 {
+	return hexutil.Uint64(u.uptime.LastSignedBlock)
+}
+
+// Uptime returns the uptime tallies the node accumulated for the validator score update
+// of the epoch of the block. They are indexed like the validator set of the epoch, and
+// are missing on nodes that do not process the blocks, such as light clients.
+func (i *Istanbul) Uptime(ctx context.Context) []*ValidatorUptime {
+	epoch := istanbul.GetEpochNumber(i.header.Number.Uint64(), i.r.engine.EpochSize())
+	uptimes := rawdb.ReadAccumulatedEpochUptime(i.r.backend.ChainDb(), epoch)
+
+	validators := i.validators(i.header)
+	ret := make([]*ValidatorUptime, 0, len(validators))
+	for index, validator := range validators {
+		if index >= len(uptimes) {
+			break
+		}
+		ret = append(ret, &ValidatorUptime{
+			validator: i.account(validator.Address()),
+			uptime:    uptimes[index],
+		})
+	}
+	return ret
+}
+
+// Pending represents the current pending state.
+type Pending struct {
+	r *Resolver
+}
+
+func (p *Pending) TransactionCount(ctx context.Context) (int32, error) {
+	txs, err := p.r.backend.GetPoolTransactions()
+	return int32(len(txs)), err
+}
+
+func (p *Pending) Transactions(ctx context.Context, args struct {
+	First *int32
+	Skip  *int32
+}) (*[]*Transaction, error) {
+	txs, err := p.r.backend.GetPoolTransactions()
+	if err != nil {
+		return nil, err
+	}
+	start, end, err := paginate(len(txs), args.First, args.Skip)
+	if err != nil {
+		return nil, err
+	}
+	ret := make([]*Transaction, 0, end-start)
+	for _, tx := range txs[start:end] {
+		ret = append(ret, &Transaction{
+			r:    p.r,
+			hash: tx.Hash(),
+			tx:   tx,
+		})
+	}
+	return &ret, nil
+}
+
+func (p *Pending) Account(ctx context.Context, args struct {
+	Address common.Address
+}) *Account {
+	return &Account{
+		r:           p.r,
+		address:     args.Address,
+		blockNumber: rpc.PendingBlockNumber,
+	}
+}
+
+// Resolver is the top-level object in the GraphQL hierarchy.
+type Resolver struct {
+	backend Backend
+	engine  istanbulEngine
+}
+
+func (r *Resolver) Block(ctx context.Context, args struct {
+	Number *hexutil.Uint64
+	Hash   *common.Hash
+}) (*Block, error) {
+	var block *Block
+	if args.Number != nil {
+		num := rpc.BlockNumber(uint64(*args.Number))
+		block = &Block{
+			r:   r,
+			num: &num,
+		}
+	} else if args.Hash != nil {
+		block = &Block{
+			r:    r,
+			hash: *args.Hash,
+		}
+	} else {
+		num := rpc.LatestBlockNumber
+		block = &Block{
+			r:   r,
+			num: &num,
+		}
+	}
+	// Resolve the block, return nil if it doesn't exist.
+	header, err := block.resolveHeader(ctx)
+	if err != nil || header == nil {
+		return nil, nil
+	}
+	return block, nil
+}
+
+func (r *Resolver) Blocks(ctx context.Context, args struct {
+	From  hexutil.Uint64
+	To    *hexutil.Uint64
+	First *int32
+	Skip  *int32
+}) ([]*Block, error) {
+	from := uint64(args.From)
+
+	var to uint64
+	if args.To != nil {
+		to = uint64(*args.To)
+	} else {
+		header, err := r.backend.HeaderByNumber(ctx, rpc.LatestBlockNumber)
+		if err != nil {
+			return nil, err
+		}
+		to = header.Number.Uint64()
+	}
+	if to < from {
+		return []*Block{}, nil
+	}
+	// Pages can't reach beyond the int32 skip argument, so cap the range accordingly
+	total := to - from + 1
+	if total > math.MaxInt32 {
+		total = math.MaxInt32
+	}
+	start, end, err := paginate(int(total), args.First, args.Skip)
+	if err != nil {
+		return nil, err
+	}
+	ret := make([]*Block, 0, end-start)
+	for i := from + uint64(start); i < from+uint64(end); i++ {
+		num := rpc.BlockNumber(i)
+		block := &Block{
+			r:   r,
+			num: &num,
+		}
+		// Stop at the first block that doesn't exist yet
+		if header, err := block.resolveHeader(ctx); err != nil || header == nil {
+			break
+		}
+		ret = append(ret, block)
+	}
+	return ret, nil
+}
+
+func (r *Resolver) Pending(ctx context.Context) *Pending {
+	return &Pending{r}
+}
+
+func (r *Resolver) Transaction(ctx context.Context, args struct{ Hash common.Hash }) (*Transaction, error) {
+	tx := &Transaction{
+		r:    r,
+		hash: args.Hash,
+	}
+	// Resolve the transaction; if it doesn't exist, return nil.
+	t, err := tx.resolve(ctx)
+	if err != nil {
+		return nil, err
+	} else if t == nil {
+		return nil, nil
+	}
+	return tx, nil
+}
+
+func (r *Resolver) SendRawTransaction(ctx context.Context, args struct{ Data hexutil.Bytes }) (common.Hash, error) {
+	tx := new(types.Transaction)
+	if err := rlp.DecodeBytes(args.Data, tx); err != nil {
+		return common.Hash{}, err
+	}
+	if err := r.backend.SendTx(ctx, tx); err != nil {
+		return common.Hash{}, err
+	}
+	return tx.Hash(), nil
+}
+
+// FilterCriteria encapsulates the arguments to `logs` on the root resolver object.
+type FilterCriteria struct {
+	FromBlock *hexutil.Uint64   // beginning of the queried range, nil means genesis block
+	ToBlock   *hexutil.Uint64   // end of the range, nil means latest block
+	Addresses *[]common.Address // restricts matches to events created by specific contracts
+
+	// The Topic list restricts matches to particular event topics. Each event has a list
+	// of topics. Topics matches a prefix of that list. An empty element slice matches any
+	// topic. Non-empty elements represent an alternative that matches any of the
+	// contained topics.
+	Topics *[][]common.Hash
+}
+
+func (r *Resolver) Logs(ctx context.Context, args struct {
+	Filter FilterCriteria
+	First  *int32
+	Skip   *int32
+}) ([]*Log, error) {
+	// Convert the RPC block numbers into internal representations
+	begin := rpc.LatestBlockNumber.Int64()
+	if args.Filter.FromBlock != nil {
+		begin = int64(*args.Filter.FromBlock)
+	}
+	end := rpc.LatestBlockNumber.Int64()
+	if args.Filter.ToBlock != nil {
+		end = int64(*args.Filter.ToBlock)
+	}
+	var addresses []common.Address
+	if args.Filter.Addresses != nil {
+		addresses = *args.Filter.Addresses
+	}
+	var topics [][]common.Hash
+	if args.Filter.Topics != nil {
+		topics = *args.Filter.Topics
+	}
+	// Construct the range filter, restricted to the requested page so that the
+	// search stops as soon as the page is filled
+	skip, limit, err := pageLimits(args.First, args.Skip)
+	if err != nil || limit == 0 {
+		return []*Log{}, err
+	}
+	filter := filters.NewRangeFilter(filters.Backend(r.backend), begin, end, addresses, topics)
+	filter.SetPage(skip, limit)
+
+	// Run the filter and return the requested page of logs
+	logs, err := runFilter(ctx, r, filter)
+	if err != nil {
+		return nil, err
+	}
+	if len(logs) > maxPageSize {
+		return nil, errPageSize
+	}
+	return logs, nil
+}
+
+func (r *Resolver) GasPrice(ctx context.Context) (hexutil.Big, error) {
+	price, err := r.backend.SuggestPrice(ctx)
+	if err != nil {
+		return hexutil.Big{}, err
+	}
+	return hexutil.Big(*price), nil
+}
+
+func (r *Resolver) ProtocolVersion(ctx context.Context) (int32, error) {
+	return int32(r.backend.ProtocolVersion()), nil
+}
+
+// SyncState represents the synchronisation status returned from the `syncing` accessor.
+type SyncState struct {
+	progress ethereum.SyncProgress
+}
+
+func (s *SyncState) StartingBlock() hexutil.Uint64 {
+	return hexutil.Uint64(s.progress.StartingBlock)
+}
+
+func (s *SyncState) CurrentBlock() hexutil.Uint64 {
+	return hexutil.Uint64(s.progress.CurrentBlock)
+}
+
+func (s *SyncState) HighestBlock() hexutil.Uint64 {
+	return hexutil.Uint64(s.progress.HighestBlock)
+}
+
+func (s *SyncState) PulledStates() *hexutil.Uint64 {
+	ret := hexutil.Uint64(s.progress.PulledStates)
+	return &ret
+}
+
+func (s *SyncState) KnownStates() *hexutil.Uint64 {
+	ret := hexutil.Uint64(s.progress.KnownStates)
+	return &ret
+}
+
+// Syncing returns false in case the node is currently not syncing with the network. It can be up to date or has not
+// yet received the latest block headers from its pears. In case it is synchronizing:
+// - startingBlock: block number this node started to synchronise from
+// - currentBlock:  block number this node is currently importing
+// - highestBlock:  block number of the highest block header this node has received from peers
+// - pulledStates:  number of state entries processed until now
+// - knownStates:   number of known state entries that still need to be pulled
+func (r *Resolver) Syncing() (*SyncState, error) {
+	progress := r.backend.Downloader().Progress()
+
+	// Return not syncing if the synchronisation already completed
+	if progress.CurrentBlock >= progress.HighestBlock {
+		return nil, nil
+	}
+	// Otherwise gather the block sync stats
+	return &SyncState{progress}, nil
+}
@@ -0,0 +1,470 @@
+// Copyright 2019 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/consensus/istanbul"
+	"github.com/ethereum/go-ethereum/consensus/istanbul/validator"
+	"github.com/ethereum/go-ethereum/core/bloombits"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/eth/downloader"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/event"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/graph-gophers/graphql-go"
+)
+
+func TestPaginate(t *testing.T) {
+	intPtr := func(i int32) *int32 { return &i }
+
+	tests := []struct {
+		total       int
+		first, skip *int32
+		start, end  int
+		err         error
+	}{
+		{total: 10, start: 0, end: 10},
+		{total: 10, first: intPtr(3), start: 0, end: 3},
+		{total: 10, first: intPtr(3), skip: intPtr(8), start: 8, end: 10},
+		{total: 10, skip: intPtr(20), start: 10, end: 10},
+		{total: 10, first: intPtr(0), start: 0, end: 0},
+		{total: maxPageSize + 1, err: errPageSize},
+		{total: maxPageSize + 1, skip: intPtr(1), start: 1, end: maxPageSize + 1},
+		{total: maxPageSize * 3, first: intPtr(maxPageSize), skip: intPtr(maxPageSize), start: maxPageSize, end: 2 * maxPageSize},
+		{total: 10, first: intPtr(maxPageSize + 1), err: errPageSize},
+		{total: 10, first: intPtr(-1), err: errNegativeFirst},
+		{total: 10, skip: intPtr(-1), err: errNegativeSkip},
+	}
+	for i, tt := range tests {
+		start, end, err := paginate(tt.total, tt.first, tt.skip)
+		if err != tt.err {
+			t.Errorf("test %d: error mismatch: have %v, want %v", i, err, tt.err)
+			continue
+		}
+		if err == nil && (start != tt.start || end != tt.end) {
+			t.Errorf("test %d: page mismatch: have [%d, %d), want [%d, %d)", i, start, end, tt.start, tt.end)
+		}
+	}
+}
+
+func TestPageLimits(t *testing.T) {
+	intPtr := func(i int32) *int32 { return &i }
+
+	tests := []struct {
+		first, skip  *int32
+		skipped, max int
+		err          error
+	}{
+		{skipped: 0, max: maxPageSize + 1},
+		{first: intPtr(3), skip: intPtr(8), skipped: 8, max: 3},
+		{first: intPtr(0), skipped: 0, max: 0},
+		{first: intPtr(maxPageSize + 1), err: errPageSize},
+		{first: intPtr(-1), err: errNegativeFirst},
+		{skip: intPtr(-1), err: errNegativeSkip},
+	}
+	for i, tt := range tests {
+		skipped, max, err := pageLimits(tt.first, tt.skip)
+		if err != tt.err {
+			t.Errorf("test %d: error mismatch: have %v, want %v", i, err, tt.err)
+			continue
+		}
+		if err == nil && (skipped != tt.skipped || max != tt.max) {
+			t.Errorf("test %d: limits mismatch: have (%d, %d), want (%d, %d)", i, skipped, max, tt.skipped, tt.max)
+		}
+	}
+}
+
+// testBackend serves a chain written to an in-memory database, along with the pending
+// transactions and the transactions sent through it. The methods the resolvers don't
+// use are left to the embedded interface.
+type testBackend struct {
+	Backend
+
+	db         ethdb.Database
+	state      state.Database
+	head       *types.Block
+	pending    types.Transactions
+	sent       types.Transactions
+	downloader *downloader.Downloader
+}
+
+func (b *testBackend) ChainDb() ethdb.Database { return b.db }
+
+func (b *testBackend) HeaderByNumber(ctx context.Context, number rpc.BlockNumber) (*types.Header, error) {
+	if number == rpc.LatestBlockNumber || number == rpc.PendingBlockNumber {
+		return b.head.Header(), nil
+	}
+	hash := rawdb.ReadCanonicalHash(b.db, uint64(number))
+	return rawdb.ReadHeader(b.db, hash, uint64(number)), nil
+}
+
+func (b *testBackend) HeaderByHash(ctx context.Context, hash common.Hash) (*types.Header, error) {
+	number := rawdb.ReadHeaderNumber(b.db, hash)
+	if number == nil {
+		return nil, nil
+	}
+	return rawdb.ReadHeader(b.db, hash, *number), nil
+}
+
+func (b *testBackend) BlockByNumber(ctx context.Context, number rpc.BlockNumber) (*types.Block, error) {
+	header, _ := b.HeaderByNumber(ctx, number)
+	if header == nil {
+		return nil, nil
+	}
+	return rawdb.ReadBlock(b.db, header.Hash(), header.Number.Uint64()), nil
+}
+
+func (b *testBackend) GetBlock(ctx context.Context, hash common.Hash) (*types.Block, error) {
+	number := rawdb.ReadHeaderNumber(b.db, hash)
+	if number == nil {
+		return nil, nil
+	}
+	return rawdb.ReadBlock(b.db, hash, *number), nil
+}
+
+func (b *testBackend) GetReceipts(ctx context.Context, hash common.Hash) (types.Receipts, error) {
+	number := rawdb.ReadHeaderNumber(b.db, hash)
+	if number == nil {
+		return nil, nil
+	}
+	return rawdb.ReadReceipts(b.db, hash, *number), nil
+}
+
+func (b *testBackend) GetLogs(ctx context.Context, hash common.Hash) ([][]*types.Log, error) {
+	receipts, _ := b.GetReceipts(ctx, hash)
+	logs := make([][]*types.Log, len(receipts))
+	for i, receipt := range receipts {
+		logs[i] = receipt.Logs
+	}
+	return logs, nil
+}
+
+func (b *testBackend) StateAndHeaderByNumber(ctx context.Context, number rpc.BlockNumber) (*state.StateDB, *types.Header, error) {
+	header, _ := b.HeaderByNumber(ctx, number)
+	if header == nil {
+		return nil, nil, fmt.Errorf("block %d not found", number)
+	}
+	statedb, err := state.New(header.Root, b.state)
+	return statedb, header, err
+}
+
+func (b *testBackend) GetPoolTransactions() (types.Transactions, error) { return b.pending, nil }
+
+func (b *testBackend) GetPoolTransaction(hash common.Hash) *types.Transaction {
+	for _, tx := range b.pending {
+		if tx.Hash() == hash {
+			return tx
+		}
+	}
+	return nil
+}
+
+func (b *testBackend) SendTx(ctx context.Context, tx *types.Transaction) error {
+	b.sent = append(b.sent, tx)
+	return nil
+}
+
+func (b *testBackend) SuggestPrice(ctx context.Context) (*big.Int, error)                   { return big.NewInt(5), nil }
+func (b *testBackend) ProtocolVersion() int                                                 { return 64 }
+func (b *testBackend) Downloader() *downloader.Downloader                                   { return b.downloader }
+func (b *testBackend) BloomStatus() (uint64, uint64)                                        { return params.BloomBitsBlocks, 0 }
+func (b *testBackend) ServiceFilter(ctx context.Context, session *bloombits.MatcherSession) {}
+
+// testLightChain is the synced header chain the downloader reports progress against.
+type testLightChain struct {
+	downloader.LightChain
+	head *types.Header
+}
+
+func (c *testLightChain) Config() *params.ChainConfig  { return nil }
+func (c *testLightChain) CurrentHeader() *types.Header { return c.head }
+
+// testEngine elects the same validators for every block and attributes blocks to
+// their coinbase.
+type testEngine struct {
+	validators []istanbul.Validator
+}
+
+func (e *testEngine) Author(header *types.Header) (common.Address, error) {
+	return header.Coinbase, nil
+}
+func (e *testEngine) EpochSize() uint64 { return 10 }
+func (e *testEngine) GetValidators(number *big.Int, hash common.Hash) []istanbul.Validator {
+	return e.validators
+}
+
+// testChain is the content of the test backend.
+type testChain struct {
+	backend    *testBackend
+	engine     *testEngine
+	sender     common.Address
+	contract   common.Address
+	topic      common.Hash
+	block      *types.Block
+	tx         *types.Transaction
+	pendingTx  *types.Transaction
+	validators []common.Address
+}
+
+// newTestChain writes a genesis block and a block 1 with a single transaction emitting
+// a log, sealed by the first and third of three validators in round 2, and queues
+// another transaction in the pool.
+func newTestChain(t *testing.T) *testChain {
+	key, _ := crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+	c := &testChain{
+		engine:   new(testEngine),
+		sender:   crypto.PubkeyToAddress(key.PublicKey),
+		contract: common.HexToAddress("0xc0de"),
+		topic:    common.HexToHash("0x70f1c"),
+	}
+	for i := 1; i <= 3; i++ {
+		address := common.BigToAddress(big.NewInt(int64(i)))
+		c.validators = append(c.validators, address)
+		c.engine.validators = append(c.engine.validators, validator.New(address, nil))
+	}
+	db := ethdb.NewMemDatabase()
+	sdb := state.NewDatabase(db)
+
+	statedb, _ := state.New(common.Hash{}, sdb)
+	statedb.SetBalance(c.sender, big.NewInt(100))
+	statedb.SetNonce(c.sender, 1)
+	statedb.SetCode(c.contract, []byte{0x60, 0x00})
+	statedb.SetState(c.contract, common.BigToHash(common.Big1), common.BigToHash(big.NewInt(42)))
+	root, err := statedb.Commit(false)
+	if err != nil {
+		t.Fatalf("failed to commit state: %v", err)
+	}
+
+	extra := func(seal types.IstanbulAggregatedSeal) []byte {
+		payload, err := rlp.EncodeToBytes(&types.IstanbulExtra{RemovedValidators: new(big.Int), AggregatedSeal: seal})
+		if err != nil {
+			t.Fatalf("failed to encode istanbul extra: %v", err)
+		}
+		return append(make([]byte, types.IstanbulExtraVanity), payload...)
+	}
+	genesis := types.NewBlock(&types.Header{
+		Number:   common.Big0,
+		Time:     common.Big0,
+		GasLimit: 1000000,
+		Root:     root,
+		Extra:    extra(types.IstanbulAggregatedSeal{}),
+	}, nil, nil, nil, nil)
+
+	signer := types.NewEIP155Signer(big.NewInt(1))
+	feeCurrency, gatewayFeeRecipient := common.HexToAddress("0xfee"), common.HexToAddress("0x9a7e")
+	c.tx, _ = types.SignTx(types.NewTransaction(0, c.contract, big.NewInt(1), 50000, big.NewInt(2), &feeCurrency, &gatewayFeeRecipient, big.NewInt(3), []byte{0x12, 0x34}), signer, key)
+	c.pendingTx, _ = types.SignTx(types.NewTransaction(1, c.contract, big.NewInt(1), 50000, big.NewInt(2), nil, nil, big.NewInt(4), nil), signer, key)
+
+	receipt := &types.Receipt{
+		Status:            types.ReceiptStatusSuccessful,
+		CumulativeGasUsed: 21000,
+		GasUsed:           21000,
+		TxHash:            c.tx.Hash(),
+		Logs: []*types.Log{{
+			Address:     c.contract,
+			Topics:      []common.Hash{c.topic},
+			Data:        []byte{0xab},
+			BlockNumber: 1,
+			TxHash:      c.tx.Hash(),
+		}},
+	}
+	receipt.Bloom = types.CreateBloom(types.Receipts{receipt})
+	c.block = types.NewBlock(&types.Header{
+		ParentHash: genesis.Hash(),
+		Coinbase:   c.validators[0],
+		Number:     common.Big1,
+		Time:       big.NewInt(10),
+		GasLimit:   1000000,
+		GasUsed:    21000,
+		Root:       root,
+		Extra:      extra(types.IstanbulAggregatedSeal{Bitmap: big.NewInt(5), Round: big.NewInt(2)}),
+	}, types.Transactions{c.tx}, nil, types.Receipts{receipt}, nil)
+	receipt.Logs[0].BlockHash = c.block.Hash()
+
+	for _, block := range []*types.Block{genesis, c.block} {
+		rawdb.WriteBlock(db, block)
+		rawdb.WriteCanonicalHash(db, block.Hash(), block.NumberU64())
+		rawdb.WriteTxLookupEntries(db, block)
+	}
+	rawdb.WriteReceipts(db, c.block.Hash(), 1, types.Receipts{receipt})
+	rawdb.WriteAccumulatedEpochUptime(db, 1, []istanbul.Uptime{{ScoreTally: 1, LastSignedBlock: 0}, {}, {ScoreTally: 1, LastSignedBlock: 0}})
+
+	c.backend = &testBackend{
+		db:         db,
+		state:      sdb,
+		head:       c.block,
+		pending:    types.Transactions{c.pendingTx},
+		downloader: downloader.New(downloader.LightSync, db, new(event.TypeMux), nil, &testLightChain{head: c.block.Header()}, nil),
+	}
+	return c
+}
+
+// query runs the given query against the schema, returning the data or the errors.
+func (c *testChain) query(t *testing.T, query string) (string, error) {
+	schema, err := graphql.ParseSchema(schema, &Resolver{backend: c.backend, engine: c.engine})
+	if err != nil {
+		t.Fatalf("failed to parse schema: %v", err)
+	}
+	res := schema.Exec(context.Background(), query, "", nil)
+	if len(res.Errors) > 0 {
+		return "", res.Errors[0]
+	}
+	return string(res.Data), nil
+}
+
+func address(a common.Address) string { return strings.ToLower(a.Hex()) }
+
+func TestQueries(t *testing.T) {
+	c := newTestChain(t)
+	defer c.backend.downloader.Terminate()
+
+	raw, _ := rlp.EncodeToBytes(c.pendingTx)
+	tests := []struct {
+		name     string
+		query    string
+		expected string
+		err      bool
+	}{
+		{
+			name:  "block",
+			query: `{ block(number: 1) { number hash parent { number } miner { address } gasUsed timestamp transactionCount } }`,
+			expected: fmt.Sprintf(`{"block":{"number":"0x1","hash":"%s","parent":{"number":"0x0"},"miner":{"address":"%s"},"gasUsed":"0x5208","timestamp":"0xa","transactionCount":1}}`,
+				c.block.Hash().Hex(), address(c.validators[0])),
+		},
+		{
+			name:     "block_by_hash",
+			query:    fmt.Sprintf(`{ block(hash: "%s") { number } }`, c.block.Hash().Hex()),
+			expected: `{"block":{"number":"0x1"}}`,
+		},
+		{
+			name:     "unknown_block",
+			query:    `{ block(number: 2) { number } }`,
+			expected: `{"block":null}`,
+		},
+		{
+			name:  "istanbul",
+			query: `{ block { istanbul { round epoch proposer { address } validators { address } signerBitmap signers { address } uptime { validator { address } scoreTally lastSignedBlock } } } }`,
+			expected: fmt.Sprintf(`{"block":{"istanbul":{"round":"0x2","epoch":"0x1","proposer":{"address":"%[1]s"},"validators":[{"address":"%[1]s"},{"address":"%[2]s"},{"address":"%[3]s"}],"signerBitmap":"0x5","signers":[{"address":"%[1]s"},{"address":"%[3]s"}],`+
+				`"uptime":[{"validator":{"address":"%[1]s"},"scoreTally":"0x1","lastSignedBlock":"0x0"},{"validator":{"address":"%[2]s"},"scoreTally":"0x0","lastSignedBlock":"0x0"},{"validator":{"address":"%[3]s"},"scoreTally":"0x1","lastSignedBlock":"0x0"}]}}}`,
+				address(c.validators[0]), address(c.validators[1]), address(c.validators[2])),
+		},
+		{
+			name: "block_transactions",
+			query: `{ block(number: 1) { transactions(first: 1) { hash nonce index from { address balance transactionCount } to { address code storage(slot: "0x0000000000000000000000000000000000000000000000000000000000000001") } ` +
+				`value gasPrice gas feeCurrency { address } gatewayFeeRecipient { address } gatewayFee inputData status gasUsed cumulativeGasUsed createdContract { address } logs { index data } } transactionAt(index: 0) { hash } } }`,
+			expected: fmt.Sprintf(`{"block":{"transactions":[{"hash":"%[1]s","nonce":"0x0","index":0,"from":{"address":"%[2]s","balance":"0x64","transactionCount":"0x1"},`+
+				`"to":{"address":"%[3]s","code":"0x6000","storage":"0x000000000000000000000000000000000000000000000000000000000000002a"},"value":"0x1","gasPrice":"0x2","gas":"0xc350",`+
+				`"feeCurrency":{"address":"0x0000000000000000000000000000000000000fee"},"gatewayFeeRecipient":{"address":"0x0000000000000000000000000000000000009a7e"},"gatewayFee":"0x3","inputData":"0x1234",`+
+				`"status":"0x1","gasUsed":"0x5208","cumulativeGasUsed":"0x5208","createdContract":null,"logs":[{"index":0,"data":"0xab"}]}],"transactionAt":{"hash":"%[1]s"}}}`,
+				c.tx.Hash().Hex(), address(c.sender), address(c.contract)),
+		},
+		{
+			name:  "block_logs",
+			query: fmt.Sprintf(`{ block(number: 1) { logs(filter: {addresses: ["%s"]}) { index topics data account { address } transaction { hash } } } }`, c.contract.Hex()),
+			expected: fmt.Sprintf(`{"block":{"logs":[{"index":0,"topics":["%s"],"data":"0xab","account":{"address":"%s"},"transaction":{"hash":"%s"}}]}}`,
+				c.topic.Hex(), address(c.contract), c.tx.Hash().Hex()),
+		},
+		{
+			name:     "blocks",
+			query:    `{ blocks(from: 0, skip: 1) { number } }`,
+			expected: `{"blocks":[{"number":"0x1"}]}`,
+		},
+		{
+			name:  "blocks_page_too_large",
+			query: `{ blocks(from: 0, first: 1001) { number } }`,
+			err:   true,
+		},
+		{
+			name:     "transaction",
+			query:    fmt.Sprintf(`{ transaction(hash: "%s") { block { number } index gatewayFee } }`, c.tx.Hash().Hex()),
+			expected: `{"transaction":{"block":{"number":"0x1"},"index":0,"gatewayFee":"0x3"}}`,
+		},
+		{
+			name:     "pending_transaction",
+			query:    fmt.Sprintf(`{ transaction(hash: "%s") { block { number } index feeCurrency { address } gatewayFee } }`, c.pendingTx.Hash().Hex()),
+			expected: `{"transaction":{"block":null,"index":null,"feeCurrency":null,"gatewayFee":"0x4"}}`,
+		},
+		{
+			name:     "logs",
+			query:    fmt.Sprintf(`{ logs(filter: {fromBlock: 0, topics: [["%s"]]}) { index transaction { hash } } }`, c.topic.Hex()),
+			expected: fmt.Sprintf(`{"logs":[{"index":0,"transaction":{"hash":"%s"}}]}`, c.tx.Hash().Hex()),
+		},
+		{
+			name:     "logs_skipped",
+			query:    `{ logs(filter: {fromBlock: 0}, skip: 1) { index } }`,
+			expected: `{"logs":[]}`,
+		},
+		{
+			name:  "pending",
+			query: fmt.Sprintf(`{ pending { transactionCount transactions { hash nonce } account(address: "%s") { balance } } }`, c.sender.Hex()),
+			expected: fmt.Sprintf(`{"pending":{"transactionCount":1,"transactions":[{"hash":"%s","nonce":"0x1"}],"account":{"balance":"0x64"}}}`,
+				c.pendingTx.Hash().Hex()),
+		},
+		{
+			name:     "node",
+			query:    `{ gasPrice protocolVersion syncing { currentBlock } }`,
+			expected: `{"gasPrice":"0x5","protocolVersion":64,"syncing":null}`,
+		},
+		{
+			name:     "send_raw_transaction",
+			query:    fmt.Sprintf(`mutation { sendRawTransaction(data: "%s") }`, hexutil.Encode(raw)),
+			expected: fmt.Sprintf(`{"sendRawTransaction":"%s"}`, c.pendingTx.Hash().Hex()),
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			data, err := c.query(t, test.query)
+			if test.err {
+				if err == nil {
+					t.Fatalf("query succeeded: %s", data)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("query failed: %v", err)
+			}
+			var have, want interface{}
+			if err := json.Unmarshal([]byte(data), &have); err != nil {
+				t.Fatalf("invalid result %s: %v", data, err)
+			}
+			if err := json.Unmarshal([]byte(test.expected), &want); err != nil {
+				t.Fatalf("invalid expectation %s: %v", test.expected, err)
+			}
+			if !reflect.DeepEqual(have, want) {
+				t.Errorf("result mismatch:\nhave %s\nwant %s", data, test.expected)
+			}
+		})
+	}
+	if len(c.backend.sent) != 1 || c.backend.sent[0].Hash() != c.pendingTx.Hash() {
+		t.Errorf("raw transaction not sent: %v", c.backend.sent)
+	}
+}
@@ -33,8 +33,13 @@ import (
 	"github.com/ethereum/go-ethereum/common/math"
 	"github.com/ethereum/go-ethereum/consensus/ethash"
 	"github.com/ethereum/go-ethereum/contract_comm/blockchain_parameters"
+	"github.com/ethereum/go-ethereum/contract_comm/currency"
+	"github.com/ethereum/go-ethereum/contract_comm/election"
+	"github.com/ethereum/go-ethereum/contract_comm/gasprice_minimum"
+	"github.com/ethereum/go-ethereum/contract_comm/validators"
 	"github.com/ethereum/go-ethereum/core"
 	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/state"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/core/vm"
 	"github.com/ethereum/go-ethereum/crypto"
@@ -103,40 +108,150 @@ func NewPublicTxPoolAPI(b Backend) *PublicTxPoolAPI {
 	return &PublicTxPoolAPI{b}
 }
 
-// Content returns the transactions contained within the transaction pool.
-func (s *PublicTxPoolAPI) Content() map[string]map[string]map[string]*RPCTransaction {
-	content := map[string]map[string]map[string]*RPCTransaction{
-		"pending": make(map[string]map[string]*RPCTransaction),
-		"queued":  make(map[string]map[string]*RPCTransaction),
-	}
+// Content returns the transactions contained within the transaction pool. If
+// groupByCurrency is set, the transactions are additionally grouped by fee currency.
+func (s *PublicTxPoolAPI) Content(groupByCurrency *bool) interface{} {
 	pending, queue := s.b.TxPoolContent()
+	if groupByCurrency != nil && *groupByCurrency {
+		return map[string]map[string]map[string]map[string]*RPCTransaction{
+			"pending": flattenTxPoolContentByCurrency(pending),
+			"queued":  flattenTxPoolContentByCurrency(queue),
+		}
+	}
+	return map[string]map[string]map[string]*RPCTransaction{
+		"pending": flattenTxPoolContent(pending),
+		"queued":  flattenTxPoolContent(queue),
+	}
+}
 
-	// Flatten the pending transactions
-	for account, txs := range pending {
+// flattenTxPoolContent flattens the transactions of each account into a map keyed by nonce.
+func flattenTxPoolContent(content map[common.Address]types.Transactions) map[string]map[string]*RPCTransaction {
+	flattened := make(map[string]map[string]*RPCTransaction)
+	for account, txs := range content {
 		dump := make(map[string]*RPCTransaction)
 		for _, tx := range txs {
 			dump[fmt.Sprintf("%d", tx.Nonce())] = newRPCPendingTransaction(tx)
 		}
-		content["pending"][account.Hex()] = dump
+		flattened[account.Hex()] = dump
 	}
-	// Flatten the queued transactions
-	for account, txs := range queue {
-		dump := make(map[string]*RPCTransaction)
+	return flattened
+}
+
+// flattenTxPoolContentByCurrency flattens the transactions of each account into a map
+// keyed by nonce, grouping the accounts by the fee currency of their transactions.
+func flattenTxPoolContentByCurrency(content map[common.Address]types.Transactions) map[string]map[string]map[string]*RPCTransaction {
+	flattened := make(map[string]map[string]map[string]*RPCTransaction)
+	for account, txs := range content {
 		for _, tx := range txs {
-			dump[fmt.Sprintf("%d", tx.Nonce())] = newRPCPendingTransaction(tx)
+			key := feeCurrencyKey(tx.FeeCurrency())
+			if flattened[key] == nil {
+				flattened[key] = make(map[string]map[string]*RPCTransaction)
+			}
+			if flattened[key][account.Hex()] == nil {
+				flattened[key][account.Hex()] = make(map[string]*RPCTransaction)
+			}
+			flattened[key][account.Hex()][fmt.Sprintf("%d", tx.Nonce())] = newRPCPendingTransaction(tx)
 		}
-		content["queued"][account.Hex()] = dump
 	}
-	return content
+	return flattened
+}
+
+// feeCurrencyKey returns the key used to group transactions paying fees in the given currency.
+func feeCurrencyKey(feeCurrency *common.Address) string {
+	if feeCurrency == nil {
+		return "gold"
+	}
+	return feeCurrency.Hex()
+}
+
+// RPCFeeCurrencyStatus is the status of the pool transactions paying fees in a single currency.
+// Gas prices and gateway fees are denominated in the fee currency, while the gold
+// equivalent prices are only set if the exchange rate of the currency is available.
+type RPCFeeCurrencyStatus struct {
+	Pending           hexutil.Uint `json:"pending"`
+	Queued            hexutil.Uint `json:"queued"`
+	MinGasPrice       *hexutil.Big `json:"minGasPrice"`
+	MaxGasPrice       *hexutil.Big `json:"maxGasPrice"`
+	MinGasPriceInGold *hexutil.Big `json:"minGasPriceInGold"`
+	MaxGasPriceInGold *hexutil.Big `json:"maxGasPriceInGold"`
+	GatewayFees       *hexutil.Big `json:"gatewayFees"`
 }
 
-// Status returns the number of pending and queued transaction in the pool.
-func (s *PublicTxPoolAPI) Status() map[string]hexutil.Uint {
+// Status returns the number of pending and queued transaction in the pool. If
+// groupByCurrency is set, the status of each fee currency is included as well.
+func (s *PublicTxPoolAPI) Status(groupByCurrency *bool) map[string]interface{} {
 	pending, queue := s.b.Stats()
-	return map[string]hexutil.Uint{
+	status := map[string]interface{}{
 		"pending": hexutil.Uint(pending),
 		"queued":  hexutil.Uint(queue),
 	}
+	if groupByCurrency != nil && *groupByCurrency {
+		status["feeCurrencies"] = s.feeCurrencyStatus()
+	}
+	return status
+}
+
+// feeCurrencyStatus groups the pool transactions by fee currency and collects their
+// gas price ranges and gateway fee totals.
+func (s *PublicTxPoolAPI) feeCurrencyStatus() map[string]*RPCFeeCurrencyStatus {
+	var (
+		pending, queue = s.b.TxPoolContent()
+		statuses       = make(map[string]*RPCFeeCurrencyStatus)
+		currencies     = make(map[string]*common.Address)
+	)
+	collect := func(content map[common.Address]types.Transactions, queued bool) {
+		for _, txs := range content {
+			for _, tx := range txs {
+				key := feeCurrencyKey(tx.FeeCurrency())
+				status := statuses[key]
+				if status == nil {
+					status = &RPCFeeCurrencyStatus{
+						MinGasPrice: (*hexutil.Big)(tx.GasPrice()),
+						MaxGasPrice: (*hexutil.Big)(tx.GasPrice()),
+						GatewayFees: (*hexutil.Big)(new(big.Int)),
+					}
+					statuses[key], currencies[key] = status, tx.FeeCurrency()
+				}
+				if queued {
+					status.Queued++
+				} else {
+					status.Pending++
+				}
+				if tx.GasPrice().Cmp(status.MinGasPrice.ToInt()) < 0 {
+					status.MinGasPrice = (*hexutil.Big)(tx.GasPrice())
+				}
+				if tx.GasPrice().Cmp(status.MaxGasPrice.ToInt()) > 0 {
+					status.MaxGasPrice = (*hexutil.Big)(tx.GasPrice())
+				}
+				if tx.GatewayFee() != nil {
+					status.GatewayFees.ToInt().Add(status.GatewayFees.ToInt(), tx.GatewayFee())
+				}
+			}
+		}
+	}
+	collect(pending, false)
+	collect(queue, true)
+
+	// Conversion preserves ordering, so only the bounds of each range need converting,
+	// at the exchange rates of the latest block
+	state, header, err := s.b.StateAndHeaderByNumber(context.Background(), rpc.LatestBlockNumber)
+	for key, status := range statuses {
+		feeCurrency := currencies[key]
+		if feeCurrency == nil {
+			status.MinGasPriceInGold, status.MaxGasPriceInGold = status.MinGasPrice, status.MaxGasPrice
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		if min, err := currency.Convert(status.MinGasPrice.ToInt(), feeCurrency, nil, header, state); err == nil {
+			status.MinGasPriceInGold = (*hexutil.Big)(min)
+		}
+		if max, err := currency.Convert(status.MaxGasPrice.ToInt(), feeCurrency, nil, header, state); err == nil {
+			status.MaxGasPriceInGold = (*hexutil.Big)(max)
+		}
+	}
+	return statuses
 }
 
 // Inspect retrieves the content of the transaction pool and flattens it into an
@@ -174,6 +289,230 @@ func (s *PublicTxPoolAPI) Inspect() map[string]map[string]map[string]string {
 	return content
 }
 
+// maxCeloHistoryPoints is the maximum number of blocks a single history query
+// of the PublicCeloAPI may evaluate.
+const maxCeloHistoryPoints = 1000
+
+// PublicCeloAPI provides an API to access Celo protocol values, such as the gas
+// price minimum and the oracle exchange rates, as they were at a given block.
+// Querying blocks whose state has been pruned requires an archive node.
+type PublicCeloAPI struct {
+	b Backend
+}
+
+// NewPublicCeloAPI creates a new Celo protocol API.
+func NewPublicCeloAPI(b Backend) *PublicCeloAPI {
+	return &PublicCeloAPI{b}
+}
+
+// RPCExchangeRate is the oracle rate of a currency: Numerator units of the
+// currency are worth Denominator units of Celo Gold.
+type RPCExchangeRate struct {
+	Numerator   *hexutil.Big `json:"numerator"`
+	Denominator *hexutil.Big `json:"denominator"`
+}
+
+// RPCValidator is the entry of a validator in the validators contract.
+type RPCValidator struct {
+	EcdsaPublicKey hexutil.Bytes  `json:"ecdsaPublicKey"`
+	BlsPublicKey   hexutil.Bytes  `json:"blsPublicKey"`
+	Affiliation    common.Address `json:"affiliation"`
+	Score          *hexutil.Big   `json:"score"`
+}
+
+// RPCGasPriceMinimumPoint is the gas price minimum at a block of a history query.
+type RPCGasPriceMinimumPoint struct {
+	BlockNumber     hexutil.Uint64 `json:"blockNumber"`
+	Timestamp       *hexutil.Big   `json:"timestamp"`
+	GasPriceMinimum *hexutil.Big   `json:"gasPriceMinimum"`
+}
+
+// RPCExchangeRatePoint is the exchange rate at a block of a history query.
+type RPCExchangeRatePoint struct {
+	BlockNumber hexutil.Uint64 `json:"blockNumber"`
+	Timestamp   *hexutil.Big   `json:"timestamp"`
+	Numerator   *hexutil.Big   `json:"numerator"`
+	Denominator *hexutil.Big   `json:"denominator"`
+}
+
+// GetGasPriceMinimum returns the gas price minimum of the fee currency in the
+// state of the given block, or of Celo Gold if feeCurrency is nil. The value
+// enforced on the transactions of a block is the one of its parent.
+func (s *PublicCeloAPI) GetGasPriceMinimum(ctx context.Context, feeCurrency *common.Address, blockNr rpc.BlockNumber) (*hexutil.Big, error) {
+	var gasPriceMinimum *big.Int
+	err := s.b.RunWithState(ctx, blockNr, func(header *types.Header, statedb *state.StateDB) (err error) {
+		gasPriceMinimum, err = gasprice_minimum.GetGasPriceMinimum(feeCurrency, header, statedb)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return (*hexutil.Big)(gasPriceMinimum), nil
+}
+
+// GetExchangeRate returns the median oracle rate of the currency in the state
+// of the given block.
+func (s *PublicCeloAPI) GetExchangeRate(ctx context.Context, currencyAddress common.Address, blockNr rpc.BlockNumber) (*RPCExchangeRate, error) {
+	var rate *currency.ExchangeRate
+	err := s.b.RunWithState(ctx, blockNr, func(header *types.Header, statedb *state.StateDB) (err error) {
+		rate, err = exchangeRate(currencyAddress, header, statedb)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &RPCExchangeRate{
+		Numerator:   (*hexutil.Big)(rate.Numerator),
+		Denominator: (*hexutil.Big)(rate.Denominator),
+	}, nil
+}
+
+// GetElectedValidators returns the signers of the validators the election
+// contract elects in the state of the given block.
+func (s *PublicCeloAPI) GetElectedValidators(ctx context.Context, blockNr rpc.BlockNumber) ([]common.Address, error) {
+	var signers []common.Address
+	err := s.b.RunWithState(ctx, blockNr, func(header *types.Header, statedb *state.StateDB) (err error) {
+		signers, err = election.GetElectedValidators(header, statedb)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return signers, nil
+}
+
+// GetValidator returns the keys, group affiliation and score the validators
+// contract holds for the validator account in the state of the given block.
+func (s *PublicCeloAPI) GetValidator(ctx context.Context, validatorAddress common.Address, blockNr rpc.BlockNumber) (*RPCValidator, error) {
+	var validator validators.ValidatorContractData
+	err := s.b.RunWithState(ctx, blockNr, func(header *types.Header, statedb *state.StateDB) (err error) {
+		validator, err = validators.GetValidator(header, statedb, validatorAddress)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &RPCValidator{
+		EcdsaPublicKey: validator.EcdsaPublicKey,
+		BlsPublicKey:   validator.BlsPublicKey,
+		Affiliation:    validator.Affiliation,
+		Score:          (*hexutil.Big)(validator.Score),
+	}, nil
+}
+
+// GetGasPriceMinimumHistory returns the gas price minimum of the fee currency at
+// every step-th block of the range [fromBlock, toBlock]. The step defaults to 1.
+func (s *PublicCeloAPI) GetGasPriceMinimumHistory(ctx context.Context, feeCurrency *common.Address, fromBlock, toBlock rpc.BlockNumber, step *hexutil.Uint64) ([]*RPCGasPriceMinimumPoint, error) {
+	var points []*RPCGasPriceMinimumPoint
+	err := s.forEachBlock(ctx, fromBlock, toBlock, step, func(header *types.Header, statedb *state.StateDB) error {
+		gasPriceMinimum, err := gasprice_minimum.GetGasPriceMinimum(feeCurrency, header, statedb)
+		if err != nil {
+			return err
+		}
+		points = append(points, &RPCGasPriceMinimumPoint{
+			BlockNumber:     hexutil.Uint64(header.Number.Uint64()),
+			Timestamp:       (*hexutil.Big)(header.Time),
+			GasPriceMinimum: (*hexutil.Big)(gasPriceMinimum),
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return points, nil
+}
+
+// GetExchangeRateHistory returns the median oracle rate of the currency at every
+// step-th block of the range [fromBlock, toBlock]. The step defaults to 1.
+func (s *PublicCeloAPI) GetExchangeRateHistory(ctx context.Context, currencyAddress common.Address, fromBlock, toBlock rpc.BlockNumber, step *hexutil.Uint64) ([]*RPCExchangeRatePoint, error) {
+	var points []*RPCExchangeRatePoint
+	err := s.forEachBlock(ctx, fromBlock, toBlock, step, func(header *types.Header, statedb *state.StateDB) error {
+		rate, err := exchangeRate(currencyAddress, header, statedb)
+		if err != nil {
+			return err
+		}
+		points = append(points, &RPCExchangeRatePoint{
+			BlockNumber: hexutil.Uint64(header.Number.Uint64()),
+			Timestamp:   (*hexutil.Big)(header.Time),
+			Numerator:   (*hexutil.Big)(rate.Numerator),
+			Denominator: (*hexutil.Big)(rate.Denominator),
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return points, nil
+}
+
+// exchangeRate returns the median oracle rate of a whitelisted fee currency in the
+// given state. Rates with a zero denominator, which the oracle reports for currencies
+// without any rates, are rejected.
+func exchangeRate(currencyAddress common.Address, header *types.Header, statedb *state.StateDB) (*currency.ExchangeRate, error) {
+	if !currency.IsWhitelisted(currencyAddress, header, statedb) {
+		return nil, fmt.Errorf("currency %s is not whitelisted", currencyAddress.Hex())
+	}
+	rate, err := currency.GetExchangeRate(&currencyAddress, header, statedb)
+	if err != nil {
+		return nil, err
+	}
+	if rate.Denominator.Sign() == 0 {
+		return nil, fmt.Errorf("no exchange rate reported for currency %s", currencyAddress.Hex())
+	}
+	return rate, nil
+}
+
+// forEachBlock calls fn with the header and state of every step-th block of the
+// range [fromBlock, toBlock].
+func (s *PublicCeloAPI) forEachBlock(ctx context.Context, fromBlock, toBlock rpc.BlockNumber, step *hexutil.Uint64, fn func(*types.Header, *state.StateDB) error) error {
+	from, err := s.resolveBlockNumber(ctx, fromBlock)
+	if err != nil {
+		return err
+	}
+	to, err := s.resolveBlockNumber(ctx, toBlock)
+	if err != nil {
+		return err
+	}
+	if from > to {
+		return fmt.Errorf("fromBlock #%d is after toBlock #%d", from, to)
+	}
+	interval := uint64(1)
+	if step != nil {
+		if *step == 0 {
+			return errors.New("step must be positive")
+		}
+		interval = uint64(*step)
+	}
+	if points := (to-from)/interval + 1; points > maxCeloHistoryPoints {
+		return fmt.Errorf("range spans %d points, exceeding the maximum of %d", points, maxCeloHistoryPoints)
+	}
+	for number := from; number <= to; number += interval {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := s.b.RunWithState(ctx, rpc.BlockNumber(number), fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// resolveBlockNumber resolves the latest and earliest block number tags to the
+// number of a canonical block. The pending block is not supported.
+func (s *PublicCeloAPI) resolveBlockNumber(ctx context.Context, blockNr rpc.BlockNumber) (uint64, error) {
+	if blockNr == rpc.PendingBlockNumber {
+		return 0, errors.New("pending block is not supported in history queries")
+	}
+	header, err := s.b.HeaderByNumber(ctx, blockNr)
+	if err != nil {
+		return 0, err
+	}
+	if header == nil {
+		return 0, fmt.Errorf("block #%d not found", blockNr)
+	}
+	return header.Number.Uint64(), nil
+}
+
 // PublicAccountAPI provides an API to access accounts managed by this node.
 // It offers only methods that can retrieve accounts.
 type PublicAccountAPI struct {
@@ -691,24 +1030,32 @@ func (s *PublicBlockChainAPI) doCall(ctx context.Context, args CallArgs, blockNr
 		return nil, 0, false, err
 	}
 	// Set sender address or use a default if none specified
-	addr := args.From
-	if addr == (common.Address{}) {
-		if wallets := s.b.AccountManager().Wallets(); len(wallets) > 0 {
-			if accounts := wallets[0].Accounts(); len(accounts) > 0 {
-				addr = accounts[0].Address
-			}
-		}
-	}
+	addr := s.callSender(args)
+
 	// Set default gas & gas price if none were set
 	gas, gasPrice := uint64(args.Gas), args.GasPrice.ToInt()
-	if gas == 0 {
-		gas = math.MaxUint64 / 2
-	}
 	// Checking against 0 is a hack to allow users to bypass the default gas price being set by web3,
 	// which will always be in Gold. This allows the default price to be set for the proper currency.
 	// TODO(asa): Remove this once this is handled in the Provider.
 	if gasPrice.Sign() == 0 || gasPrice.Cmp(big.NewInt(0)) == 0 {
 		gasPrice, err = s.b.SuggestPriceInCurrency(ctx, args.FeeCurrency)
+		if err != nil {
+			return nil, 0, false, err
+		}
+	}
+	if gas == 0 {
+		gas = math.MaxUint64 / 2
+		// The fees of calls paying in a fee currency are debited from the sender's
+		// token balance, so cap the default gas to what the sender can afford.
+		if args.FeeCurrency != nil {
+			allowance, err := feeCurrencyAllowance(addr, args.FeeCurrency, gasPrice, args.GatewayFeeRecipient, args.GatewayFee.ToInt(), header, state)
+			if err != nil {
+				return nil, 0, false, err
+			}
+			if gas > allowance {
+				gas = allowance
+			}
+		}
 	}
 
 	// Create new call message
@@ -749,6 +1096,45 @@ func (s *PublicBlockChainAPI) doCall(ctx context.Context, args CallArgs, blockNr
 	return res, gas, failed, err
 }
 
+// callSender returns the sender of a call, defaulting to the first account of
+// the first wallet if none was specified.
+func (s *PublicBlockChainAPI) callSender(args CallArgs) common.Address {
+	if args.From != (common.Address{}) {
+		return args.From
+	}
+	if wallets := s.b.AccountManager().Wallets(); len(wallets) > 0 {
+		if accounts := wallets[0].Accounts(); len(accounts) > 0 {
+			return accounts[0].Address
+		}
+	}
+	return args.From
+}
+
+// feeCurrencyAllowance returns the maximum gas the sender can pay for at the given
+// gas price with its balance in the fee currency, after paying the gateway fee.
+func feeCurrencyAllowance(from common.Address, feeCurrency *common.Address, gasPrice *big.Int, gatewayFeeRecipient *common.Address, gatewayFee *big.Int, header *types.Header, state *state.StateDB) (uint64, error) {
+	balance, _, err := currency.GetBalanceOf(from, *feeCurrency, params.MaxGasToReadErc20Balance, header, state)
+	if err != nil {
+		return 0, fmt.Errorf("failed to retrieve balance in fee currency %s: %v", feeCurrency.Hex(), err)
+	}
+	// The state transition requires the balance to strictly exceed the fees.
+	available := new(big.Int).Sub(balance, common.Big1)
+	if gatewayFeeRecipient != nil {
+		available.Sub(available, gatewayFee)
+	}
+	if available.Sign() <= 0 {
+		return 0, fmt.Errorf("insufficient balance in fee currency %s to pay for gas", feeCurrency.Hex())
+	}
+	if gasPrice.Sign() == 0 {
+		return math.MaxUint64 / 2, nil
+	}
+	allowance := available.Div(available, gasPrice)
+	if !allowance.IsUint64() || allowance.Uint64() > math.MaxUint64/2 {
+		return math.MaxUint64 / 2, nil
+	}
+	return allowance.Uint64(), nil
+}
+
 // Call executes the given transaction on the state for the given block number.
 // It doesn't make and changes in the state/blockchain and is useful to execute and retrieve values.
 func (s *PublicBlockChainAPI) Call(ctx context.Context, args CallArgs, blockNr rpc.BlockNumber) (hexutil.Bytes, error) {
@@ -757,7 +1143,9 @@ func (s *PublicBlockChainAPI) Call(ctx context.Context, args CallArgs, blockNr r
 }
 
 // EstimateGas returns an estimate of the amount of gas needed to execute the
-// given transaction against the current pending block.
+// given transaction against the current pending block. If a fee currency is
+// given, the estimate includes the intrinsic gas of paying fees in it, and the
+// allowance is capped by the sender's balance in that currency.
 func (s *PublicBlockChainAPI) EstimateGas(ctx context.Context, args CallArgs) (hexutil.Uint64, error) {
 	// Binary search the gas requirement, as it may be higher than the amount used
 	var (
@@ -775,13 +1163,38 @@ func (s *PublicBlockChainAPI) EstimateGas(ctx context.Context, args CallArgs) (h
 		}
 		hi = block.GasLimit()
 	}
+	// Fix the sender and gas price up front, so that every execution of the search pays the same fees
+	args.From = s.callSender(args)
+	if args.GasPrice.ToInt().Sign() == 0 {
+		gasPrice, err := s.b.SuggestPriceInCurrency(ctx, args.FeeCurrency)
+		if err != nil {
+			return 0, err
+		}
+		args.GasPrice = hexutil.Big(*gasPrice)
+	}
+	// Recap the highest gas allowance with the sender's balance in the fee currency
+	if args.FeeCurrency != nil {
+		state, header, err := s.b.StateAndHeaderByNumber(ctx, rpc.PendingBlockNumber)
+		if state == nil || err != nil {
+			return 0, err
+		}
+		allowance, err := feeCurrencyAllowance(args.From, args.FeeCurrency, args.GasPrice.ToInt(), args.GatewayFeeRecipient, args.GatewayFee.ToInt(), header, state)
+		if err != nil {
+			return 0, err
+		}
+		if hi > allowance {
+			hi = allowance
+		}
+	}
 	cap = hi
 
 	// Create a helper to check if a gas allowance results in an executable transaction
+	var callErr error
 	executable := func(gas uint64) bool {
 		args.Gas = hexutil.Uint64(gas)
 
 		_, _, failed, err := s.doCall(ctx, args, rpc.PendingBlockNumber, 0)
+		callErr = err
 		if err != nil || failed {
 			return false
 		}
@@ -799,6 +1212,9 @@ func (s *PublicBlockChainAPI) EstimateGas(ctx context.Context, args CallArgs) (h
 	// Reject the transaction as invalid if it still fails at the highest allowance
 	if hi == cap {
 		if !executable(hi) {
+			if callErr != nil && callErr != vm.ErrOutOfGas {
+				return 0, callErr
+			}
 			return 0, fmt.Errorf("gas required exceeds allowance or always failing transaction")
 		}
 	}
@@ -1183,6 +1599,10 @@ func (s *PublicTransactionPoolAPI) GetTransactionReceipt(ctx context.Context, ha
 	if receipt.ContractAddress != (common.Address{}) {
 		fields["contractAddress"] = receipt.ContractAddress
 	}
+	// Receipts stored before the fees were recorded don't carry them
+	if receipt.Fees != nil {
+		fields["fees"] = receipt.Fees
+	}
 	return fields, nil
 }
 
@@ -1283,7 +1703,7 @@ func (args *SendTxArgs) setDefaults(ctx context.Context, b Backend) error {
 	}
 
 	if args.GatewayFeeRecipient != nil && args.GatewayFee == nil {
-		args.GatewayFee = (*hexutil.Big)(b.GatewayFee())
+		args.GatewayFee = (*hexutil.Big)(b.GatewayFee(*args.GatewayFeeRecipient))
 	}
 	return nil
 }
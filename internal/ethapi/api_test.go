@@ -0,0 +1,238 @@
+// Copyright 2019 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package ethapi
+
+import (
+	"context"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/consensus/ethash"
+	"github.com/ethereum/go-ethereum/contract_comm"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+var (
+	testFeeCurrency = common.HexToAddress("0xfee")
+	testSender      = common.HexToAddress("0x5e4de4")
+	testRecipient   = common.HexToAddress("0x4ec1")
+	testGasPrice    = big.NewInt(10)
+
+	// testFeeCurrencyCode is a stub ERC20 returning the value of storage slot 0
+	// to every call, so balanceOf reports it as the sender's balance and the
+	// fee debits and credits succeed without changing it.
+	testFeeCurrencyCode = []byte{
+		byte(vm.PUSH1), 0, byte(vm.SLOAD), byte(vm.PUSH1), 0, byte(vm.MSTORE),
+		byte(vm.PUSH1), 32, byte(vm.PUSH1), 0, byte(vm.RETURN),
+	}
+)
+
+// testBackend serves the calls of the blockchain API from the head of a local
+// chain. The methods the tests don't use are left to the nil embedded Backend.
+type testBackend struct {
+	Backend
+	chain *core.BlockChain
+}
+
+// newTestBackend creates a chain whose fee currency reports the given balance
+// for the sender. No registry is deployed, so the fee currency is whitelisted
+// and the intrinsic gas and gas price minimum take their default values.
+func newTestBackend(t *testing.T, balance *big.Int) *testBackend {
+	return newTestBackendWithAlloc(t, core.GenesisAlloc{
+		testFeeCurrency: {
+			Code:    testFeeCurrencyCode,
+			Storage: map[common.Hash]common.Hash{{}: common.BigToHash(balance)},
+			Balance: new(big.Int),
+		},
+	})
+}
+
+// newTestBackendWithAlloc creates a chain with the given genesis accounts.
+func newTestBackendWithAlloc(t *testing.T, alloc core.GenesisAlloc) *testBackend {
+	db := ethdb.NewMemDatabase()
+	gspec := &core.Genesis{
+		Config:   params.TestChainConfig,
+		GasLimit: 20000000,
+		Alloc:    alloc,
+	}
+	gspec.MustCommit(db)
+
+	chain, err := core.NewBlockChain(db, nil, gspec.Config, ethash.NewFaker(), vm.Config{}, nil)
+	if err != nil {
+		t.Fatalf("failed to create chain: %v", err)
+	}
+	contract_comm.SetInternalEVMHandler(chain)
+	return &testBackend{chain: chain}
+}
+
+func (b *testBackend) StateAndHeaderByNumber(ctx context.Context, blockNr rpc.BlockNumber) (*state.StateDB, *types.Header, error) {
+	header := b.chain.CurrentHeader()
+	statedb, err := b.chain.StateAt(header.Root)
+	return statedb, header, err
+}
+
+func (b *testBackend) HeaderByNumber(ctx context.Context, blockNr rpc.BlockNumber) (*types.Header, error) {
+	return b.chain.CurrentHeader(), nil
+}
+
+func (b *testBackend) RunWithState(ctx context.Context, blockNr rpc.BlockNumber, fn func(*types.Header, *state.StateDB) error) error {
+	statedb, header, err := b.StateAndHeaderByNumber(ctx, blockNr)
+	if err != nil {
+		return err
+	}
+	return fn(header, statedb)
+}
+
+func (b *testBackend) BlockByNumber(ctx context.Context, blockNr rpc.BlockNumber) (*types.Block, error) {
+	return b.chain.CurrentBlock(), nil
+}
+
+func (b *testBackend) SuggestPriceInCurrency(ctx context.Context, currencyAddress *common.Address) (*big.Int, error) {
+	return testGasPrice, nil
+}
+
+func (b *testBackend) GetEVM(ctx context.Context, msg core.Message, header *types.Header, state *state.StateDB) (*vm.EVM, func() error, error) {
+	state.SetBalance(msg.From(), math.MaxBig256)
+	context := core.NewEVMContext(msg, header, b.chain, nil)
+	return vm.NewEVM(context, state, b.chain.Config(), vm.Config{}), func() error { return nil }, nil
+}
+
+// Tests that calls and estimates paying fees in a fee currency charge its intrinsic
+// gas and are limited by the sender's balance in it.
+func TestFeeCurrencyCallAndEstimateGas(t *testing.T) {
+	intrinsicGas := params.TxGas + params.IntrinsicGasForAlternativeFeeCurrency
+	enough := new(big.Int).Mul(big.NewInt(int64(2*intrinsicGas)), testGasPrice)
+	tooLittle := new(big.Int).Mul(big.NewInt(int64(intrinsicGas/2)), testGasPrice)
+
+	tests := []struct {
+		name        string
+		balance     *big.Int
+		gas         uint64 // gas passed to the call, 0 for the default
+		callErr     string // expected eth_call error, empty if the call succeeds
+		estimateErr string // expected eth_estimateGas error, empty if the estimate succeeds
+	}{
+		{"enough_balance", enough, 0, "", ""},
+		{"enough_balance_with_gas", enough, intrinsicGas, "", ""},
+		// The default gas is capped to what the balance pays for, which is below the intrinsic gas
+		{"too_little_balance", tooLittle, 0, "out of gas", "gas required exceeds allowance"},
+		{"too_little_balance_with_gas", tooLittle, intrinsicGas, "insufficient balance to pay for fees", "gas required exceeds allowance"},
+		{"no_balance", common.Big0, 0, "insufficient balance in fee currency", "insufficient balance in fee currency"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			api := NewPublicBlockChainAPI(newTestBackend(t, test.balance))
+			feeCurrency := testFeeCurrency
+			args := CallArgs{
+				From:        testSender,
+				To:          &testRecipient,
+				Gas:         hexutil.Uint64(test.gas),
+				FeeCurrency: &feeCurrency,
+			}
+
+			_, err := api.Call(context.Background(), args, rpc.LatestBlockNumber)
+			checkError(t, "eth_call", err, test.callErr)
+
+			gas, err := api.EstimateGas(context.Background(), args)
+			checkError(t, "eth_estimateGas", err, test.estimateErr)
+			if err == nil && uint64(gas) != intrinsicGas {
+				t.Errorf("eth_estimateGas mismatch: have %d, want %d", gas, intrinsicGas)
+			}
+		})
+	}
+}
+
+func checkError(t *testing.T, method string, err error, want string) {
+	switch {
+	case want == "" && err != nil:
+		t.Errorf("%s failed: %v", method, err)
+	case want != "" && err == nil:
+		t.Errorf("%s succeeded, want error %q", method, want)
+	case want != "" && !strings.Contains(err.Error(), want):
+		t.Errorf("%s error mismatch: have %q, want %q", method, err, want)
+	}
+}
+
+// testOraclesCode is a stub standing in for both the FeeCurrencyWhitelist and the
+// SortedOracles contracts. Calls without arguments are answered like getWhitelist,
+// returning the test fee currency, and others like medianRate, returning storage
+// slots 0 and 1 as the numerator and denominator.
+var testOraclesCode = append(append([]byte{
+	byte(vm.CALLDATASIZE), byte(vm.PUSH1), 4, byte(vm.EQ), byte(vm.PUSH1), 24, byte(vm.JUMPI),
+	byte(vm.PUSH1), 0, byte(vm.SLOAD), byte(vm.PUSH1), 0, byte(vm.MSTORE),
+	byte(vm.PUSH1), 1, byte(vm.SLOAD), byte(vm.PUSH1), 32, byte(vm.MSTORE),
+	byte(vm.PUSH1), 64, byte(vm.PUSH1), 0, byte(vm.RETURN),
+	byte(vm.JUMPDEST), byte(vm.PUSH1), 32, byte(vm.PUSH1), 0, byte(vm.MSTORE),
+	byte(vm.PUSH1), 1, byte(vm.PUSH1), 32, byte(vm.MSTORE),
+	byte(vm.PUSH20)}, testFeeCurrency.Bytes()...),
+	byte(vm.PUSH1), 64, byte(vm.MSTORE), byte(vm.PUSH1), 96, byte(vm.PUSH1), 0, byte(vm.RETURN),
+)
+
+// Tests that exchange rates are only reported for whitelisted currencies that have a rate.
+func TestGetExchangeRate(t *testing.T) {
+	oracles := common.HexToAddress("0x0ac1e5")
+	// getAddressFor: return the oracles address for every contract
+	registryCode := append(append([]byte{byte(vm.PUSH20)}, oracles.Bytes()...),
+		byte(vm.PUSH1), 0, byte(vm.MSTORE), byte(vm.PUSH1), 32, byte(vm.PUSH1), 0, byte(vm.RETURN))
+
+	tests := []struct {
+		name     string
+		currency common.Address
+		rate     [2]int64
+		err      string
+	}{
+		{name: "valid", currency: testFeeCurrency, rate: [2]int64{3, 2}},
+		{name: "not_whitelisted", currency: testRecipient, rate: [2]int64{3, 2}, err: "not whitelisted"},
+		{name: "zero_denominator", currency: testFeeCurrency, rate: [2]int64{0, 0}, err: "no exchange rate"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			api := NewPublicCeloAPI(newTestBackendWithAlloc(t, core.GenesisAlloc{
+				params.RegistrySmartContractAddress: {Code: registryCode, Balance: new(big.Int)},
+				oracles: {
+					Code: testOraclesCode,
+					Storage: map[common.Hash]common.Hash{
+						common.BigToHash(common.Big0): common.BigToHash(big.NewInt(test.rate[0])),
+						common.BigToHash(common.Big1): common.BigToHash(big.NewInt(test.rate[1])),
+					},
+					Balance: new(big.Int),
+				},
+			}))
+
+			rate, err := api.GetExchangeRate(context.Background(), test.currency, rpc.LatestBlockNumber)
+			checkError(t, "celo_getExchangeRate", err, test.err)
+			if err == nil && (rate.Numerator.ToInt().Int64() != test.rate[0] || rate.Denominator.ToInt().Int64() != test.rate[1]) {
+				t.Errorf("celo_getExchangeRate mismatch: have %v/%v, want %d/%d", rate.Numerator, rate.Denominator, test.rate[0], test.rate[1])
+			}
+
+			points, err := api.GetExchangeRateHistory(context.Background(), test.currency, rpc.LatestBlockNumber, rpc.LatestBlockNumber, nil)
+			checkError(t, "celo_getExchangeRateHistory", err, test.err)
+			if err == nil && (len(points) != 1 || points[0].Denominator.ToInt().Int64() != test.rate[1]) {
+				t.Errorf("celo_getExchangeRateHistory mismatch: have %v", points)
+			}
+		})
+	}
+}
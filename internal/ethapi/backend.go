@@ -51,6 +51,9 @@ type Backend interface {
 	HeaderByNumber(ctx context.Context, blockNr rpc.BlockNumber) (*types.Header, error)
 	BlockByNumber(ctx context.Context, blockNr rpc.BlockNumber) (*types.Block, error)
 	StateAndHeaderByNumber(ctx context.Context, blockNr rpc.BlockNumber) (*state.StateDB, *types.Header, error)
+	// RunWithState calls fn with the header and state of the given block. Light
+	// clients retrieve the state entries fn reads along with their proofs.
+	RunWithState(ctx context.Context, blockNr rpc.BlockNumber, fn func(*types.Header, *state.StateDB) error) error
 	GetBlock(ctx context.Context, blockHash common.Hash) (*types.Block, error)
 	GetReceipts(ctx context.Context, blockHash common.Hash) (types.Receipts, error)
 	GetTd(blockHash common.Hash) *big.Int
@@ -72,7 +75,8 @@ type Backend interface {
 	CurrentBlock() *types.Block
 
 	GatewayFeeRecipient() common.Address
-	GatewayFee() *big.Int
+	// GatewayFee returns the minimum gateway fee the given recipient accepts.
+	GatewayFee(recipient common.Address) *big.Int
 }
 
 func GetAPIs(apiBackend Backend) []rpc.API {
@@ -98,6 +102,11 @@ func GetAPIs(apiBackend Backend) []rpc.API {
 			Version:   "1.0",
 			Service:   NewPublicTxPoolAPI(apiBackend),
 			Public:    true,
+		}, {
+			Namespace: "celo",
+			Version:   "1.0",
+			Service:   NewPublicCeloAPI(apiBackend),
+			Public:    true,
 		}, {
 			Namespace: "debug",
 			Version:   "1.0",
@@ -33,6 +33,8 @@ var Modules = map[string]string{
 	"swarmfs":    SWARMFS_JS,
 	"txpool":     TxPool_JS,
 	"istanbul":   Istanbul_JS,
+	"celo":       Celo_JS,
+	"les":        LES_JS,
 }
 
 const Chequebook_JS = `
@@ -543,6 +545,12 @@ web3._extend({
 			params: 1,
 			inputFormatter: [web3._extend.formatters.inputAddressFormatter]
 		}),
+		new web3._extend.Method({
+			name: 'rotateSigner',
+			call: 'miner_rotateSigner',
+			params: 1,
+			inputFormatter: [web3._extend.formatters.inputAddressFormatter]
+		}),
 		new web3._extend.Method({
 			name: 'setExtra',
 			call: 'miner_setExtra',
@@ -687,7 +695,19 @@ web3._extend({
 const TxPool_JS = `
 web3._extend({
 	property: 'txpool',
-	methods: [],
+	methods:
+	[
+		new web3._extend.Method({
+			name: 'getContent',
+			call: 'txpool_content',
+			params: 1
+		}),
+		new web3._extend.Method({
+			name: 'getStatus',
+			call: 'txpool_status',
+			params: 1
+		}),
+	],
 	properties:
 	[
 		new web3._extend.Property({
@@ -782,6 +802,50 @@ web3._extend({
 			call: 'istanbul_getValidatorsAtHash',
 			params: 1
 		}),
+		new web3._extend.Method({
+			name: 'getRoundTimeline',
+			call: 'istanbul_getRoundTimeline',
+			params: 1,
+			inputFormatter: [null]
+		}),
+		new web3._extend.Method({
+			name: 'getNextEpochValidators',
+			call: 'istanbul_getNextEpochValidators',
+			params: 1,
+			inputFormatter: [null]
+		}),
+		new web3._extend.Method({
+			name: 'getSigners',
+			call: 'istanbul_getSigners',
+			params: 0
+		}),
+		new web3._extend.Method({
+			name: 'startValidating',
+			call: 'istanbul_startValidating',
+			params: 0
+		}),
+		new web3._extend.Method({
+			name: 'stopValidating',
+			call: 'istanbul_stopValidating',
+			params: 0
+		}),
+		new web3._extend.Method({
+			name: 'startValidatingAtBlock',
+			call: 'istanbul_startValidatingAtBlock',
+			params: 1,
+			inputFormatter: [web3._extend.utils.fromDecimal]
+		}),
+		new web3._extend.Method({
+			name: 'stopValidatingAtBlock',
+			call: 'istanbul_stopValidatingAtBlock',
+			params: 1,
+			inputFormatter: [web3._extend.utils.fromDecimal]
+		}),
+		new web3._extend.Method({
+			name: 'requestAnnounce',
+			call: 'istanbul_requestAnnounce',
+			params: 1
+		}),
 		new web3._extend.Method({
 			name: 'addSentry',
 			call: 'istanbul_addSentry',
@@ -795,6 +859,10 @@ web3._extend({
 		new web3._extend.Property({
 			name: 'sentryInfo',
 			getter: 'istanbul_sentryInfo'
+		}),
+		new web3._extend.Property({
+			name: 'replicaState',
+			getter: 'istanbul_replicaState'
 		}),		
 	],
 	properties:
@@ -806,3 +874,63 @@ web3._extend({
 	]
 });
 `
+
+const Celo_JS = `
+web3._extend({
+	property: 'celo',
+	methods: [
+		new web3._extend.Method({
+			name: 'getGasPriceMinimum',
+			call: 'celo_getGasPriceMinimum',
+			params: 2,
+			inputFormatter: [null, web3._extend.formatters.inputDefaultBlockNumberFormatter],
+			outputFormatter: web3._extend.utils.toBigNumber
+		}),
+		new web3._extend.Method({
+			name: 'getGasPriceMinimumHistory',
+			call: 'celo_getGasPriceMinimumHistory',
+			params: 4,
+			inputFormatter: [null, web3._extend.formatters.inputBlockNumberFormatter, web3._extend.formatters.inputBlockNumberFormatter, null]
+		}),
+		new web3._extend.Method({
+			name: 'getExchangeRate',
+			call: 'celo_getExchangeRate',
+			params: 2,
+			inputFormatter: [web3._extend.formatters.inputAddressFormatter, web3._extend.formatters.inputDefaultBlockNumberFormatter]
+		}),
+		new web3._extend.Method({
+			name: 'getExchangeRateHistory',
+			call: 'celo_getExchangeRateHistory',
+			params: 4,
+			inputFormatter: [web3._extend.formatters.inputAddressFormatter, web3._extend.formatters.inputBlockNumberFormatter, web3._extend.formatters.inputBlockNumberFormatter, null]
+		}),
+	]
+});
+`
+
+const LES_JS = `
+web3._extend({
+	property: 'les',
+	methods:
+	[
+		new web3._extend.Method({
+			name: 'setClientCapacity',
+			call: 'les_setClientCapacity',
+			params: 3,
+			inputFormatter: [null, web3._extend.utils.fromDecimal, web3._extend.utils.fromDecimal]
+		}),
+		new web3._extend.Method({
+			name: 'removeClientCapacity',
+			call: 'les_removeClientCapacity',
+			params: 1
+		}),
+	],
+	properties:
+	[
+		new web3._extend.Property({
+			name: 'clients',
+			getter: 'les_clients'
+		}),
+	]
+});
+`
@@ -0,0 +1,113 @@
+// Copyright 2019 The Celo Authors
+// This file is part of the celo library.
+//
+// The celo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The celo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the celo library. If not, see <http://www.gnu.org/licenses/>.
+
+package les
+
+import (
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/p2p/enode"
+)
+
+// PrivateLightServerAPI provides an API to manage the capacity and priority of
+// the clients of a light server, and to inspect their usage.
+type PrivateLightServerAPI struct {
+	server *LesServer
+}
+
+// NewPrivateLightServerAPI creates a new light server API.
+func NewPrivateLightServerAPI(server *LesServer) *PrivateLightServerAPI {
+	return &PrivateLightServerAPI{server: server}
+}
+
+// ClientInfo describes the flow control parameters of a client and its usage of
+// the server.
+type ClientInfo struct {
+	ID             enode.ID       `json:"id"`
+	Connected      bool           `json:"connected"`
+	PriorityClient bool           `json:"priorityClient"`
+	Capacity       hexutil.Uint64 `json:"capacity"`
+	Priority       hexutil.Uint64 `json:"priority"`
+	BufLimit       hexutil.Uint64 `json:"bufLimit"`
+	BufValue       hexutil.Uint64 `json:"bufValue"`
+	ServedRequests hexutil.Uint64 `json:"servedRequests"`
+	ServedCost     hexutil.Uint64 `json:"servedCost"`
+}
+
+// SetClientCapacity assigns a capacity and priority to the client with the given
+// node ID. The capacity is the minimum rate at which the client's flow control
+// buffer is recharged, the buffer limit is scaled accordingly. The priority is
+// the weight of the client's share of the server's capacity when it is busy,
+// free clients have a priority of 1. The capacities of all priority clients may
+// not exceed the server's capacity for all of its client slots at the default
+// recharge rate. A connected client is disconnected so that
+// it picks up the new parameters when it reconnects.
+func (api *PrivateLightServerAPI) SetClientCapacity(id enode.ID, capacity, priority hexutil.Uint64) error {
+	return api.server.priorityClients.set(id, uint64(capacity), uint64(priority))
+}
+
+// RemoveClientCapacity turns a priority client back into a free client. It
+// returns whether the client was a priority client.
+func (api *PrivateLightServerAPI) RemoveClientCapacity(id enode.ID) bool {
+	return api.server.priorityClients.remove(id)
+}
+
+// Clients returns the parameters and usage of the connected clients, followed by
+// the disconnected priority clients.
+func (api *PrivateLightServerAPI) Clients() []ClientInfo {
+	var (
+		infos     []ClientInfo
+		connected = make(map[enode.ID]bool)
+	)
+	for _, p := range api.server.protocolManager.peers.AllPeers() {
+		if p.fcClient == nil {
+			continue
+		}
+		infos = append(infos, api.clientInfo(p))
+		connected[p.ID()] = true
+	}
+	for _, c := range api.server.priorityClients.list() {
+		if connected[c.ID] {
+			continue
+		}
+		params := c.params(api.server.defParams)
+		infos = append(infos, ClientInfo{
+			ID:             c.ID,
+			PriorityClient: true,
+			Capacity:       hexutil.Uint64(params.MinRecharge),
+			Priority:       hexutil.Uint64(c.Priority),
+			BufLimit:       hexutil.Uint64(params.BufLimit),
+		})
+	}
+	return infos
+}
+
+// clientInfo returns the parameters and usage of a connected client.
+func (api *PrivateLightServerAPI) clientInfo(p *peer) ClientInfo {
+	_, priority := api.server.priorityClients.get(p.ID())
+	bufValue, served, cost := p.fcClient.Stats()
+
+	return ClientInfo{
+		ID:             p.ID(),
+		Connected:      true,
+		PriorityClient: priority,
+		Capacity:       hexutil.Uint64(p.fcClientParams.MinRecharge),
+		Priority:       hexutil.Uint64(p.fcClientWeight),
+		BufLimit:       hexutil.Uint64(p.fcClientParams.BufLimit),
+		BufValue:       hexutil.Uint64(bufValue),
+		ServedRequests: hexutil.Uint64(served),
+		ServedCost:     hexutil.Uint64(cost),
+	}
+}
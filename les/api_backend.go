@@ -18,6 +18,7 @@ package les
 
 import (
 	"context"
+	"fmt"
 	"math/big"
 
 	"github.com/ethereum/go-ethereum/accounts"
@@ -83,6 +84,21 @@ func (b *LesApiBackend) StateAndHeaderByNumber(ctx context.Context, blockNr rpc.
 	return light.NewState(ctx, header, b.eth.odr), header, nil
 }
 
+// RunWithState runs a contract call against the state of the given block,
+// retrieving the state entries it reads from the network along with their proofs.
+func (b *LesApiBackend) RunWithState(ctx context.Context, blockNr rpc.BlockNumber, fn func(*types.Header, *state.StateDB) error) error {
+	header, err := b.HeaderByNumber(ctx, blockNr)
+	if err != nil {
+		return err
+	}
+	if header == nil {
+		return fmt.Errorf("block #%d not found", blockNr)
+	}
+	return light.RunWithState(ctx, header, b.eth.odr, func(statedb *state.StateDB) error {
+		return fn(header, statedb)
+	})
+}
+
 func (b *LesApiBackend) GetBlock(ctx context.Context, blockHash common.Hash) (*types.Block, error) {
 	return b.eth.blockchain.GetBlockByHash(ctx, blockHash)
 }
@@ -173,15 +189,23 @@ func (b *LesApiBackend) ProtocolVersion() int {
 }
 
 func (b *LesApiBackend) SuggestPrice(ctx context.Context) (*big.Int, error) {
-	return gpm.GetGasPriceSuggestion(nil, nil, nil)
+	return b.SuggestPriceInCurrency(ctx, nil)
 }
 
-func (b *LesApiBackend) SuggestPriceInCurrency(ctx context.Context, currencyAddress *common.Address) (*big.Int, error) {
-	return gpm.GetGasPriceSuggestion(currencyAddress, nil, nil)
+func (b *LesApiBackend) SuggestPriceInCurrency(ctx context.Context, currencyAddress *common.Address) (price *big.Int, err error) {
+	err = b.RunWithState(ctx, rpc.LatestBlockNumber, func(header *types.Header, statedb *state.StateDB) (err error) {
+		price, err = gpm.GetGasPriceSuggestion(currencyAddress, header, statedb)
+		return err
+	})
+	return price, err
 }
 
-func (b *LesApiBackend) GetGasPriceMinimum(ctx context.Context, currencyAddress *common.Address) (*big.Int, error) {
-	return gpm.GetGasPriceMinimum(currencyAddress, nil, nil)
+func (b *LesApiBackend) GetGasPriceMinimum(ctx context.Context, currencyAddress *common.Address) (minimum *big.Int, err error) {
+	err = b.RunWithState(ctx, rpc.LatestBlockNumber, func(header *types.Header, statedb *state.StateDB) (err error) {
+		minimum, err = gpm.GetGasPriceMinimum(currencyAddress, header, statedb)
+		return err
+	})
+	return minimum, err
 }
 
 func (b *LesApiBackend) ChainDb() ethdb.Database {
@@ -214,7 +238,11 @@ func (b *LesApiBackend) GatewayFeeRecipient() common.Address {
 	return b.eth.GetRandomPeerEtherbase()
 }
 
-func (b *LesApiBackend) GatewayFee() *big.Int {
-	// TODO(nategraf): Create a method to fetch the gateway fee values of peers along with the coinbase.
-	return eth.DefaultConfig.GatewayFee
+// GatewayFee returns the minimum gateway fee the server with the given etherbase
+// reported, or the default one if it did not report any, as LES/2 servers don't.
+func (b *LesApiBackend) GatewayFee(recipient common.Address) *big.Int {
+	if fee := b.eth.peers.gatewayFee(recipient); fee != nil {
+		return fee
+	}
+	return new(big.Int).Set(eth.DefaultConfig.GatewayFee)
 }
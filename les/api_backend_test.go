@@ -0,0 +1,45 @@
+// Copyright 2019 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package les
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/eth"
+)
+
+// Tests that the gateway fee of a server is the one it reported, and the default
+// one if it reported none.
+func TestGatewayFee(t *testing.T) {
+	var (
+		reporting = common.HexToAddress("0x01")
+		silent    = common.HexToAddress("0x02")
+	)
+	peers := newPeerSet()
+	peers.setEtherbase(&peer{id: "les3"}, reporting, big.NewInt(25000))
+	peers.setEtherbase(&peer{id: "les2"}, silent, nil)
+
+	b := &LesApiBackend{eth: &LightEthereum{peers: peers}}
+	if fee := b.GatewayFee(reporting); fee.Cmp(big.NewInt(25000)) != 0 {
+		t.Errorf("reported gateway fee mismatch: have %v, want %v", fee, 25000)
+	}
+	if fee := b.GatewayFee(silent); fee.Cmp(eth.DefaultConfig.GatewayFee) != 0 {
+		t.Errorf("default gateway fee mismatch: have %v, want %v", fee, eth.DefaultConfig.GatewayFee)
+	}
+}
@@ -37,16 +37,25 @@ type ClientNode struct {
 	lock     sync.Mutex
 	cm       *ClientManager
 	cmNode   *cmNode
+
+	servedCount, servedCost uint64 // number and total cost of the requests served
 }
 
 func NewClientNode(cm *ClientManager, params *ServerParams) *ClientNode {
+	return NewWeightedClientNode(cm, params, 1)
+}
+
+// NewWeightedClientNode creates a client node whose share of the recharge of the
+// client manager is weighted by the given value, relative to the weight of 1 of
+// the nodes created by NewClientNode.
+func NewWeightedClientNode(cm *ClientManager, params *ServerParams, weight uint64) *ClientNode {
 	node := &ClientNode{
 		cm:       cm,
 		params:   params,
 		bufValue: params.BufLimit,
 		lastTime: mclock.Now(),
 	}
-	node.cmNode = cm.addNode(node)
+	node.cmNode = cm.addNode(node, weight)
 	return node
 }
 
@@ -89,9 +98,21 @@ func (peer *ClientNode) RequestProcessed(cost uint64) (bv, realCost uint64) {
 			peer.bufValue = bv
 		}
 	}
+	peer.servedCount++
+	peer.servedCost += cost
 	return peer.bufValue, rcost
 }
 
+// Stats returns the current buffer value of the client, along with the number
+// and the total cost of the requests served so far.
+func (peer *ClientNode) Stats() (bufValue, servedCount, servedCost uint64) {
+	peer.lock.Lock()
+	defer peer.lock.Unlock()
+
+	peer.recalcBV(mclock.Now())
+	return peer.bufValue, peer.servedCount, peer.servedCost
+}
+
 type ServerNode struct {
 	bufEstimate uint64
 	lastTime    mclock.AbsTime
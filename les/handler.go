@@ -86,6 +86,7 @@ type BlockChain interface {
 type txPool interface {
 	AddRemotes(txs []*types.Transaction) []error
 	Status(hashes []common.Hash) []core.TxStatus
+	Validate(tx *types.Transaction) error
 }
 
 type ProtocolManager struct {
@@ -279,15 +280,24 @@ func (pm *ProtocolManager) handle(p *peer) error {
 		return err
 	}
 
-	if !pm.lightSync && !p.Peer.Info().Network.Trusted {
-		// geth upstream uses the IP address for the client pool to protect against malicious clients, in here we'll just use the peer's ID which can be changed but allows us to circumvent:
-		// 1. Kubernetes internal networking putting clients on the "same IP"
-		// 2. Light clients being on the same Wifi network
-		if !pm.clientPool.connect(p.id, func() { go pm.removePeer(p.id) }) {
-			p.Log().Debug(fmt.Sprintf("Unable to connect peer to client pool"))
-			return p2p.DiscTooManyPeers
+	if !pm.lightSync {
+		// Clients with a capacity assigned by the operator are always served, the
+		// client pool only limits the anonymous ones
+		if _, ok := pm.server.priorityClients.get(p.ID()); ok {
+			priorityClientCounter.Inc(1)
+			defer priorityClientCounter.Dec(1)
+		} else if !p.Peer.Info().Network.Trusted {
+			// geth upstream uses the IP address for the client pool to protect against malicious clients, in here we'll just use the peer's ID which can be changed but allows us to circumvent:
+			// 1. Kubernetes internal networking putting clients on the "same IP"
+			// 2. Light clients being on the same Wifi network
+			if !pm.clientPool.connect(p.id, func() { go pm.removePeer(p.id) }) {
+				p.Log().Debug(fmt.Sprintf("Unable to connect peer to client pool"))
+				return p2p.DiscTooManyPeers
+			}
+			defer pm.clientPool.disconnect(p.id)
+			freeClientCounter.Inc(1)
+			defer freeClientCounter.Dec(1)
 		}
-		defer pm.clientPool.disconnect(p.id)
 	}
 
 	if rw, ok := p.rw.(*meteredMsgReadWriter); ok {
@@ -361,7 +371,7 @@ func (pm *ProtocolManager) handle(p *peer) error {
 	}
 }
 
-var reqList = []uint64{GetBlockHeadersMsg, GetBlockBodiesMsg, GetCodeMsg, GetReceiptsMsg, GetProofsV1Msg, SendTxMsg, SendTxV2Msg, GetTxStatusMsg, GetHeaderProofsMsg, GetProofsV2Msg, GetHelperTrieProofsMsg, GetEtherbaseMsg}
+var reqList = []uint64{GetBlockHeadersMsg, GetBlockBodiesMsg, GetCodeMsg, GetReceiptsMsg, GetProofsV1Msg, SendTxMsg, SendTxV2Msg, GetTxStatusMsg, GetHeaderProofsMsg, GetProofsV2Msg, GetHelperTrieProofsMsg, GetEtherbaseMsg, GetTxValidationMsg}
 
 func (pm *ProtocolManager) verifyGatewayFee(gatewayFeeRecipient *common.Address, gatewayFee *big.Int) error {
 	// If this node does not specify an etherbase, accept any GatewayFeeRecipient. Otherwise,
@@ -401,11 +411,11 @@ func (pm *ProtocolManager) handleMsg(p *peer) error {
 		}
 		bufValue, _ := p.fcClient.AcceptRequest()
 		cost := costs.baseCost + reqCnt*costs.reqCost
-		if cost > pm.server.defParams.BufLimit {
-			cost = pm.server.defParams.BufLimit
+		if cost > p.fcClientParams.BufLimit {
+			cost = p.fcClientParams.BufLimit
 		}
 		if cost > bufValue {
-			recharge := time.Duration((cost - bufValue) * 1000000 / pm.server.defParams.MinRecharge)
+			recharge := time.Duration((cost - bufValue) * 1000000 / p.fcClientParams.MinRecharge)
 			p.Log().Error("Request came too early", "recharge", common.PrettyDuration(recharge))
 			return true
 		}
@@ -1168,6 +1178,64 @@ func (pm *ProtocolManager) handleMsg(p *peer) error {
 
 		p.fcServer.GotReply(resp.ReqID, resp.BV)
 
+	case GetTxValidationMsg:
+		if pm.txpool == nil {
+			return errResp(ErrRequestRejected, "")
+		}
+		// Validate the transactions against the pool rules without adding them
+		var req struct {
+			ReqID uint64
+			Txs   []*types.Transaction
+		}
+		if err := msg.Decode(&req); err != nil {
+			return errResp(ErrDecode, "msg %v: %v", msg, err)
+		}
+		reqCnt := len(req.Txs)
+		if reject(uint64(reqCnt), MaxTxSend) {
+			return errResp(ErrRequestRejected, "")
+		}
+		hashes := make([]common.Hash, len(req.Txs))
+		for i, tx := range req.Txs {
+			hashes[i] = tx.Hash()
+		}
+		results := make([]txValidation, len(req.Txs))
+		for i, stat := range pm.txStatus(hashes) {
+			tx := req.Txs[i]
+			if stat.Status != core.TxStatusUnknown {
+				results[i] = newTxValidation(txRejectedKnown, fmt.Errorf("known transaction: %x", hashes[i]))
+				continue
+			}
+			if err := pm.verifyGatewayFee(tx.GatewayFeeRecipient(), tx.GatewayFee()); err != nil {
+				results[i] = newTxValidation(txRejectedGatewayFee, err)
+				continue
+			}
+			results[i] = newTxValidation(txRejectedOther, pm.txpool.Validate(tx))
+		}
+		bv, rcost := p.fcClient.RequestProcessed(costs.baseCost + uint64(reqCnt)*costs.reqCost)
+		pm.server.fcCostStats.update(msg.Code, uint64(reqCnt), rcost)
+
+		return p.SendTxValidation(req.ReqID, bv, results)
+
+	case TxValidationMsg:
+		if pm.odr == nil {
+			return errResp(ErrUnexpectedResponse, "")
+		}
+
+		p.Log().Trace("Received tx validation response")
+		var resp struct {
+			ReqID, BV uint64
+			Results   []txValidation
+		}
+		if err := msg.Decode(&resp); err != nil {
+			return errResp(ErrDecode, "msg %v: %v", msg, err)
+		}
+		p.fcServer.GotReply(resp.ReqID, resp.BV)
+		deliverMsg = &Msg{
+			MsgType: MsgTxValidation,
+			ReqID:   resp.ReqID,
+			Obj:     resp.Results,
+		}
+
 	case GetEtherbaseMsg:
 		p.Log().Trace("Received etherbase request")
 		// Transactions arrived, parse all of them and deliver to the pool
@@ -1179,20 +1247,26 @@ func (pm *ProtocolManager) handleMsg(p *peer) error {
 		}
 		bv, rcost := p.fcClient.RequestProcessed(costs.baseCost + costs.reqCost)
 		pm.server.fcCostStats.update(msg.Code, 1, rcost)
-		return p.SendEtherbaseRLP(req.ReqID, bv, pm.etherbase)
+		return p.SendEtherbaseRLP(req.ReqID, bv, pm.etherbase, pm.gatewayFee)
 
 	case EtherbaseMsg:
 		p.Log().Trace("Received etherbase response")
 		// TODO(asa): do we need to do anything with flow control here?
+		// LES/3 servers append the minimum gateway fee they accept.
 		var resp struct {
-			ReqID, BV uint64
-			Etherbase common.Address
+			ReqID, BV  uint64
+			Etherbase  common.Address
+			GatewayFee []*big.Int `rlp:"tail"`
 		}
 		if err := msg.Decode(&resp); err != nil {
 			return errResp(ErrDecode, "msg %v: %v", msg, err)
 		}
-		p.Log().Trace("Setting peer etherbase", "etherbase", resp.Etherbase, "Peer ID", p.ID)
-		pm.peers.setEtherbase(p, resp.Etherbase)
+		var gatewayFee *big.Int
+		if len(resp.GatewayFee) > 0 {
+			gatewayFee = resp.GatewayFee[0]
+		}
+		p.Log().Trace("Setting peer etherbase", "etherbase", resp.Etherbase, "gatewayFee", gatewayFee, "Peer ID", p.ID)
+		pm.peers.setEtherbase(p, resp.Etherbase, gatewayFee)
 
 	default:
 		p.Log().Trace("Received unknown message", "code", msg.Code)
@@ -18,6 +18,7 @@ package les
 
 import (
 	"encoding/binary"
+	"fmt"
 	"math/big"
 	"math/rand"
 	"testing"
@@ -582,6 +583,79 @@ func TestTransactionStatusLes2(t *testing.T) {
 	test(tx2, false, txStatus{Status: core.TxStatusPending})
 }
 
+func TestTransactionValidationLes3(t *testing.T) {
+	db := ethdb.NewMemDatabase()
+	pm := newTestProtocolManagerMust(t, downloader.FullSync, 0, nil, nil, nil, db)
+	chain := pm.blockchain.(*core.BlockChain)
+	config := core.DefaultTxPoolConfig
+	config.Journal = ""
+	config.PriceLimit = 1
+	txpool := core.NewTxPool(config, params.TestChainConfig, chain)
+	pm.txpool = txpool
+	peer, _ := newTestPeer(t, "peer", 3, pm, true)
+	defer peer.close()
+
+	var reqID uint64
+
+	test := func(tx *types.Transaction, exp txValidation) {
+		reqID++
+		cost := peer.GetRequestCost(GetTxValidationMsg, 1)
+		sendRequest(peer.app, GetTxValidationMsg, reqID, cost, types.Transactions{tx})
+		if err := expectResponse(peer.app, TxValidationMsg, reqID, testBufLimit, []txValidation{exp}); err != nil {
+			t.Errorf("transaction validation mismatch: %v", err)
+		}
+	}
+	signer := types.HomesteadSigner{}
+
+	// An underpriced transaction is rejected with a structured reason
+	tx0, _ := types.SignTx(types.NewTransaction(0, acc1Addr, big.NewInt(10000), params.TxGas, big.NewInt(0), nil, nil, nil, nil), signer, testBankKey)
+	test(tx0, txValidation{Code: txRejectedUnderpriced, Error: core.ErrUnderpriced.Error()})
+
+	// A valid transaction is accepted, but not added to the pool
+	tx1, _ := types.SignTx(types.NewTransaction(0, acc1Addr, big.NewInt(10000), params.TxGas, big.NewInt(100000000000), nil, nil, nil, nil), signer, testBankKey)
+	test(tx1, txValidation{Code: txAccepted})
+	if pending, queued := txpool.Stats(); pending != 0 || queued != 0 {
+		t.Fatalf("pool modified by validation: pending %d, queued %d", pending, queued)
+	}
+	// Once added, the transaction is reported as known
+	txpool.AddRemote(tx1)
+	test(tx1, txValidation{Code: txRejectedKnown, Error: fmt.Sprintf("known transaction: %x", tx1.Hash())})
+
+	// A transaction with too little gas is rejected as such
+	tx2, _ := types.SignTx(types.NewTransaction(1, acc1Addr, big.NewInt(10000), params.TxGas-1, big.NewInt(100000000000), nil, nil, nil, nil), signer, testBankKey)
+	test(tx2, txValidation{Code: txRejectedIntrinsicGas, Error: core.ErrIntrinsicGas.Error()})
+}
+
+// Tests that the etherbase is served to all peers, and the minimum gateway fee
+// only to LES/3 peers.
+func TestGetEtherbaseLes2(t *testing.T) { testGetEtherbase(t, 2) }
+func TestGetEtherbaseLes3(t *testing.T) { testGetEtherbase(t, 3) }
+
+func testGetEtherbase(t *testing.T, protocol int) {
+	db := ethdb.NewMemDatabase()
+	pm := newTestProtocolManagerMust(t, downloader.FullSync, 0, nil, nil, nil, db)
+	pm.etherbase = common.HexToAddress("2ad937cb878d8beefc84f3d0545750c2ff78cd0e")
+	pm.gatewayFee = big.NewInt(25000)
+	peer, _ := newTestPeer(t, "peer", protocol, pm, true)
+	defer peer.close()
+
+	p2p.Send(peer.app, GetEtherbaseMsg, struct{ ReqID uint64 }{42})
+	var err error
+	if protocol < lpv3 {
+		err = expectResponse(peer.app, EtherbaseMsg, 42, testBufLimit, pm.etherbase)
+	} else {
+		type resp struct {
+			ReqID, BV  uint64
+			Etherbase  common.Address
+			GatewayFee *big.Int
+		}
+		err = p2p.ExpectMsg(peer.app, EtherbaseMsg, resp{42, testBufLimit, pm.etherbase, pm.gatewayFee})
+	}
+	if err != nil {
+		t.Errorf("etherbase mismatch: %v", err)
+	}
+}
+
 func TestTransactionGatewayFeeRequirementLes2(t *testing.T) {
 	db := ethdb.NewMemDatabase()
 	pm := newTestProtocolManagerMust(t, downloader.FullSync, 0, nil, nil, nil, db)
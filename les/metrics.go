@@ -62,6 +62,9 @@ var (
 	miscInTrafficMeter  = metrics.NewRegisteredMeter("les/misc/in/traffic", nil)
 	miscOutPacketsMeter = metrics.NewRegisteredMeter("les/misc/out/packets", nil)
 	miscOutTrafficMeter = metrics.NewRegisteredMeter("les/misc/out/traffic", nil)
+
+	freeClientCounter     = metrics.NewRegisteredCounter("les/server/clients/free", nil)
+	priorityClientCounter = metrics.NewRegisteredCounter("les/server/clients/priority", nil)
 )
 
 // meteredMsgReadWriter is a wrapper around a p2p.MsgReadWriter, capable of
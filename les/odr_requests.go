@@ -25,6 +25,7 @@ import (
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/state"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/ethdb"
@@ -62,6 +63,8 @@ func LesRequest(req light.OdrRequest) LesOdrRequest {
 		return (*ReceiptsRequest)(r)
 	case *light.TrieRequest:
 		return (*TrieRequest)(r)
+	case *light.StateProofsRequest:
+		return (*StateProofsRequest)(r)
 	case *light.CodeRequest:
 		return (*CodeRequest)(r)
 	case *light.ChtRequest:
@@ -193,7 +196,7 @@ func (r *TrieRequest) GetCost(peer *peer) uint64 {
 	switch peer.version {
 	case lpv1:
 		return peer.GetRequestCost(GetProofsV1Msg, 1)
-	case lpv2:
+	case lpv2, lpv3:
 		return peer.GetRequestCost(GetProofsV2Msg, 1)
 	default:
 		panic(nil)
@@ -256,6 +259,108 @@ func (r *TrieRequest) Validate(db ethdb.Database, msg *Msg) error {
 	}
 }
 
+// ODR request type for batches of state/storage trie entries, see LesOdrRequest interface
+type StateProofsRequest light.StateProofsRequest
+
+// proofReqs returns the proof requests of the entries, preceded by the account
+// entries of the storage tries that are needed to verify the storage entries.
+func (r *StateProofsRequest) proofReqs() []ProofReq {
+	var (
+		reqs     []ProofReq
+		accounts = make(map[string]struct{})
+	)
+	for _, entry := range r.Entries {
+		if len(entry.AccKey) == 0 {
+			accounts[string(entry.Key)] = struct{}{}
+		}
+	}
+	for _, entry := range r.Entries {
+		if _, ok := accounts[string(entry.AccKey)]; len(entry.AccKey) > 0 && !ok {
+			accounts[string(entry.AccKey)] = struct{}{}
+			reqs = append(reqs, ProofReq{BHash: r.Id.BlockHash, Key: entry.AccKey})
+		}
+	}
+	for _, entry := range r.Entries {
+		reqs = append(reqs, ProofReq{BHash: r.Id.BlockHash, AccKey: entry.AccKey, Key: entry.Key})
+	}
+	return reqs
+}
+
+// GetCost returns the cost of the given ODR request according to the serving
+// peer's cost table (implementation of LesOdrRequest)
+func (r *StateProofsRequest) GetCost(peer *peer) uint64 {
+	return peer.GetRequestCost(GetProofsV2Msg, len(r.proofReqs()))
+}
+
+// CanSend tells if a certain peer is suitable for serving the given request
+func (r *StateProofsRequest) CanSend(peer *peer) bool {
+	if peer.version < lpv2 {
+		return false
+	}
+	return peer.HasBlock(r.Id.BlockHash, r.Id.BlockNumber, true)
+}
+
+// Request sends an ODR request to the LES network (implementation of LesOdrRequest)
+func (r *StateProofsRequest) Request(reqID uint64, peer *peer) error {
+	peer.Log().Debug("Requesting state proofs", "root", r.Id.Root, "count", len(r.Entries))
+	return peer.RequestProofs(reqID, r.GetCost(peer), r.proofReqs())
+}
+
+// Valid processes an ODR request reply message from the LES network
+// returns true and stores results in memory if the message was a valid reply
+// to the request (implementation of LesOdrRequest)
+func (r *StateProofsRequest) Validate(db ethdb.Database, msg *Msg) error {
+	log.Debug("Validating state proofs", "root", r.Id.Root, "count", len(r.Entries))
+
+	if msg.MsgType != MsgProofsV2 {
+		return errInvalidMessageType
+	}
+	proofs := msg.Obj.(light.NodeList)
+	nodeSet := proofs.NodeSet()
+	reads := &readTraceDB{db: nodeSet}
+
+	// Verify the account entries first, the storage entries are verified against
+	// the storage roots of the proven accounts
+	reqs := r.proofReqs()
+	roots := make(map[string]common.Hash)
+	for _, req := range reqs {
+		if len(req.AccKey) > 0 {
+			continue
+		}
+		value, _, err := trie.VerifyProof(r.Id.Root, req.Key, reads)
+		if err != nil {
+			return fmt.Errorf("merkle proof verification failed: %v", err)
+		}
+		if value == nil {
+			continue
+		}
+		var account state.Account
+		if err := rlp.DecodeBytes(value, &account); err != nil {
+			return err
+		}
+		roots[string(req.Key)] = account.Root
+	}
+	for _, req := range reqs {
+		if len(req.AccKey) == 0 {
+			continue
+		}
+		// Storage of missing accounts is empty, which is proven by the account proof
+		root, ok := roots[string(req.AccKey)]
+		if !ok {
+			continue
+		}
+		if _, _, err := trie.VerifyProof(root, req.Key, reads); err != nil {
+			return fmt.Errorf("merkle proof verification failed: %v", err)
+		}
+	}
+	// check if all nodes have been read by VerifyProof
+	if len(reads.reads) != nodeSet.KeyCount() {
+		return errUselessNodes
+	}
+	r.Proof = nodeSet
+	return nil
+}
+
 type CodeReq struct {
 	BHash  common.Hash
 	AccKey []byte
@@ -353,7 +458,7 @@ func (r *ChtRequest) GetCost(peer *peer) uint64 {
 	switch peer.version {
 	case lpv1:
 		return peer.GetRequestCost(GetHeaderProofsMsg, 1)
-	case lpv2:
+	case lpv2, lpv3:
 		return peer.GetRequestCost(GetHelperTrieProofsMsg, 1)
 	default:
 		panic(nil)
@@ -389,7 +494,7 @@ func (r *ChtRequest) Request(reqID uint64, peer *peer) error {
 		// convert HelperTrie request to old CHT request
 		reqsV1 = ChtReq{ChtNum: (req.TrieIdx + 1) * (r.Config.ChtSize / r.Config.PairChtSize), BlockNum: blockNum, FromLevel: req.FromLevel}
 		return peer.RequestHelperTrieProofs(reqID, r.GetCost(peer), []ChtReq{reqsV1})
-	case lpv2:
+	case lpv2, lpv3:
 		return peer.RequestHelperTrieProofs(reqID, r.GetCost(peer), []HelperTrieReq{req})
 	default:
 		panic(nil)
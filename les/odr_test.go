@@ -30,6 +30,7 @@ import (
 	"github.com/ethereum/go-ethereum/core/state"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/eth/downloader"
 	"github.com/ethereum/go-ethereum/ethdb"
 	"github.com/ethereum/go-ethereum/light"
@@ -160,6 +161,113 @@ func odrContractCall(ctx context.Context, db ethdb.Database, config *params.Chai
 	return res
 }
 
+func TestOdrContractCallWithStateLes2(t *testing.T) { testOdr(t, 2, 2, odrContractCallWithState) }
+
+func TestOdrContractCallWithStateLes3(t *testing.T) { testOdr(t, 3, 2, odrContractCallWithState) }
+
+func odrContractCallWithState(ctx context.Context, db ethdb.Database, config *params.ChainConfig, bc *core.BlockChain, lc *light.LightChain, bhash common.Hash) []byte {
+	if bc != nil {
+		return odrContractCall(ctx, db, config, bc, lc, bhash)
+	}
+	data := common.Hex2Bytes("60CD26850000000000000000000000000000000000000000000000000000000000000000")
+	header := lc.GetHeaderByHash(bhash)
+
+	var res []byte
+	for i := 0; i < 3; i++ {
+		data[35] = byte(i)
+
+		var ret []byte
+		err := light.RunWithState(ctx, header, lc.Odr(), func(statedb *state.StateDB) error {
+			statedb.SetBalance(testBankAddress, math.MaxBig256)
+			msg := callmsg{types.NewMessage(testBankAddress, &testContractAddr, 0, new(big.Int), 100000, new(big.Int), nil, nil, new(big.Int), data, false)}
+			context := core.NewEVMContext(msg, header, lc, nil)
+			vmenv := vm.NewEVM(context, statedb, config, vm.Config{})
+			gp := new(core.GasPool).AddGas(math.MaxUint64)
+			ret, _, _, _ = core.ApplyMessage(vmenv, msg, gp)
+			return statedb.Error()
+		})
+		if err != nil {
+			return res
+		}
+		res = append(res, ret...)
+	}
+	return res
+}
+
+// Tests that state proofs are only accepted if they prove every requested state
+// and storage entry against the state root of the request, and nothing else.
+func TestStateProofsRequestValidate(t *testing.T) {
+	var (
+		db       = ethdb.NewMemDatabase()
+		addr     = common.HexToAddress("0x1234567812345678123456781234567812345678")
+		contract = common.HexToAddress("0x8765432187654321876543218765432187654321")
+		slot     = common.HexToHash("0x01")
+	)
+	// Create two states that only differ in the storage of the contract
+	commit := func(value common.Hash) common.Hash {
+		statedb, _ := state.New(common.Hash{}, state.NewDatabase(db))
+		statedb.SetBalance(addr, big.NewInt(1))
+		statedb.SetNonce(contract, 1)
+		statedb.SetState(contract, slot, value)
+		root, err := statedb.Commit(false)
+		if err != nil {
+			t.Fatalf("failed to commit state: %v", err)
+		}
+		if err := statedb.Database().TrieDB().Commit(root, false); err != nil {
+			t.Fatalf("failed to commit trie: %v", err)
+		}
+		return root
+	}
+	root := commit(common.HexToHash("0x02"))
+	otherRoot := commit(common.HexToHash("0x03"))
+
+	entries := []light.StateEntryKey{
+		{Key: crypto.Keccak256(addr.Bytes())},
+		{AccKey: crypto.Keccak256(contract.Bytes()), Key: crypto.Keccak256(slot.Bytes())},
+	}
+	// prove generates the proofs a server holding the given state would send
+	prove := func(root common.Hash) light.NodeList {
+		nodes := light.NewNodeSet()
+		req := &StateProofsRequest{Id: &light.TrieID{Root: root}, Entries: entries}
+		statedb, _ := state.New(root, state.NewDatabase(db))
+		for _, preq := range req.proofReqs() {
+			if len(preq.AccKey) == 0 {
+				tr, _ := statedb.Database().OpenTrie(root)
+				tr.Prove(preq.Key, 0, nodes)
+				continue
+			}
+			tr := statedb.StorageTrie(contract)
+			tr.Prove(preq.Key, 0, nodes)
+		}
+		return nodes.NodeList()
+	}
+	valid := prove(root)
+
+	tests := []struct {
+		name   string
+		proofs light.NodeList
+		ok     bool
+	}{
+		{"valid", valid, true},
+		{"other_state", prove(otherRoot), false},
+		{"missing_node", valid[:len(valid)-1], false},
+		{"useless_node", append(append(light.NodeList{}, valid...), prove(otherRoot)...), false},
+	}
+	for _, test := range tests {
+		req := &StateProofsRequest{Id: &light.TrieID{Root: root}, Entries: entries}
+		err := req.Validate(ethdb.NewMemDatabase(), &Msg{MsgType: MsgProofsV2, Obj: test.proofs})
+		if test.ok && err != nil {
+			t.Errorf("%s: failed to validate proofs: %v", test.name, err)
+		}
+		if !test.ok && err == nil {
+			t.Errorf("%s: invalid proofs accepted", test.name)
+		}
+		if test.ok && req.Proof == nil {
+			t.Errorf("%s: proofs not stored in request", test.name)
+		}
+	}
+}
+
 // testOdr tests odr requests whose validation guaranteed by block headers.
 func testOdr(t *testing.T, protocol int, expFail uint64, fn odrTestFn) {
 	// Assemble the test environment
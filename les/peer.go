@@ -74,6 +74,8 @@ type peer struct {
 	responseErrors int
 
 	fcClient       *flowcontrol.ClientNode // nil if the peer is server only
+	fcClientParams *flowcontrol.ServerParams
+	fcClientWeight uint64
 	fcServer       *flowcontrol.ServerNode // nil if the peer is client only
 	fcServerParams *flowcontrol.ServerParams
 	fcCosts        requestCostTable
@@ -198,8 +200,21 @@ func (p *peer) SendBlockBodiesRLP(reqID, bv uint64, bodies []rlp.RawValue) error
 	return sendResponse(p.rw, BlockBodiesMsg, reqID, bv, bodies)
 }
 
-func (p *peer) SendEtherbaseRLP(reqID, bv uint64, etherbase common.Address) error {
-	return sendResponse(p.rw, EtherbaseMsg, reqID, bv, etherbase)
+// SendEtherbaseRLP sends the etherbase of this node to the remote peer. LES/3
+// peers also receive the minimum gateway fee this node accepts.
+func (p *peer) SendEtherbaseRLP(reqID, bv uint64, etherbase common.Address, gatewayFee *big.Int) error {
+	if p.version < lpv3 {
+		return sendResponse(p.rw, EtherbaseMsg, reqID, bv, etherbase)
+	}
+	if gatewayFee == nil {
+		gatewayFee = common.Big0
+	}
+	type resp struct {
+		ReqID, BV  uint64
+		Etherbase  common.Address
+		GatewayFee *big.Int
+	}
+	return p2p.Send(p.rw, EtherbaseMsg, resp{reqID, bv, etherbase, gatewayFee})
 }
 
 // SendCodeRLP sends a batch of arbitrary internal data, corresponding to the
@@ -239,6 +254,12 @@ func (p *peer) SendTxStatus(reqID, bv uint64, stats []txStatus) error {
 	return sendResponse(p.rw, TxStatusMsg, reqID, bv, stats)
 }
 
+// SendTxValidation sends a batch of transaction validation results, corresponding
+// to the transactions requested.
+func (p *peer) SendTxValidation(reqID, bv uint64, results []txValidation) error {
+	return sendResponse(p.rw, TxValidationMsg, reqID, bv, results)
+}
+
 // RequestHeadersByHash fetches a batch of blocks' headers corresponding to the
 // specified header query, based on the hash of an origin block.
 func (p *peer) RequestHeadersByHash(reqID, cost uint64, origin common.Hash, amount int, skip int, reverse bool) error {
@@ -279,7 +300,7 @@ func (p *peer) RequestProofs(reqID, cost uint64, reqs []ProofReq) error {
 	switch p.version {
 	case lpv1:
 		return sendRequest(p.rw, GetProofsV1Msg, reqID, cost, reqs)
-	case lpv2:
+	case lpv2, lpv3:
 		return sendRequest(p.rw, GetProofsV2Msg, reqID, cost, reqs)
 	default:
 		panic(nil)
@@ -296,7 +317,7 @@ func (p *peer) RequestHelperTrieProofs(reqID, cost uint64, data interface{}) err
 		}
 		p.Log().Debug("Fetching batch of header proofs", "count", len(reqs))
 		return sendRequest(p.rw, GetHeaderProofsMsg, reqID, cost, reqs)
-	case lpv2:
+	case lpv2, lpv3:
 		reqs, ok := data.([]HelperTrieReq)
 		if !ok {
 			return errInvalidHelpTrieReq
@@ -314,6 +335,13 @@ func (p *peer) RequestTxStatus(reqID, cost uint64, txHashes []common.Hash) error
 	return sendRequest(p.rw, GetTxStatusMsg, reqID, cost, txHashes)
 }
 
+// RequestTxValidation asks a remote node to validate a batch of transactions
+// against its transaction pool rules, without adding them to the pool.
+func (p *peer) RequestTxValidation(reqID, cost uint64, txs types.Transactions) error {
+	p.Log().Debug("Requesting transaction validation", "count", len(txs))
+	return sendRequest(p.rw, GetTxValidationMsg, reqID, cost, txs)
+}
+
 // RequestEtherbase fetches the etherbase of a remote node.
 func (p *peer) RequestEtherbase(reqID, cost uint64) error {
 	p.Log().Debug("Requesting etherbase for peer", "enode", p.id)
@@ -329,7 +357,7 @@ func (p *peer) SendTxs(reqID, cost uint64, txs types.Transactions) error {
 	switch p.version {
 	case lpv1:
 		return p2p.Send(p.rw, SendTxMsg, txs) // old message format does not include reqID
-	case lpv2:
+	case lpv2, lpv3:
 		return sendRequest(p.rw, SendTxV2Msg, reqID, cost, txs)
 	default:
 		panic(nil)
@@ -421,8 +449,9 @@ func (p *peer) Handshake(td *big.Int, head common.Hash, headNum uint64, genesis
 		send = send.add("serveChainSince", uint64(0))
 		send = send.add("serveStateSince", uint64(0))
 		send = send.add("txRelay", nil)
-		send = send.add("flowControl/BL", server.defParams.BufLimit)
-		send = send.add("flowControl/MRR", server.defParams.MinRecharge)
+		p.fcClientParams, p.fcClientWeight = server.clientParams(p.ID())
+		send = send.add("flowControl/BL", p.fcClientParams.BufLimit)
+		send = send.add("flowControl/MRR", p.fcClientParams.MinRecharge)
 		list := server.fcCostStats.getCurrentList()
 		send = send.add("flowControl/MRC", list)
 		p.fcCosts = list.decode()
@@ -476,7 +505,7 @@ func (p *peer) Handshake(td *big.Int, head common.Hash, headNum uint64, genesis
 		if recv.get("announceType", &p.announceType) != nil {
 			p.announceType = announceTypeSimple
 		}
-		p.fcClient = flowcontrol.NewClientNode(server.fcManager, server.defParams)
+		p.fcClient = flowcontrol.NewWeightedClientNode(server.fcManager, p.fcClientParams, p.fcClientWeight)
 	} else {
 		if recv.get("serveChainSince", nil) != nil {
 			return errResp(ErrUselessPeer, "peer cannot serve chain")
@@ -524,18 +553,20 @@ type peerSetNotify interface {
 // peerSet represents the collection of active peers currently participating in
 // the Light Ethereum sub-protocol.
 type peerSet struct {
-	peers      map[string]*peer
-	etherbases map[string]common.Address
-	lock       sync.RWMutex
-	notifyList []peerSetNotify
-	closed     bool
+	peers       map[string]*peer
+	etherbases  map[string]common.Address
+	gatewayFees map[string]*big.Int
+	lock        sync.RWMutex
+	notifyList  []peerSetNotify
+	closed      bool
 }
 
 // newPeerSet creates a new peer set to track the active participants.
 func newPeerSet() *peerSet {
 	return &peerSet{
-		peers:      make(map[string]*peer),
-		etherbases: make(map[string]common.Address),
+		peers:       make(map[string]*peer),
+		etherbases:  make(map[string]common.Address),
+		gatewayFees: make(map[string]*big.Int),
 	}
 }
 
@@ -579,10 +610,38 @@ func (ps *peerSet) Register(p *peer) error {
 	return nil
 }
 
-func (ps *peerSet) setEtherbase(p *peer, etherbase common.Address) {
+// setEtherbase records the etherbase of a peer and the minimum gateway fee it
+// accepts. A nil fee means the peer did not report one.
+func (ps *peerSet) setEtherbase(p *peer, etherbase common.Address, gatewayFee *big.Int) {
 	ps.lock.Lock()
 	defer ps.lock.Unlock()
 	ps.etherbases[p.id] = etherbase
+	if gatewayFee != nil {
+		ps.gatewayFees[p.id] = gatewayFee
+	} else {
+		delete(ps.gatewayFees, p.id)
+	}
+}
+
+// gatewayFee returns the highest minimum gateway fee reported by the peers with
+// the given etherbase, or nil if none of them reported one.
+func (ps *peerSet) gatewayFee(etherbase common.Address) *big.Int {
+	ps.lock.RLock()
+	defer ps.lock.RUnlock()
+
+	var fee *big.Int
+	for id, petherbase := range ps.etherbases {
+		if petherbase != etherbase {
+			continue
+		}
+		if pfee := ps.gatewayFees[id]; pfee != nil && (fee == nil || pfee.Cmp(fee) > 0) {
+			fee = pfee
+		}
+	}
+	if fee == nil {
+		return nil
+	}
+	return new(big.Int).Set(fee)
 }
 
 func (ps *peerSet) isEtherbaseSet(p *peer) bool {
@@ -634,6 +693,7 @@ func (ps *peerSet) Unregister(id string) error {
 	} else {
 		delete(ps.peers, id)
 		delete(ps.etherbases, id)
+		delete(ps.gatewayFees, id)
 		peers := make([]peerSetNotify, len(ps.notifyList))
 		copy(peers, ps.notifyList)
 		ps.lock.Unlock()
@@ -0,0 +1,192 @@
+// Copyright 2019 The Celo Authors
+// This file is part of the celo library.
+//
+// The celo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The celo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the celo library. If not, see <http://www.gnu.org/licenses/>.
+
+package les
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/les/flowcontrol"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/p2p/enode"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+var (
+	errZeroCapacity = errors.New("client capacity must be positive")
+	errZeroPriority = errors.New("client priority must be positive")
+)
+
+var priorityClientsKey = []byte("priorityClients")
+
+// priorityClient is the capacity and priority assigned to a client by the server
+// operator.
+type priorityClient struct {
+	ID       enode.ID
+	Capacity uint64 // Minimum recharge rate of the client's buffer (cost units per millisecond)
+	Priority uint64 // Weight of the client's share of the recharge when the server is busy
+}
+
+// params returns the flow control parameters of the client, with the buffer
+// limit of the default parameters scaled by the capacity of the client.
+func (c *priorityClient) params(defParams *flowcontrol.ServerParams) *flowcontrol.ServerParams {
+	return &flowcontrol.ServerParams{
+		BufLimit:    defParams.BufLimit / defParams.MinRecharge * c.Capacity,
+		MinRecharge: c.Capacity,
+	}
+}
+
+// priorityClientPool keeps the clients the server operator assigned a capacity
+// and priority to. Unlike the free clients, which are identified by a shortened
+// node ID and compete for the connection slots of the free client pool, priority
+// clients are always accepted and served according to their own flow control
+// parameters. The assignments are persisted in the database on every change.
+type priorityClientPool struct {
+	db            ethdb.Database
+	lock          sync.RWMutex
+	clients       map[enode.ID]*priorityClient
+	totalCapacity uint64            // Upper limit of the summed capacity of all priority clients
+	onChange      func(id enode.ID) // called when the assignment of a client changed
+}
+
+// newPriorityClientPool creates a priority client pool and loads the previously
+// assigned clients from the database.
+func newPriorityClientPool(db ethdb.Database, totalCapacity uint64, onChange func(id enode.ID)) *priorityClientPool {
+	pool := &priorityClientPool{
+		db:            db,
+		clients:       make(map[enode.ID]*priorityClient),
+		totalCapacity: totalCapacity,
+		onChange:      onChange,
+	}
+	pool.loadFromDb()
+	return pool
+}
+
+// get returns the assignment of the given client, if it's a priority client.
+func (pool *priorityClientPool) get(id enode.ID) (priorityClient, bool) {
+	if pool == nil {
+		return priorityClient{}, false
+	}
+	pool.lock.RLock()
+	defer pool.lock.RUnlock()
+
+	if c, ok := pool.clients[id]; ok {
+		return *c, true
+	}
+	return priorityClient{}, false
+}
+
+// list returns the assignments of all priority clients, ordered by node ID.
+func (pool *priorityClientPool) list() []priorityClient {
+	pool.lock.RLock()
+	defer pool.lock.RUnlock()
+
+	clients := make([]priorityClient, 0, len(pool.clients))
+	for _, c := range pool.clients {
+		clients = append(clients, *c)
+	}
+	sort.Slice(clients, func(i, j int) bool {
+		return clients[i].ID.String() < clients[j].ID.String()
+	})
+	return clients
+}
+
+// set assigns the given capacity and priority to a client. The capacities of all
+// priority clients together may not exceed the total capacity of the server. A
+// connected client is notified through the change callback, since its flow
+// control parameters can only be changed by a new handshake.
+func (pool *priorityClientPool) set(id enode.ID, capacity, priority uint64) error {
+	if capacity == 0 {
+		return errZeroCapacity
+	}
+	if priority == 0 {
+		return errZeroPriority
+	}
+	pool.lock.Lock()
+	var assigned uint64
+	for cid, c := range pool.clients {
+		if cid != id {
+			assigned += c.Capacity
+		}
+	}
+	if assigned > pool.totalCapacity || capacity > pool.totalCapacity-assigned {
+		pool.lock.Unlock()
+		return fmt.Errorf("client capacity %d exceeds the unassigned server capacity (total %d, assigned %d)", capacity, pool.totalCapacity, assigned)
+	}
+	pool.clients[id] = &priorityClient{ID: id, Capacity: capacity, Priority: priority}
+	pool.saveToDb()
+	pool.lock.Unlock()
+
+	log.Info("Assigned light client capacity", "id", id, "capacity", capacity, "priority", priority)
+	pool.onChange(id)
+	return nil
+}
+
+// remove turns a priority client back into a free client.
+func (pool *priorityClientPool) remove(id enode.ID) bool {
+	pool.lock.Lock()
+	_, ok := pool.clients[id]
+	if ok {
+		delete(pool.clients, id)
+		pool.saveToDb()
+	}
+	pool.lock.Unlock()
+
+	if ok {
+		log.Info("Removed light client capacity", "id", id)
+		pool.onChange(id)
+	}
+	return ok
+}
+
+// loadFromDb restores the priority clients from the database storage
+// (automatically called at initialization)
+func (pool *priorityClientPool) loadFromDb() {
+	enc, err := pool.db.Get(priorityClientsKey)
+	if err != nil {
+		return
+	}
+	var list []*priorityClient
+	if err := rlp.DecodeBytes(enc, &list); err != nil {
+		log.Error("Failed to decode priority client list", "err", err)
+		return
+	}
+	for _, c := range list {
+		log.Debug("Loaded priority client record", "id", c.ID, "capacity", c.Capacity, "priority", c.Priority)
+		pool.clients[c.ID] = c
+	}
+}
+
+// saveToDb saves the priority clients to the database storage. The pool lock
+// must be held.
+func (pool *priorityClientPool) saveToDb() {
+	list := make([]*priorityClient, 0, len(pool.clients))
+	for _, c := range pool.clients {
+		list = append(list, c)
+	}
+	enc, err := rlp.EncodeToBytes(list)
+	if err != nil {
+		log.Error("Failed to encode priority client list", "err", err)
+		return
+	}
+	if err := pool.db.Put(priorityClientsKey, enc); err != nil {
+		log.Error("Failed to store priority client list", "err", err)
+	}
+}
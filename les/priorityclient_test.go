@@ -0,0 +1,83 @@
+// Copyright 2019 The Celo Authors
+// This file is part of the celo library.
+//
+// The celo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The celo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the celo library. If not, see <http://www.gnu.org/licenses/>.
+
+package les
+
+import (
+	"math"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/les/flowcontrol"
+	"github.com/ethereum/go-ethereum/p2p/enode"
+)
+
+func TestPriorityClientPool(t *testing.T) {
+	var (
+		db       = ethdb.NewMemDatabase()
+		changed  []enode.ID
+		onChange = func(id enode.ID) { changed = append(changed, id) }
+		pool     = newPriorityClientPool(db, 1000, onChange)
+		id1      = enode.ID{1}
+		id2      = enode.ID{2}
+	)
+	if err := pool.set(id1, 0, 1); err != errZeroCapacity {
+		t.Fatalf("zero capacity error mismatch: have %v, want %v", err, errZeroCapacity)
+	}
+	if err := pool.set(id1, 100, 0); err != errZeroPriority {
+		t.Fatalf("zero priority error mismatch: have %v, want %v", err, errZeroPriority)
+	}
+	if err := pool.set(id1, 100, 3); err != nil {
+		t.Fatalf("failed to set client capacity: %v", err)
+	}
+	if err := pool.set(id2, 200, 1); err != nil {
+		t.Fatalf("failed to set client capacity: %v", err)
+	}
+	// The summed capacity is limited by the total capacity of the server, also
+	// for capacities that would overflow the flow control parameters
+	if err := pool.set(id2, 901, 1); err == nil {
+		t.Fatalf("capacity exceeding the total capacity accepted")
+	}
+	if err := pool.set(enode.ID{3}, math.MaxUint64, 1); err == nil {
+		t.Fatalf("overflowing capacity accepted")
+	}
+	if err := pool.set(id2, 900, 1); err != nil {
+		t.Fatalf("failed to change client capacity: %v", err)
+	}
+	if len(changed) != 3 || changed[0] != id1 || changed[1] != id2 || changed[2] != id2 {
+		t.Fatalf("change notifications mismatch: have %v", changed)
+	}
+	// The flow control parameters are scaled by the capacity
+	c, ok := pool.get(id1)
+	if !ok {
+		t.Fatalf("priority client missing")
+	}
+	params := c.params(&flowcontrol.ServerParams{BufLimit: 1000, MinRecharge: 10})
+	if params.MinRecharge != 100 || params.BufLimit != 10000 {
+		t.Fatalf("flow control parameters mismatch: have %+v", params)
+	}
+	// The assignments survive a restart
+	if !pool.remove(id2) {
+		t.Fatalf("failed to remove priority client")
+	}
+	pool = newPriorityClientPool(db, 1000, onChange)
+	if clients := pool.list(); len(clients) != 1 || clients[0] != (priorityClient{ID: id1, Capacity: 100, Priority: 3}) {
+		t.Fatalf("loaded clients mismatch: have %v", clients)
+	}
+	if _, ok := pool.get(id2); ok {
+		t.Fatalf("removed client loaded")
+	}
+}
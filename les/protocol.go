@@ -36,17 +36,18 @@ import (
 const (
 	lpv1 = 1
 	lpv2 = 2
+	lpv3 = 3
 )
 
 // Supported versions of the les protocol (first is primary)
 var (
-	ClientProtocolVersions    = []uint{lpv2, lpv1}
-	ServerProtocolVersions    = []uint{lpv2, lpv1}
+	ClientProtocolVersions    = []uint{lpv3, lpv2, lpv1}
+	ServerProtocolVersions    = []uint{lpv3, lpv2, lpv1}
 	AdvertiseProtocolVersions = []uint{lpv2} // clients are searching for the first advertised protocol in the list
 )
 
 // Number of implemented message corresponding to different protocol versions.
-var ProtocolLengths = map[uint]uint64{lpv1: 15, lpv2: 24}
+var ProtocolLengths = map[uint]uint64{lpv1: 15, lpv2: 24, lpv3: 26}
 
 const (
 	NetworkId          = 1
@@ -81,6 +82,9 @@ const (
 	TxStatusMsg            = 0x15
 	GetEtherbaseMsg        = 0x16
 	EtherbaseMsg           = 0x17
+	// Protocol messages belonging to LPV3
+	GetTxValidationMsg = 0x18
+	TxValidationMsg    = 0x19
 )
 
 type errCode int
@@ -226,3 +230,76 @@ type txStatus struct {
 	Lookup *rawdb.TxLookupEntry `rlp:"nil"`
 	Error  string
 }
+
+// txRejectCode is the reason given by a server for rejecting a transaction it
+// was asked to validate against the rules of its transaction pool.
+type txRejectCode uint
+
+const (
+	txAccepted txRejectCode = iota
+	txRejectedOther
+	txRejectedKnown
+	txRejectedInvalidSender
+	txRejectedNonceTooLow
+	txRejectedUnderpriced
+	txRejectedReplaceUnderpriced
+	txRejectedInsufficientFunds
+	txRejectedIntrinsicGas
+	txRejectedGasLimit
+	txRejectedNegativeValue
+	txRejectedOversizedData
+	txRejectedNonWhitelistedFeeCurrency
+	txRejectedGasPriceMinimum
+	txRejectedGatewayFee
+)
+
+// txRejectErrors maps the rejection codes to the transaction pool errors they
+// stand for. Codes without an entry are reported with the server's message.
+var txRejectErrors = map[txRejectCode]error{
+	txRejectedInvalidSender:             core.ErrInvalidSender,
+	txRejectedNonceTooLow:               core.ErrNonceTooLow,
+	txRejectedUnderpriced:               core.ErrUnderpriced,
+	txRejectedReplaceUnderpriced:        core.ErrReplaceUnderpriced,
+	txRejectedInsufficientFunds:         core.ErrInsufficientFunds,
+	txRejectedIntrinsicGas:              core.ErrIntrinsicGas,
+	txRejectedGasLimit:                  core.ErrGasLimit,
+	txRejectedNegativeValue:             core.ErrNegativeValue,
+	txRejectedOversizedData:             core.ErrOversizedData,
+	txRejectedNonWhitelistedFeeCurrency: core.ErrNonWhitelistedFeeCurrency,
+	txRejectedGasPriceMinimum:           core.ErrGasPriceDoesNotExceedMinimum,
+}
+
+// txValidation is the result of validating a transaction on behalf of a client.
+type txValidation struct {
+	Code  txRejectCode
+	Error string
+}
+
+// newTxValidation creates the validation result reported for the given error
+// of the transaction pool.
+func newTxValidation(code txRejectCode, err error) txValidation {
+	if err == nil {
+		return txValidation{Code: txAccepted}
+	}
+	if code == txRejectedOther {
+		for c, e := range txRejectErrors {
+			if e == err {
+				code = c
+				break
+			}
+		}
+	}
+	return txValidation{Code: code, Error: err.Error()}
+}
+
+// err converts the validation result back into an error, reusing the errors of
+// the transaction pool where possible.
+func (v txValidation) err() error {
+	if v.Code == txAccepted {
+		return nil
+	}
+	if err, ok := txRejectErrors[v.Code]; ok {
+		return err
+	}
+	return errors.New(v.Error)
+}
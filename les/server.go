@@ -20,6 +20,7 @@ package les
 import (
 	"crypto/ecdsa"
 	"encoding/binary"
+	"fmt"
 	"math"
 	"sync"
 
@@ -35,19 +36,22 @@ import (
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/p2p"
 	"github.com/ethereum/go-ethereum/p2p/discv5"
+	"github.com/ethereum/go-ethereum/p2p/enode"
 	"github.com/ethereum/go-ethereum/params"
 	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/rpc"
 )
 
 type LesServer struct {
 	lesCommons
 
-	fcManager   *flowcontrol.ClientManager // nil if our node is client only
-	fcCostStats *requestCostStats
-	defParams   *flowcontrol.ServerParams
-	lesTopics   []discv5.Topic
-	privateKey  *ecdsa.PrivateKey
-	quitSync    chan struct{}
+	fcManager       *flowcontrol.ClientManager // nil if our node is client only
+	fcCostStats     *requestCostStats
+	defParams       *flowcontrol.ServerParams
+	priorityClients *priorityClientPool // clients with a capacity assigned by the operator
+	lesTopics       []discv5.Topic
+	privateKey      *ecdsa.PrivateKey
+	quitSync        chan struct{}
 }
 
 func NewLesServer(eth *eth.Ethereum, config *eth.Config) (*LesServer, error) {
@@ -110,6 +114,14 @@ func NewLesServer(eth *eth.Ethereum, config *eth.Config) (*LesServer, error) {
 	}
 	srv.fcManager = flowcontrol.NewClientManager(uint64(config.LightServ), 10, 1000000000)
 	srv.fcCostStats = newCostStats(eth.ChainDb())
+
+	// The capacity assigned to priority clients is limited to the recharge of all
+	// client slots at the default rate. Disconnect priority clients whose
+	// assignment changed, they are served with the new flow control parameters
+	// once they reconnect
+	srv.priorityClients = newPriorityClientPool(eth.ChainDb(), srv.defParams.MinRecharge*uint64(config.LightPeers), func(id enode.ID) {
+		pm.removePeer(fmt.Sprintf("%x", id[:8]))
+	})
 	return srv, nil
 }
 
@@ -117,6 +129,27 @@ func (s *LesServer) Protocols() []p2p.Protocol {
 	return s.makeProtocols(ServerProtocolVersions)
 }
 
+// APIs returns the RPC APIs of the light server.
+func (s *LesServer) APIs() []rpc.API {
+	return []rpc.API{
+		{
+			Namespace: "les",
+			Version:   "1.0",
+			Service:   NewPrivateLightServerAPI(s),
+			Public:    false,
+		},
+	}
+}
+
+// clientParams returns the flow control parameters and the recharge weight of
+// the client with the given node ID.
+func (s *LesServer) clientParams(id enode.ID) (*flowcontrol.ServerParams, uint64) {
+	if c, ok := s.priorityClients.get(id); ok {
+		return c.params(s.defParams), c.Priority
+	}
+	return s.defParams, 1
+}
+
 // Start starts the LES server
 func (s *LesServer) Start(srvr *p2p.Server) {
 	s.protocolManager.Start(s.config.LightPeers)
@@ -17,6 +17,8 @@
 package les
 
 import (
+	"context"
+	"math/big"
 	"sync"
 
 	"github.com/ethereum/go-ethereum/common"
@@ -36,15 +38,19 @@ type LesTxRelay struct {
 	peerList  []*peer
 	lock      sync.RWMutex
 
-	reqDist *requestDistributor
+	reqDist   *requestDistributor
+	retriever *retrieveManager
+	stop      chan struct{}
 }
 
-func NewLesTxRelay(ps *peerSet, reqDist *requestDistributor) *LesTxRelay {
+func NewLesTxRelay(ps *peerSet, retriever *retrieveManager, stop chan struct{}) *LesTxRelay {
 	r := &LesTxRelay{
 		txSent:    make(map[common.Hash]*ltrInfo),
 		txPending: make(map[common.Hash]struct{}),
 		ps:        ps,
-		reqDist:   reqDist,
+		reqDist:   retriever.dist,
+		retriever: retriever,
+		stop:      stop,
 	}
 	ps.notify(r)
 	return r
@@ -69,6 +75,10 @@ func (self *LesTxRelay) HasPeerWithEtherbase(etherbase common.Address) error {
 	return err
 }
 
+func (self *LesTxRelay) GatewayFee(etherbase common.Address) *big.Int {
+	return self.ps.gatewayFee(etherbase)
+}
+
 // send sends a list of transactions to at most a given number of peers at
 // once, never resending any particular transaction to the same peer twice
 func (self *LesTxRelay) send(txs types.Transactions) {
@@ -127,6 +137,88 @@ func (self *LesTxRelay) Send(txs types.Transactions) {
 	self.send(txs)
 }
 
+// ValidateTxs asks the peers the transactions would be relayed to whether they
+// pass the rules of their transaction pools, which include the fee currency checks
+// that can't be done locally. The transactions are batched into one request per
+// peer and MaxTxSend transactions. Peers that don't speak LES/3 are not asked.
+func (self *LesTxRelay) ValidateTxs(ctx context.Context, txs types.Transactions) []error {
+	errs := make([]error, len(txs))
+
+	// Group the transactions by the peer they would be relayed to
+	batches := make(map[*peer][]int)
+	for i, tx := range txs {
+		etherbase := common.Address{}
+		if tx.GatewayFeeRecipient() != nil {
+			etherbase = *tx.GatewayFeeRecipient()
+		}
+		p, err := self.ps.getPeerWithEtherbase(etherbase)
+		if err != nil {
+			errs[i] = err
+			continue
+		}
+		if p.version >= lpv3 {
+			batches[p] = append(batches[p], i)
+		}
+	}
+	for p, indices := range batches {
+		for len(indices) > 0 {
+			count := len(indices)
+			if count > MaxTxSend {
+				count = MaxTxSend
+			}
+			batch := make(types.Transactions, count)
+			for j, i := range indices[:count] {
+				batch[j] = txs[i]
+			}
+			results, err := self.requestTxValidation(ctx, p, batch)
+			for j, i := range indices[:count] {
+				if err != nil {
+					errs[i] = err
+				} else {
+					errs[i] = results[j].err()
+				}
+			}
+			indices = indices[count:]
+		}
+	}
+	return errs
+}
+
+// requestTxValidation retrieves the validation results of a batch of transactions
+// from the given peer.
+func (self *LesTxRelay) requestTxValidation(ctx context.Context, p *peer, txs types.Transactions) ([]txValidation, error) {
+	var results []txValidation
+	reqID := genReqID()
+	rq := &distReq{
+		getCost: func(dp distPeer) uint64 {
+			return dp.(*peer).GetRequestCost(GetTxValidationMsg, len(txs))
+		},
+		canSend: func(dp distPeer) bool {
+			return dp.(*peer) == p
+		},
+		request: func(dp distPeer) func() {
+			peer := dp.(*peer)
+			cost := peer.GetRequestCost(GetTxValidationMsg, len(txs))
+			peer.fcServer.QueueRequest(reqID, cost)
+			return func() { peer.RequestTxValidation(reqID, cost, txs) }
+		},
+	}
+	validate := func(dp distPeer, msg *Msg) error {
+		if msg.MsgType != MsgTxValidation {
+			return errInvalidMessageType
+		}
+		results = msg.Obj.([]txValidation)
+		if len(results) != len(txs) {
+			return errInvalidEntryCount
+		}
+		return nil
+	}
+	if err := self.retriever.retrieve(ctx, reqID, rq, validate, self.stop); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
 func (self *LesTxRelay) NewHead(head common.Hash, mined []common.Hash, rollback []common.Hash) {
 	self.lock.Lock()
 	defer self.lock.Unlock()
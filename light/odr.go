@@ -95,6 +95,26 @@ func (req *TrieRequest) StoreResult(db ethdb.Database) {
 	req.Proof.Store(db)
 }
 
+// StateEntryKey identifies an entry of the state trie, or of the storage trie of
+// the account with the given AccKey. Both keys are hashed.
+type StateEntryKey struct {
+	AccKey, Key []byte
+}
+
+// StateProofsRequest is the ODR request type for retrieving a batch of state and
+// storage trie entries of a block in a single round trip
+type StateProofsRequest struct {
+	OdrRequest
+	Id      *TrieID // references the state trie of the block
+	Entries []StateEntryKey
+	Proof   *NodeSet
+}
+
+// StoreResult stores the retrieved data in local database
+func (req *StateProofsRequest) StoreResult(db ethdb.Database) {
+	req.Proof.Store(db)
+}
+
 // CodeRequest is the ODR request type for retrieving contract code
 type CodeRequest struct {
 	OdrRequest
@@ -86,6 +86,23 @@ func (odr *testOdr) Retrieve(ctx context.Context, req OdrRequest) error {
 		nodes := NewNodeSet()
 		t.Prove(req.Key, 0, nodes)
 		req.Proof = nodes
+	case *StateProofsRequest:
+		nodes := NewNodeSet()
+		st, _ := trie.New(req.Id.Root, trie.NewDatabase(odr.sdb))
+		for _, entry := range req.Entries {
+			if len(entry.AccKey) == 0 {
+				st.Prove(entry.Key, 0, nodes)
+				continue
+			}
+			// Storage entries are proven along with the account holding them
+			st.Prove(entry.AccKey, 0, nodes)
+			var account state.Account
+			if blob, _ := st.TryGet(entry.AccKey); blob != nil && rlp.DecodeBytes(blob, &account) == nil {
+				t, _ := trie.New(account.Root, trie.NewDatabase(odr.sdb))
+				t.Prove(entry.Key, 0, nodes)
+			}
+		}
+		req.Proof = nodes
 	case *CodeRequest:
 		req.Data, _ = odr.sdb.Get(req.Hash[:])
 	}
@@ -207,6 +224,38 @@ func odrContractCall(ctx context.Context, db ethdb.Database, bc *core.BlockChain
 	return res, nil
 }
 
+func TestOdrContractCallWithStateLes1(t *testing.T) { testChainOdr(t, 1, odrContractCallWithState) }
+
+func odrContractCallWithState(ctx context.Context, db ethdb.Database, bc *core.BlockChain, lc *LightChain, bhash common.Hash) ([]byte, error) {
+	if bc != nil {
+		return odrContractCall(ctx, db, bc, lc, bhash)
+	}
+	data := common.Hex2Bytes("60CD26850000000000000000000000000000000000000000000000000000000000000000")
+	header := lc.GetHeaderByHash(bhash)
+
+	var res []byte
+	for i := 0; i < 3; i++ {
+		data[35] = byte(i)
+
+		var ret []byte
+		err := RunWithState(ctx, header, lc.Odr(), func(st *state.StateDB) error {
+			// Perform read-only call.
+			st.SetBalance(testBankAddress, math.MaxBig256)
+			msg := callmsg{types.NewMessage(testBankAddress, &testContractAddr, 0, new(big.Int), 1000000, new(big.Int), nil, nil, new(big.Int), data, false)}
+			context := core.NewEVMContext(msg, header, lc, nil)
+			vmenv := vm.NewEVM(context, st, params.TestChainConfig, vm.Config{})
+			gp := new(core.GasPool).AddGas(math.MaxUint64)
+			ret, _, _, _ = core.ApplyMessage(vmenv, msg, gp)
+			return st.Error()
+		})
+		res = append(res, ret...)
+		if err != nil {
+			return res, err
+		}
+	}
+	return res, nil
+}
+
 func testChainGen(i int, block *core.BlockGen) {
 	signer := types.HomesteadSigner{}
 	switch i {
@@ -35,13 +35,74 @@ func NewState(ctx context.Context, head *types.Header, odr OdrBackend) *state.St
 }
 
 func NewStateDatabase(ctx context.Context, head *types.Header, odr OdrBackend) state.Database {
-	return &odrDatabase{ctx, StateTrieID(head), odr}
+	return &odrDatabase{ctx: ctx, id: StateTrieID(head), backend: odr}
+}
+
+const (
+	// maxStateRounds is the number of times a function is rerun by RunWithState
+	// before the remaining state is retrieved entry by entry.
+	maxStateRounds = 16
+
+	// maxStateProofEntries is the number of state and storage trie entries that
+	// are retrieved by a single StateProofsRequest.
+	maxStateProofEntries = 32
+)
+
+// RunWithState runs fn against the state of the given header, such as a contract
+// call reading a few values from the storage of the core contracts. Instead of
+// retrieving every missing trie node on its own, the state and storage entries
+// fn reads are collected and retrieved in batches, after which fn is rerun on
+// the extended local state until all of its reads could be served.
+func RunWithState(ctx context.Context, head *types.Header, odr OdrBackend, fn func(statedb *state.StateDB) error) error {
+	id := StateTrieID(head)
+	for i := 0; i < maxStateRounds; i++ {
+		recorder := &stateRecorder{seen: make(map[string]struct{})}
+		statedb, err := state.New(head.Root, &odrDatabase{ctx: ctx, id: id, backend: odr, recorder: recorder})
+		if err != nil {
+			return err
+		}
+		err = fn(statedb)
+		if len(recorder.missing) == 0 {
+			return err
+		}
+		// Some entries were unavailable, the result can't be trusted. Retrieve
+		// them and try again.
+		for start := 0; start < len(recorder.missing); start += maxStateProofEntries {
+			end := start + maxStateProofEntries
+			if end > len(recorder.missing) {
+				end = len(recorder.missing)
+			}
+			req := &StateProofsRequest{Id: id, Entries: recorder.missing[start:end]}
+			if err := odr.Retrieve(ctx, req); err != nil {
+				return err
+			}
+		}
+	}
+	// The state read by fn keeps changing, fall back to retrieving on demand
+	return fn(NewState(ctx, head, odr))
+}
+
+// stateRecorder collects the state and storage trie entries that could not be
+// resolved from the local database.
+type stateRecorder struct {
+	missing []StateEntryKey
+	seen    map[string]struct{}
+}
+
+func (r *stateRecorder) add(accKey, key []byte) {
+	id := string(accKey) + string(key)
+	if _, ok := r.seen[id]; ok {
+		return
+	}
+	r.seen[id] = struct{}{}
+	r.missing = append(r.missing, StateEntryKey{AccKey: common.CopyBytes(accKey), Key: common.CopyBytes(key)})
 }
 
 type odrDatabase struct {
-	ctx     context.Context
-	id      *TrieID
-	backend OdrBackend
+	ctx      context.Context
+	id       *TrieID
+	backend  OdrBackend
+	recorder *stateRecorder // if set, missing trie entries are recorded instead of retrieved
 }
 
 func (db *odrDatabase) OpenTrie(root common.Hash) (state.Trie, error) {
@@ -159,6 +220,11 @@ func (t *odrTrie) do(key []byte, fn func() error) error {
 		if _, ok := err.(*trie.MissingNodeError); !ok {
 			return err
 		}
+		if t.db.recorder != nil {
+			// Treat the entry as empty, it is retrieved with the rest of the batch
+			t.db.recorder.add(t.id.AccKey, key)
+			return nil
+		}
 		r := &TrieRequest{Id: t.id, Key: key}
 		if err := t.db.backend.Retrieve(t.db.ctx, r); err != nil {
 			return err
@@ -20,6 +20,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"math/big"
 	"sync"
 	"time"
 
@@ -28,7 +29,6 @@ import (
 	"github.com/ethereum/go-ethereum/core/rawdb"
 	"github.com/ethereum/go-ethereum/core/state"
 	"github.com/ethereum/go-ethereum/core/types"
-	"github.com/ethereum/go-ethereum/eth"
 	"github.com/ethereum/go-ethereum/ethdb"
 	"github.com/ethereum/go-ethereum/event"
 	"github.com/ethereum/go-ethereum/log"
@@ -88,6 +88,17 @@ type TxRelayBackend interface {
 	NewHead(head common.Hash, mined []common.Hash, rollback []common.Hash)
 	Discard(hashes []common.Hash)
 	HasPeerWithEtherbase(etherbase common.Address) error
+	// GatewayFee returns the minimum gateway fee the peers with the given
+	// etherbase accept, or nil if they did not report one.
+	GatewayFee(etherbase common.Address) *big.Int
+}
+
+// TxValidationBackend is implemented by relay backends that can have remote
+// nodes check transactions against the rules of their transaction pools, such
+// as the fee currency whitelist, balances and the gas price minimum, before the
+// transactions are relayed.
+type TxValidationBackend interface {
+	ValidateTxs(ctx context.Context, txs types.Transactions) []error
 }
 
 // NewTxPool creates a new light transaction pool
@@ -377,7 +388,7 @@ func (pool *TxPool) validateTx(ctx context.Context, tx *types.Transaction) error
 	}
 
 	// Transactor should have enough funds to cover the costs
-	err = core.ValidateTransactorBalanceCoversTx(tx, from, currentState)
+	err = core.ValidateTransactorBalanceCoversTx(tx, from, header, currentState)
 	if err != nil {
 		return err
 	}
@@ -401,15 +412,26 @@ func (pool *TxPool) validateTx(ctx context.Context, tx *types.Transaction) error
 		return err
 	}
 
-	// TODO(nategraf): Support fetching the gateway fee from our peers.
-	// For now we assume our peer is using the default.
-	if tx.GatewayFeeRecipient() != nil && tx.GatewayFee().Cmp(eth.DefaultConfig.GatewayFee) < 0 {
-		return errGatewayFeeTooLow
+	// Peers that did not report their minimum gateway fee check it themselves.
+	if tx.GatewayFeeRecipient() != nil {
+		if minimum := pool.relay.GatewayFee(*tx.GatewayFeeRecipient()); minimum != nil && tx.GatewayFee().Cmp(minimum) < 0 {
+			return errGatewayFeeTooLow
+		}
 	}
 
 	return currentState.Error()
 }
 
+// validateRemote has the serving nodes check the rules that can't be verified
+// locally. It waits for the network, so it must be called without holding the
+// pool lock.
+func (pool *TxPool) validateRemote(ctx context.Context, txs types.Transactions) []error {
+	if validator, ok := pool.relay.(TxValidationBackend); ok {
+		return validator.ValidateTxs(ctx, txs)
+	}
+	return make([]error, len(txs))
+}
+
 // add validates a new transaction and sets its state pending if processable.
 // It also updates the locally stored nonce if necessary.
 func (self *TxPool) add(ctx context.Context, tx *types.Transaction) error {
@@ -447,6 +469,10 @@ func (self *TxPool) add(ctx context.Context, tx *types.Transaction) error {
 // Add adds a transaction to the pool if valid and passes it to the tx relay
 // backend
 func (self *TxPool) Add(ctx context.Context, tx *types.Transaction) error {
+	if err := self.validateRemote(ctx, types.Transactions{tx})[0]; err != nil {
+		return err
+	}
+
 	self.mu.Lock()
 	defer self.mu.Unlock()
 
@@ -468,11 +494,16 @@ func (self *TxPool) Add(ctx context.Context, tx *types.Transaction) error {
 // AddTransactions adds all valid transactions to the pool and passes them to
 // the tx relay backend
 func (self *TxPool) AddBatch(ctx context.Context, txs []*types.Transaction) {
+	errs := self.validateRemote(ctx, txs)
+
 	self.mu.Lock()
 	defer self.mu.Unlock()
 	var sendTx types.Transactions
 
-	for _, tx := range txs {
+	for i, tx := range txs {
+		if errs[i] != nil {
+			continue
+		}
 		if err := self.add(ctx, tx); err == nil {
 			sendTx = append(sendTx, tx)
 		}
@@ -55,6 +55,10 @@ func (self *testTxRelay) HasPeerWithEtherbase(common.Address) error {
 	return nil
 }
 
+func (self *testTxRelay) GatewayFee(common.Address) *big.Int {
+	return nil
+}
+
 const poolTestTxs = 1000
 const poolTestBlocks = 100
 
@@ -145,3 +149,89 @@ func TestTxPool(t *testing.T) {
 		}
 	}
 }
+
+// validatingTxRelay is a testTxRelay that also validates transactions remotely,
+// rejecting the ones in reject.
+type validatingTxRelay struct {
+	testTxRelay
+	pool    *TxPool
+	reject  map[common.Hash]bool
+	batches chan int
+	locked  chan bool
+}
+
+func (self *validatingTxRelay) ValidateTxs(ctx context.Context, txs types.Transactions) []error {
+	self.batches <- len(txs)
+
+	// The pool lock must not be held while waiting for the network
+	done := make(chan struct{})
+	go func() {
+		self.pool.Stats()
+		close(done)
+	}()
+	select {
+	case <-done:
+		self.locked <- false
+	case <-time.After(time.Second):
+		self.locked <- true
+	}
+
+	errs := make([]error, len(txs))
+	for i, tx := range txs {
+		if self.reject[tx.Hash()] {
+			errs[i] = core.ErrUnderpriced
+		}
+	}
+	return errs
+}
+
+func TestTxPoolRemoteValidation(t *testing.T) {
+	var (
+		sdb   = ethdb.NewMemDatabase()
+		ldb   = ethdb.NewMemDatabase()
+		gspec = core.Genesis{Alloc: core.GenesisAlloc{testBankAddress: {Balance: testBankFunds}}}
+	)
+	gspec.MustCommit(sdb)
+	gspec.MustCommit(ldb)
+
+	odr := &testOdr{sdb: sdb, ldb: ldb, indexerConfig: TestClientIndexerConfig}
+	relay := &validatingTxRelay{
+		testTxRelay: testTxRelay{send: make(chan int, 1)},
+		reject:      make(map[common.Hash]bool),
+		batches:     make(chan int, 1),
+		locked:      make(chan bool, 1),
+	}
+	lightchain, _ := NewLightChain(odr, params.TestChainConfig, ethash.NewFullFaker())
+	pool := NewTxPool(params.TestChainConfig, lightchain, relay)
+	defer pool.Stop()
+	relay.pool = pool
+
+	txs := make(types.Transactions, 3)
+	for i := range txs {
+		txs[i], _ = types.SignTx(types.NewTransaction(uint64(i), acc1Addr, big.NewInt(10000), params.TxGas, nil, nil, nil, nil, nil), types.HomesteadSigner{}, testBankKey)
+	}
+	relay.reject[txs[1].Hash()] = true
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// A batch is validated in a single request, and only the accepted transactions are relayed
+	pool.AddBatch(ctx, txs)
+	if batch := <-relay.batches; batch != len(txs) {
+		t.Errorf("validation batch size mismatch: have %d, want %d", batch, len(txs))
+	}
+	if <-relay.locked {
+		t.Errorf("transactions validated with the pool lock held")
+	}
+	if sent := <-relay.send; sent != 2 {
+		t.Errorf("relayed transaction count mismatch: have %d, want %d", sent, 2)
+	}
+	// A single rejected transaction reports the remote error
+	if err := pool.Add(ctx, txs[1]); err != core.ErrUnderpriced {
+		t.Errorf("error mismatch: have %v, want %v", err, core.ErrUnderpriced)
+	}
+	<-relay.batches
+	if <-relay.locked {
+		t.Errorf("transaction validated with the pool lock held")
+	}
+}
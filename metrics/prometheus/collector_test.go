@@ -0,0 +1,176 @@
+// Copyright 2019 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package prometheus
+
+import (
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/metrics"
+)
+
+func TestMain(m *testing.M) {
+	metrics.Enabled = true
+	os.Exit(m.Run())
+}
+
+func TestCollector(t *testing.T) {
+	c := newCollector()
+
+	counter := metrics.NewCounter()
+	counter.Inc(12345)
+	c.addCounter("test/counter", counter)
+
+	gauge := metrics.NewGauge()
+	gauge.Update(23456)
+	c.addGauge("test/gauge", gauge)
+
+	gaugeFloat64 := metrics.NewGaugeFloat64()
+	gaugeFloat64.Update(34567.89)
+	c.addGaugeFloat64("test/gauge_float64", gaugeFloat64)
+
+	histogram := metrics.NewHistogram(&metrics.NilSample{})
+	c.addHistogram("test/histogram", histogram)
+
+	meter := metrics.NewMeter()
+	defer meter.Stop()
+	meter.Mark(9999999)
+	c.addMeter("test/meter", meter)
+
+	timer := metrics.NewTimer()
+	defer timer.Stop()
+	timer.Update(20 * time.Millisecond)
+	timer.Update(21 * time.Millisecond)
+	timer.Update(22 * time.Millisecond)
+	timer.Update(120 * time.Millisecond)
+	timer.Update(23 * time.Millisecond)
+	timer.Update(24 * time.Millisecond)
+	c.addTimer("test/timer", timer)
+
+	resettingTimer := metrics.NewResettingTimer()
+	resettingTimer.Update(10 * time.Millisecond)
+	resettingTimer.Update(11 * time.Millisecond)
+	resettingTimer.Update(12 * time.Millisecond)
+	resettingTimer.Update(120 * time.Millisecond)
+	resettingTimer.Update(13 * time.Millisecond)
+	resettingTimer.Update(14 * time.Millisecond)
+	c.addResettingTimer("test/resetting_timer", resettingTimer.Snapshot())
+
+	emptyResettingTimer := metrics.NewResettingTimer().Snapshot()
+	c.addResettingTimer("test/empty_resetting_timer", emptyResettingTimer)
+
+	const expectedOutput = `# TYPE test_counter counter
+test_counter 12345
+
+# TYPE test_gauge gauge
+test_gauge 23456
+
+# TYPE test_gauge_float64 gauge
+test_gauge_float64 34567.89
+
+# TYPE test_histogram_count counter
+test_histogram_count 0
+
+# TYPE test_histogram summary
+test_histogram {quantile="0.5"} 0
+test_histogram {quantile="0.75"} 0
+test_histogram {quantile="0.95"} 0
+test_histogram {quantile="0.99"} 0
+test_histogram {quantile="0.999"} 0
+test_histogram {quantile="0.9999"} 0
+
+# TYPE test_meter counter
+test_meter 9999999
+
+`
+	exp := c.buff.String()
+	if !strings.HasPrefix(exp, expectedOutput) {
+		t.Fatalf("unexpected collector output:\n%s\nexpected prefix:\n%s", exp, expectedOutput)
+	}
+	for _, want := range []string{
+		"# TYPE test_meter_rate1 gauge\n",
+		"# TYPE test_timer_count counter\ntest_timer_count 6\n",
+		"# TYPE test_timer summary\n",
+		"test_timer {quantile=\"0.5\"} 2.25e+07\n",
+		"test_timer {quantile=\"0.99\"} 1.2e+08\n",
+		"# TYPE test_resetting_timer_count counter\ntest_resetting_timer_count 6\n",
+		"test_resetting_timer {quantile=\"0.50\"} 12000000\n",
+		"test_resetting_timer {quantile=\"0.99\"} 120000000\n",
+	} {
+		if !strings.Contains(exp, want) {
+			t.Errorf("collector output missing %q:\n%s", want, exp)
+		}
+	}
+	if strings.Contains(exp, "test_empty_resetting_timer") {
+		t.Errorf("empty resetting timer should not be reported")
+	}
+}
+
+func TestMutateKey(t *testing.T) {
+	tests := []struct {
+		key  string
+		want string
+	}{
+		{"chain/inserts", "chain_inserts"},
+		{"consensus/istanbul/core/round", "consensus_istanbul_core_round"},
+		{"p2p/InboundTraffic", "p2p_InboundTraffic"},
+		{"eth/db/chaindata/compact.time", "eth_db_chaindata_compact_time"},
+		{"les/server/req-avg", "les_server_req_avg"},
+		{"rpc:calls", "rpc:calls"},
+		{"1st/metric", "_1st_metric"},
+	}
+	for _, tt := range tests {
+		if have := mutateKey(tt.key); have != tt.want {
+			t.Errorf("mutateKey(%q) mismatch: have %q, want %q", tt.key, have, tt.want)
+		}
+	}
+}
+
+func TestHandler(t *testing.T) {
+	r := metrics.NewRegistry()
+	metrics.NewRegisteredCounter("b/counter", r).Inc(2)
+	metrics.NewRegisteredGauge("a/gauge", r).Update(1)
+
+	rec := httptest.NewRecorder()
+	Handler(r).ServeHTTP(rec, httptest.NewRequest("GET", "/debug/metrics/prometheus", nil))
+
+	want := "# TYPE a_gauge gauge\na_gauge 1\n\n# TYPE b_counter counter\nb_counter 2\n\n"
+	if have := rec.Body.String(); have != want {
+		t.Errorf("handler output mismatch:\nhave %q\nwant %q", have, want)
+	}
+}
+
+func TestHandlerCollidingNames(t *testing.T) {
+	r := metrics.NewRegistry()
+	metrics.NewRegisteredGauge("a/b", r).Update(1)
+	metrics.NewRegisteredGauge("a.b", r).Update(2)
+	metrics.NewRegisteredGauge("a_b_2", r).Update(3)
+
+	rec := httptest.NewRecorder()
+	Handler(r).ServeHTTP(rec, httptest.NewRequest("GET", "/debug/metrics/prometheus", nil))
+
+	// Names are sorted, so "a.b" comes first and keeps the plain key
+	want := "# TYPE a_b gauge\na_b 2\n\n" +
+		"# TYPE a_b_2 gauge\na_b_2 1\n\n" +
+		"# TYPE a_b_2_2 gauge\na_b_2_2 3\n\n"
+	if have := rec.Body.String(); have != want {
+		t.Errorf("handler output mismatch:\nhave %q\nwant %q", have, want)
+	}
+}
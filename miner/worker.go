@@ -320,7 +320,7 @@ func (w *worker) close() {
 }
 
 func (w *worker) txCmp(tx1 *types.Transaction, tx2 *types.Transaction) int {
-	return currency.Cmp(tx1.GasPrice(), tx1.FeeCurrency(), tx2.GasPrice(), tx2.FeeCurrency())
+	return currency.Cmp(tx1.GasPrice(), tx1.FeeCurrency(), tx2.GasPrice(), tx2.FeeCurrency(), w.current.header, w.current.state)
 }
 
 // newWorkLoop is a standalone goroutine to submit new mining work upon received events.
@@ -979,7 +979,7 @@ func (w *worker) commitNewWork(interrupt *int32, noempty bool, timestamp int64)
 	w.updateSnapshot()
 
 	// Play our part in generating the random beacon.
-	if w.isRunning() && random.IsRunning() {
+	if w.isRunning() && random.IsRunning(w.current.header, w.current.state) {
 		if randomSeed == nil {
 			account := accounts.Account{Address: w.coinbase}
 			wallet, err := w.eth.AccountManager().Find(account)
@@ -98,6 +98,14 @@ const (
 	ProofOfPossessionGas uint64 = 50000 // Cost of verifying a BLS proof of possession.
 	GetValidatorGas      uint64 = 5000  // Cost of reading a validator's address.
 	GetEpochSizeGas      uint64 = 1000  // Cost of querying the number of blocks in an epoch.
+	GetExchangeRateGas   uint64 = 5000  // Cost of reading a fee currency exchange rate, whose medianRate call is bounded by MaxGasForMedianRate.
+
+	// The BLS costs are priced at the rate of ecrecover. BenchmarkPrecompiledBlsGas in core/vm times
+	// each term against EcrecoverGas and reports it in gas; update these from its output. Only messages
+	// hashed with the composite hasher are charged per word, as the direct hasher is a single Blake2 pass.
+	BlsVerifySignatureGas    uint64 = 255000 // Base cost of verifying a BLS signature, a two pair pairing check.
+	BlsAggregatePublicKeyGas uint64 = 13500  // Cost of deserializing, subgroup checking and aggregating each public key.
+	BlsVerifyPerWordGas      uint64 = 3000   // Cost of hashing each 32-byte word of the message to a curve point with the composite hasher.
 )
 
 var (
@@ -162,6 +170,7 @@ const (
 	MaxGasForGetEligibleValidatorGroupsVoteTotals  uint64 = 1 * 1000000
 	MaxGasForGetGasPriceMinimum                    uint64 = 2000000
 	MaxGasForGetGroupEpochRewards                  uint64 = 50 * 1000
+	MaxGasForGetGroupsVotedForByAccount            uint64 = 1 * 100000
 	MaxGasForGetMembershipInLastEpoch              uint64 = 1 * 1000000
 	MaxGasForGetOrComputeTobinTax                  uint64 = 1000000
 	MaxGasForGetRegisteredValidators               uint64 = 1000000
@@ -171,6 +180,7 @@ const (
 	MaxGasForMedianRate                            uint64 = 20000
 	MaxGasForReadBlockchainParameter               uint64 = 20000
 	MaxGasForRevealAndCommit                       uint64 = 2000000
+	MaxGasForRevokeActive                          uint64 = 1 * 1000000
 	MaxGasForUpdateGasPriceMinimum                 uint64 = 2000000
 	MaxGasForUpdateTargetVotingYield               uint64 = 2000000
 	MaxGasForUpdateValidatorScore                  uint64 = 1 * 1000000